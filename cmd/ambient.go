@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// AmbientSource is a looping sound placed in the world that gets louder
+// as the player approaches it.
+type AmbientSource struct {
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	// MP3 file looped while in range
+	Sound string `json:"sound"`
+	// distance in world pixels at which the sound fades out completely
+	Radius float64 `json:"radius"`
+	// loudness right at the source, relative to audio.volume
+	Volume float64 `json:"volume"`
+}
+
+// ambient is a playing ambient source.
+type ambient struct {
+	src    AmbientSource
+	player *audio.Player
+	pan    *panStream
+}
+
+// panStream scales the left and right channels of 16-bit little-endian
+// stereo PCM. The gains are set on the game goroutine and read on the
+// audio one, so they are stored atomically as float bits.
+type panStream struct {
+	src         io.ReadSeeker
+	left, right atomic.Uint64
+}
+
+func newPanStream(src io.ReadSeeker) *panStream {
+	s := &panStream{src: src}
+	s.setPan(0)
+	return s
+}
+
+// setPan places the sound from -1 (left) to 1 (right) with equal-power
+// gains, so it doesn't get quieter in the middle.
+func (s *panStream) setPan(pan float64) {
+	a := (max(min(pan, 1), -1) + 1) * math.Pi / 4
+	s.left.Store(math.Float64bits(math.Cos(a) * math.Sqrt2))
+	s.right.Store(math.Float64bits(math.Sin(a) * math.Sqrt2))
+}
+
+func (s *panStream) Read(p []byte) (int, error) {
+	// whole stereo frames only, so samples stay aligned to channels
+	n, err := io.ReadFull(s.src, p[:len(p)/4*4])
+	l := math.Float64frombits(s.left.Load())
+	r := math.Float64frombits(s.right.Load())
+	for i := 0; i+4 <= n; i += 4 {
+		putSample(p[i:], l)
+		putSample(p[i+2:], r)
+	}
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (s *panStream) Seek(offset int64, whence int) (int64, error) {
+	return s.src.Seek(offset, whence)
+}
+
+// putSample scales the int16 sample at b by gain, clipping it.
+func putSample(b []byte, gain float64) {
+	v := float64(int16(binary.LittleEndian.Uint16(b))) * gain
+	v = max(min(v, math.MaxInt16), math.MinInt16)
+	binary.LittleEndian.PutUint16(b, uint16(int16(v)))
+}
+
+// newAmbient starts an ambient source looping silently; updateAmbient
+// sets its volume and pan.
+func (g *Game) newAmbient(src AmbientSource) (*ambient, error) {
+	data, err := os.ReadFile(src.Sound)
+	if err != nil {
+		return nil, err
+	}
+	rate := g.audioContext.SampleRate()
+	stream, err := decodeMusic(bytes.NewReader(data), rate)
+	if err != nil {
+		return nil, err
+	}
+	if stream.SampleRate() != rate {
+		return nil, fmt.Errorf("sample rate %d Hz does not match %d Hz", stream.SampleRate(), rate)
+	}
+	pan := newPanStream(audio.NewInfiniteLoop(stream, stream.Length()))
+	player, err := g.audioContext.NewPlayer(pan)
+	if err != nil {
+		return nil, err
+	}
+	player.SetVolume(0)
+	player.Play()
+	return &ambient{src: src, player: player, pan: pan}, nil
+}
+
+// loadAmbient starts every configured ambient source.
+func (g *Game) loadAmbient() {
+	if g.audioContext == nil {
+		return
+	}
+	for _, src := range g.cfg.Ambient.Sources {
+		a, err := g.newAmbient(src)
+		if err != nil {
+			slog.Warn("failed to load ambient sound", "name", src.Name, "path", src.Sound, "err", err)
+			continue
+		}
+		g.ambient = append(g.ambient, a)
+	}
+}
+
+// ambientMix is the volume and pan of a source at distance (dx, dy) from
+// the listener: the volume falls off linearly to 0 at radius, and the pan
+// follows the horizontal offset, reaching maxPan at radius.
+func ambientMix(dx, dy, radius, maxPan float64) (volume, pan float64) {
+	if radius <= 0 {
+		return 0, 0
+	}
+	volume = max(1-math.Hypot(dx, dy)/radius, 0)
+	pan = max(min(dx/radius, maxPan), -maxPan)
+	return volume, pan
+}
+
+// updateAmbient mixes the ambient sources for where the player is, or
+// the camera center in free-cam.
+func (g *Game) updateAmbient() {
+	if len(g.ambient) == 0 {
+		return
+	}
+	lx, ly := g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2
+	if g.freeCam {
+		vw, vh := g.viewSize()
+		lx, ly = g.vx+vw/2, g.vy+vh/2
+	}
+	maxPan := 0.0
+	if g.cfg.Ambient.Pan {
+		maxPan = g.cfg.Ambient.MaxPan
+	}
+	for _, a := range g.ambient {
+		vol, pan := ambientMix(a.src.X-lx, a.src.Y-ly, a.src.Radius, maxPan)
+		a.player.SetVolume(g.cfg.Audio.Volume * a.src.Volume * vol)
+		a.pan.setPan(pan)
+	}
+}
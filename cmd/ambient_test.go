@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestAmbientMix(t *testing.T) {
+	tests := []struct {
+		name        string
+		dx, dy      float64
+		radius, max float64
+		volume, pan float64
+	}{
+		{"at the source", 0, 0, 100, 0.8, 1, 0},
+		{"halfway, right", 50, 0, 100, 0.8, 0.5, 0.5},
+		{"halfway, left", -50, 0, 100, 0.8, 0.5, -0.5},
+		{"straight above", 0, -60, 100, 0.8, 0.4, 0},
+		{"diagonal", 30, 40, 100, 0.8, 0.5, 0.3},
+		{"at the radius", 100, 0, 100, 0.8, 0, 0.8},
+		{"out of range", 300, 0, 100, 0.8, 0, 0.8},
+		{"pan capped on the left", -90, 0, 100, 0.5, 0.1, -0.5},
+		{"no radius", 0, 0, 0, 0.8, 0, 0},
+	}
+	for _, tt := range tests {
+		v, p := ambientMix(tt.dx, tt.dy, tt.radius, tt.max)
+		if math.Abs(v-tt.volume) > 1e-12 || math.Abs(p-tt.pan) > 1e-12 {
+			t.Errorf("%s: ambientMix(%v, %v, %v, %v) = %v, %v, want %v, %v",
+				tt.name, tt.dx, tt.dy, tt.radius, tt.max, v, p, tt.volume, tt.pan)
+		}
+	}
+}
+
+// stereoPCM is frames of 16-bit stereo samples with both channels at v.
+func stereoPCM(frames int, v int16) []byte {
+	b := make([]byte, 4*frames)
+	for i := 0; i < len(b); i += 2 {
+		binary.LittleEndian.PutUint16(b[i:], uint16(v))
+	}
+	return b
+}
+
+func TestPanStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		pan         float64
+		left, right int16
+	}{
+		// equal power: the middle keeps both channels at full level
+		{"center", 0, 1000, 1000},
+		{"hard left", -1, 1414, 0},
+		{"hard right", 1, 0, 1414},
+		{"beyond right", 3, 0, 1414},
+	}
+	for _, tt := range tests {
+		s := newPanStream(bytes.NewReader(stereoPCM(8, 1000)))
+		s.setPan(tt.pan)
+		// an odd length reads whole frames only
+		buf := make([]byte, 4*8+3)
+		n, err := s.Read(buf)
+		if n != 32 || err != nil && err != io.EOF {
+			t.Fatalf("%s: Read = %d, %v, want 32 bytes", tt.name, n, err)
+		}
+		for i := 0; i < n; i += 4 {
+			l := int16(binary.LittleEndian.Uint16(buf[i:]))
+			r := int16(binary.LittleEndian.Uint16(buf[i+2:]))
+			if l != tt.left || r != tt.right {
+				t.Fatalf("%s: frame %d = %d, %d, want %d, %d", tt.name, i/4, l, r, tt.left, tt.right)
+			}
+		}
+	}
+}
+
+func TestPutSampleClips(t *testing.T) {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, uint16(30000))
+	putSample(b, 2)
+	if v := int16(binary.LittleEndian.Uint16(b)); v != math.MaxInt16 {
+		t.Errorf("30000 * 2 = %d, want %d", v, math.MaxInt16)
+	}
+	v0 := int16(-30000)
+	binary.LittleEndian.PutUint16(b, uint16(v0))
+	putSample(b, 2)
+	if v := int16(binary.LittleEndian.Uint16(b)); v != math.MinInt16 {
+		t.Errorf("-30000 * 2 = %d, want %d", v, math.MinInt16)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+)
+
+// defaultSampleRate is used when neither the config nor a music file
+// decides the audio context rate.
+const defaultSampleRate = 48000
+
+// decodeMusic decodes an MP3 resampled to rate. If that fails, or rate is
+// 0, it decodes at the file's native rate instead; the returned stream's
+// SampleRate tells which one was used.
+func decodeMusic(src io.ReadSeeker, rate int) (*mp3.Stream, error) {
+	if rate > 0 {
+		stream, err := mp3.DecodeWithSampleRate(rate, src)
+		if err == nil {
+			return stream, nil
+		}
+		slog.Warn("decoding music failed, retrying at native rate", "rate", rate, "err", err)
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	stream, err := mp3.DecodeWithoutResampling(src)
+	if err != nil {
+		return nil, fmt.Errorf("decode at native rate: %w", err)
+	}
+	return stream, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"image/color"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// drawBackdrop fills the map area behind the map, which shows in the bars
+// around a letterboxed or edge-peeking view, with map.backdrop: a solid
+// color, a vertical gradient or a tiled texture. Gradient and texture are
+// drawn into an image cached at the area's size, rebuilt once a resize
+// settles.
+func (g *Game) drawBackdrop(world *ebiten.Image) {
+	cfg := g.cfg.Map.Backdrop
+	area := world.Bounds()
+	if cfg.Mode != "gradient" && cfg.Mode != "texture" {
+		c := g.backdropColor(cfg.Color, &g.backdropSolid)
+		if c.A > 0 {
+			world.Fill(c)
+		}
+		return
+	}
+	w, h := area.Dx(), area.Dy()
+	if g.backdrop == nil || (g.resizeSettle <= 0 && (g.backdrop.Bounds().Dx() != w || g.backdrop.Bounds().Dy() != h)) {
+		if g.backdrop != nil {
+			g.backdrop.Deallocate()
+		}
+		g.backdrop = g.buildBackdrop(w, h)
+	}
+	op := &ebiten.DrawImageOptions{}
+	// stretched while a resize is still settling
+	op.GeoM.Scale(float64(w)/float64(g.backdrop.Bounds().Dx()), float64(h)/float64(g.backdrop.Bounds().Dy()))
+	op.GeoM.Translate(float64(area.Min.X), float64(area.Min.Y))
+	world.DrawImage(g.backdrop, op)
+}
+
+// buildBackdrop draws a w x h gradient or tiled texture, falling back to
+// the solid color when the texture can't be loaded.
+func (g *Game) buildBackdrop(w, h int) *ebiten.Image {
+	cfg := g.cfg.Map.Backdrop
+	img := ebiten.NewImage(max(w, 1), max(h, 1))
+	if cfg.Mode == "texture" {
+		tex, err := loadImage(cfg.Texture)
+		if err != nil {
+			slog.Warn("failed to load backdrop texture", "path", cfg.Texture, "err", err)
+			img.Fill(g.backdropColor(cfg.Color, &g.backdropSolid))
+			return img
+		}
+		tw, th := tex.Bounds().Dx(), tex.Bounds().Dy()
+		for y := 0; y < h; y += th {
+			for x := 0; x < w; x += tw {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(x), float64(y))
+				img.DrawImage(tex, op)
+			}
+		}
+		tex.Deallocate()
+		return img
+	}
+	top := g.backdropColor(cfg.Top, &g.backdropTop)
+	bottom := g.backdropColor(cfg.Bottom, &g.backdropBottom)
+	pixels := make([]byte, 4*img.Bounds().Dx()*img.Bounds().Dy())
+	for y := range img.Bounds().Dy() {
+		t := float64(y) / float64(max(h-1, 1))
+		c := lerpColor(top, bottom, t)
+		for x := range img.Bounds().Dx() {
+			i := 4 * (y*img.Bounds().Dx() + x)
+			// parseHexColor already premultiplied both ends
+			pixels[i] = c.R
+			pixels[i+1] = c.G
+			pixels[i+2] = c.B
+			pixels[i+3] = c.A
+		}
+	}
+	img.WritePixels(pixels)
+	return img
+}
+
+// backdropColor parses a backdrop color once into cache, using black if it
+// is invalid.
+func (g *Game) backdropColor(s string, cache **color.RGBA) color.RGBA {
+	if *cache == nil {
+		c, err := parseHexColor(s)
+		if err != nil {
+			slog.Warn("invalid backdrop color", "color", s, "err", err)
+			c = color.RGBA{A: 0xff}
+		}
+		*cache = &c
+	}
+	return **cache
+}
+
+// lerpColor blends from a to b by t in [0, 1].
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
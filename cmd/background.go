@@ -0,0 +1,467 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// mapPart is one image of a map split across several files, placed at its
+// offset in world coordinates.
+type mapPart struct {
+	path string
+	img  *ebiten.Image
+	// img halved once per LOD level, mips[0] being img itself
+	mips []*ebiten.Image
+	// decoded image kept on the CPU side for exports; nil for lazy parts,
+	// which are decoded again from path when exporting
+	src  image.Image
+	x, y int
+	// size in world pixels, known before a lazy part is loaded
+	w, h int
+}
+
+// background is the map drawn behind everything else, stitched from one
+// or more parts.
+type background struct {
+	parts []mapPart
+	// world size covered by all parts
+	w, h int
+	// repeat the parts, as one pw x ph pattern, across the world instead
+	// of drawing them once
+	tile   bool
+	pw, ph int
+	// the world wraps around, so copies are drawn past every edge
+	wrap bool
+	// LOD levels every part gets mips for, including parts loaded later,
+	// and the filter they are made with
+	mipLevels int
+	mipFilter string
+	// loads parts near the view in the background; nil when every part
+	// was loaded up front
+	lazy *partLoader
+}
+
+// defaultTileRepeat is how many copies of a tiled background fit across
+// the world on each axis when no world size is configured.
+const defaultTileRepeat = 8
+
+var partNumberRe = regexp.MustCompile(`(\d+)\D*$`)
+
+// partNumber extracts the trailing number of a file name such as
+// map-part12.jpg, or -1 if there is none.
+func partNumber(path string) int {
+	m := partNumberRe.FindStringSubmatch(filepath.Base(path))
+	if m == nil {
+		return -1
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// loadBackground loads every file matching pattern, ordered by part
+// number, and lays them out left to right in rows of columns parts
+// (a single row when columns <= 0). Lazy parts are only measured here and
+// loaded once they come near the view.
+func loadBackground(pattern string, columns int, lazy bool) (*background, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files match %s", pattern)
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return partNumber(paths[i]) < partNumber(paths[j])
+	})
+	if lazy {
+		sizes := make([]image.Point, len(paths))
+		for i, p := range paths {
+			size, err := imageSize(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p, err)
+			}
+			sizes[i] = size
+		}
+		return newLazyBackground(paths, sizes, rowLayout(sizes, columns)), nil
+	}
+	srcs := make([]image.Image, len(paths))
+	for i, p := range paths {
+		src, err := decodeImage(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		srcs[i] = src
+	}
+	return newBackground(paths, srcs, columns), nil
+}
+
+func newBackground(paths []string, srcs []image.Image, columns int) *background {
+	sizes := make([]image.Point, len(srcs))
+	for i, src := range srcs {
+		sizes[i] = src.Bounds().Size()
+	}
+	return newBackgroundAt(paths, srcs, rowLayout(sizes, columns))
+}
+
+// rowLayout places parts of the given sizes left to right in rows of
+// columns parts.
+func rowLayout(sizes []image.Point, columns int) []image.Point {
+	if columns <= 0 {
+		columns = len(sizes)
+	}
+	offsets := make([]image.Point, len(sizes))
+	x, y, rowH := 0, 0, 0
+	for i, size := range sizes {
+		if i > 0 && i%columns == 0 {
+			// start a new row below the tallest part of the previous one
+			x = 0
+			y += rowH
+			rowH = 0
+		}
+		offsets[i] = image.Pt(x, y)
+		x += size.X
+		rowH = max(rowH, size.Y)
+	}
+	return offsets
+}
+
+// newBackgroundAt places each part at an explicit world offset.
+func newBackgroundAt(paths []string, srcs []image.Image, offsets []image.Point) *background {
+	bg := &background{}
+	for i, src := range srcs {
+		off := offsets[i]
+		bg.parts = append(bg.parts, mapPart{
+			path: paths[i],
+			img:  ebiten.NewImageFromImage(src),
+			src:  src,
+			x:    off.X,
+			y:    off.Y,
+			w:    src.Bounds().Dx(),
+			h:    src.Bounds().Dy(),
+		})
+		bg.w = max(bg.w, off.X+src.Bounds().Dx())
+		bg.h = max(bg.h, off.Y+src.Bounds().Dy())
+	}
+	return bg
+}
+
+// loadBackgroundParts loads parts at the offsets given by map metadata.
+func loadBackgroundParts(parts []MetaPart, lazy bool) (*background, error) {
+	paths := make([]string, len(parts))
+	srcs := make([]image.Image, len(parts))
+	offsets := make([]image.Point, len(parts))
+	if lazy {
+		sizes := make([]image.Point, len(parts))
+		for i, p := range parts {
+			size, err := imageSize(p.File)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p.File, err)
+			}
+			paths[i] = p.File
+			sizes[i] = size
+			offsets[i] = image.Pt(p.X, p.Y)
+		}
+		return newLazyBackground(paths, sizes, offsets), nil
+	}
+	for i, p := range parts {
+		src, err := decodeImage(p.File)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.File, err)
+		}
+		paths[i] = p.File
+		srcs[i] = src
+		offsets[i] = image.Pt(p.X, p.Y)
+	}
+	return newBackgroundAt(paths, srcs, offsets), nil
+}
+
+// loadMapBackground loads the background as laid out by the map metadata
+// if it lists parts, otherwise from the configured glob. bench, if not nil,
+// times decoding and building the LOD levels.
+func loadMapBackground(cfg MapConfig, meta *MapMeta, bench *startupBench) (*background, error) {
+	var bg *background
+	var err error
+	if meta != nil && len(meta.Parts) > 0 {
+		bg, err = loadBackgroundParts(meta.Parts, cfg.Lazy.Enabled)
+	} else {
+		bg, err = loadBackground(cfg.Parts, cfg.Columns, cfg.Lazy.Enabled)
+	}
+	if err != nil {
+		return nil, err
+	}
+	bench.mark("decode map")
+	if cfg.LOD.Enabled {
+		bg.buildMips(len(cfg.LOD.Thresholds), cfg.LOD.Filter)
+		bench.mark("build lod")
+	}
+	bg.wrap = cfg.Wrap
+	if cfg.Mode == "tile" {
+		bg.tile = true
+		bg.pw, bg.ph = bg.w, bg.h
+		bg.w, bg.h = bg.pw*defaultTileRepeat, bg.ph*defaultTileRepeat
+		if cfg.WorldWidth > 0 && cfg.WorldHeight > 0 {
+			bg.w, bg.h = cfg.WorldWidth, cfg.WorldHeight
+		}
+	}
+	// the metadata may declare a world larger or smaller than the images
+	if meta != nil && meta.Width > 0 && meta.Height > 0 {
+		bg.w, bg.h = meta.Width, meta.Height
+	}
+	return bg, nil
+}
+
+func (b *background) size() (int, int) {
+	return b.w, b.h
+}
+
+// buildMips prepares levels successively halved copies of every part for
+// drawing zoomed out. filter "gaussian" makes smoother levels on the CPU,
+// which is slower; anything else halves with a box filter on the GPU.
+func (b *background) buildMips(levels int, filter string) {
+	b.mipLevels = levels
+	b.mipFilter = filter
+	for i := range b.parts {
+		if b.parts[i].img != nil {
+			b.parts[i].buildMips(levels, filter, b.parts[i].src)
+		}
+	}
+}
+
+// buildMips makes the part's levels, from src when smoothing since that
+// needs the pixels on the CPU.
+func (p *mapPart) buildMips(levels int, filter string, src image.Image) {
+	p.mips = []*ebiten.Image{p.img}
+	if filter == "gaussian" && src != nil {
+		cur := toRGBA(src)
+		for range levels {
+			if cur = halveSmooth(cur); cur == nil {
+				break
+			}
+			p.mips = append(p.mips, ebiten.NewImageFromImage(cur))
+		}
+		return
+	}
+	for range levels {
+		prev := p.mips[len(p.mips)-1]
+		w, h := prev.Bounds().Dx()/2, prev.Bounds().Dy()/2
+		if w < 1 || h < 1 {
+			break
+		}
+		mip := ebiten.NewImage(w, h)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(float64(w)/float64(prev.Bounds().Dx()), float64(h)/float64(prev.Bounds().Dy()))
+		op.Filter = ebiten.FilterLinear
+		mip.DrawImage(prev, op)
+		p.mips = append(p.mips, mip)
+	}
+}
+
+// mip returns the image for a LOD level, falling back to the coarsest one
+// there is.
+func (p mapPart) mip(level int) *ebiten.Image {
+	if len(p.mips) == 0 {
+		return p.img
+	}
+	return p.mips[min(max(level, 0), len(p.mips)-1)]
+}
+
+// draw renders every part at a LOD level with the world transform
+// screen = world*scale + (tx, ty). Each part is stretched by overlap
+// screen pixels to the right and bottom so rounding can't open seams
+// between neighbors. filter is how parts are sampled when scaled.
+func (b *background) draw(screen *ebiten.Image, level int, scale, tx, ty, overlap float64, alpha float32, filter ebiten.Filter) {
+	if b.wrap {
+		b.drawWrapped(screen, level, scale, tx, ty, overlap, alpha, filter)
+		return
+	}
+	b.drawOnce(screen, level, scale, tx, ty, overlap, alpha, filter)
+}
+
+// drawWrapped draws the copies of a wrapping world that overlap the
+// screen.
+func (b *background) drawWrapped(screen *ebiten.Image, level int, scale, tx, ty, overlap float64, alpha float32, filter ebiten.Filter) {
+	sb := screen.Bounds()
+	ww, wh := float64(b.w)*scale, float64(b.h)*scale
+	i0 := int(math.Floor((float64(sb.Min.X) - tx) / ww))
+	i1 := int(math.Floor((float64(sb.Max.X) - tx) / ww))
+	j0 := int(math.Floor((float64(sb.Min.Y) - ty) / wh))
+	j1 := int(math.Floor((float64(sb.Max.Y) - ty) / wh))
+	for j := j0; j <= j1; j++ {
+		for i := i0; i <= i1; i++ {
+			b.drawOnce(screen, level, scale, tx+float64(i)*ww, ty+float64(j)*wh, overlap, alpha, filter)
+		}
+	}
+}
+
+func (b *background) drawOnce(screen *ebiten.Image, level int, scale, tx, ty, overlap float64, alpha float32, filter ebiten.Filter) {
+	if b.tile {
+		b.drawTiled(screen, level, scale, tx, ty, overlap, alpha, filter)
+		return
+	}
+	b.drawAt(screen, level, scale, tx, ty, overlap, alpha, filter)
+}
+
+func (b *background) drawAt(screen *ebiten.Image, level int, scale, tx, ty, overlap float64, alpha float32, filter ebiten.Filter) {
+	for _, p := range b.parts {
+		if p.img == nil {
+			continue
+		}
+		img := p.mip(level)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM = p.geoM(img.Bounds().Dx(), img.Bounds().Dy(), scale, tx, ty, overlap)
+		op.ColorScale.ScaleAlpha(alpha)
+		op.Filter = filter
+		screen.DrawImage(img, op)
+	}
+}
+
+// countVisible tallies the lazy parts the view sb shows with the world
+// transform, as hits when they are loaded and misses when they aren't.
+// It is called once a frame for the main map pass; draw can visit a part
+// several times a frame, for a LOD crossfade, wrapped copies or tiles, and
+// the minimap and magnifier draw it again, so drawing doesn't count.
+func (b *background) countVisible(sb image.Rectangle, scale, tx, ty float64) {
+	if b.lazy == nil || scale <= 0 {
+		return
+	}
+	view := bounds{
+		(float64(sb.Min.X) - tx) / scale, (float64(sb.Min.Y) - ty) / scale,
+		(float64(sb.Max.X) - tx) / scale, (float64(sb.Max.Y) - ty) / scale,
+	}
+	// the visible world, split into the pieces that lie inside the map
+	w, h := float64(b.w), float64(b.h)
+	views := []bounds{{max(view.minX, 0), max(view.minY, 0), min(view.maxX, w), min(view.maxY, h)}}
+	if b.wrap {
+		views = wrappedRects(view, w, h)
+	}
+	if b.tile {
+		// and folded into the pattern the parts are laid out in
+		var pattern []bounds
+		for _, v := range views {
+			if v.minX < v.maxX && v.minY < v.maxY {
+				pattern = append(pattern, wrappedRects(v, float64(b.pw), float64(b.ph))...)
+			}
+		}
+		views = pattern
+	}
+	for _, p := range b.parts {
+		if !p.overlaps(views) {
+			continue
+		}
+		if p.img == nil {
+			b.lazy.misses++
+		} else {
+			b.lazy.hits++
+		}
+	}
+}
+
+// overlaps reports whether the part covers some of one of the world
+// rectangles.
+func (p mapPart) overlaps(rs []bounds) bool {
+	x0, y0 := float64(p.x), float64(p.y)
+	x1, y1 := x0+float64(p.w), y0+float64(p.h)
+	for _, r := range rs {
+		if r.minX < r.maxX && r.minY < r.maxY && x1 > r.minX && y1 > r.minY && x0 < r.maxX && y0 < r.maxY {
+			return true
+		}
+	}
+	return false
+}
+
+// geoM places an imgW x imgH image of the part, the part itself or one of
+// its mips, on screen with the world transform, stretched by overlap
+// screen pixels to the right and bottom.
+func (p mapPart) geoM(imgW, imgH int, scale, tx, ty, overlap float64) ebiten.GeoM {
+	// a mip covers the same world area with fewer pixels
+	w, h := float64(imgW), float64(imgH)
+	var m ebiten.GeoM
+	m.Scale(scale*float64(p.w)/w+overlap/w, scale*float64(p.h)/h+overlap/h)
+	m.Translate(float64(p.x)*scale+tx, float64(p.y)*scale+ty)
+	return m
+}
+
+// drawTiled repeats the pattern over the visible part of the world,
+// clipped to the world's edges.
+func (b *background) drawTiled(screen *ebiten.Image, level int, scale, tx, ty, overlap float64, alpha float32, filter ebiten.Filter) {
+	sw, sh := float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy())
+	clip := image.Rect(int(tx), int(ty), int(math.Ceil(float64(b.w)*scale+tx)), int(math.Ceil(float64(b.h)*scale+ty)))
+	dst, ok := screen.SubImage(clip.Intersect(screen.Bounds())).(*ebiten.Image)
+	if !ok || dst.Bounds().Empty() {
+		return
+	}
+	// visible world rectangle, in whole pattern copies
+	x0 := max(int(math.Floor(-tx/scale))/b.pw, 0)
+	y0 := max(int(math.Floor(-ty/scale))/b.ph, 0)
+	x1 := min(int(math.Ceil((sw-tx)/scale)), b.w) / b.pw
+	y1 := min(int(math.Ceil((sh-ty)/scale)), b.h) / b.ph
+	for j := y0; j <= y1; j++ {
+		for i := x0; i <= x1; i++ {
+			ox := float64(i*b.pw) * scale
+			oy := float64(j*b.ph) * scale
+			b.drawAt(dst, level, scale, tx+ox, ty+oy, overlap, alpha, filter)
+		}
+	}
+}
+
+// deallocate frees the GPU images of every part.
+func (b *background) deallocate() {
+	for _, p := range b.parts {
+		if p.img == nil {
+			continue
+		}
+		p.img.Deallocate()
+		// mips[0] is img, already freed
+		for _, m := range p.mips[min(1, len(p.mips)):] {
+			m.Deallocate()
+		}
+	}
+}
+
+// compose stitches the decoded parts into a single full-resolution image,
+// repeating them in tile mode. Lazy parts are decoded from disk again,
+// skipping any that fail. It only reads fields that never change after
+// loading, so it is safe to call off the game goroutine.
+func (b *background) compose() *image.RGBA {
+	srcs := b.parts
+	if b.lazy != nil {
+		srcs = nil
+		for i := range b.parts {
+			// the game goroutine fills in img, so only touch the rest
+			p := &b.parts[i]
+			src, err := decodeImage(p.path)
+			if err != nil {
+				slog.Warn("failed to decode map part for export", "path", p.path, "err", err)
+				continue
+			}
+			srcs = append(srcs, mapPart{src: src, x: p.x, y: p.y})
+		}
+	}
+	out := image.NewRGBA(image.Rect(0, 0, b.w, b.h))
+	stepX, stepY := b.w, b.h
+	if b.tile {
+		stepX, stepY = b.pw, b.ph
+	}
+	for oy := 0; oy < b.h; oy += stepY {
+		for ox := 0; ox < b.w; ox += stepX {
+			for _, p := range srcs {
+				sb := p.src.Bounds()
+				x, y := ox+p.x, oy+p.y
+				draw.Draw(out, image.Rect(x, y, x+sb.Dx(), y+sb.Dy()), p.src, sb.Min, draw.Src)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Two parts side by side and one below: at any scale and offset, each part
+// must reach overlap screen pixels into its right and bottom neighbors, so
+// rounding the edges to pixels can't leave a transparent seam between
+// them. Drawing and reading back pixels needs a GPU; the placement is what
+// decides whether a seam can open.
+func TestPartGeoMOverlapsNeighbors(t *testing.T) {
+	left := mapPart{x: 0, y: 0, w: 4096, h: 4096}
+	right := mapPart{x: 4096, y: 0, w: 4096, h: 4096}
+	below := mapPart{x: 0, y: 4096, w: 4096, h: 4096}
+	for _, overlap := range []float64{0, 0.5, 1, 2} {
+		for _, scale := range []float64{0.1, 0.137, 0.25, 1 / 3.0, 0.71, 1, 1.9} {
+			for _, mip := range []int{0, 1, 3} {
+				imgW, imgH := 4096>>mip, 4096>>mip
+				tx, ty := -123.37, 45.61
+				lm := left.geoM(imgW, imgH, scale, tx, ty, overlap)
+				rm := right.geoM(imgW, imgH, scale, tx, ty, overlap)
+				bm := below.geoM(imgW, imgH, scale, tx, ty, overlap)
+
+				leftEnd, leftBottom := lm.Apply(float64(imgW), float64(imgH))
+				rightStart, _ := rm.Apply(0, 0)
+				_, belowStart := bm.Apply(0, 0)
+				if d := leftEnd - rightStart; math.Abs(d-overlap) > 1e-9 {
+					t.Errorf("overlap %v scale %v mip %v: left part ends %v px past its right neighbor's start, want %v", overlap, scale, mip, d, overlap)
+				}
+				if d := leftBottom - belowStart; math.Abs(d-overlap) > 1e-9 {
+					t.Errorf("overlap %v scale %v mip %v: top part ends %v px past the one below, want %v", overlap, scale, mip, d, overlap)
+				}
+				// the part itself still starts at its world position
+				x0, y0 := lm.Apply(0, 0)
+				if x0 != tx || y0 != ty {
+					t.Errorf("overlap %v scale %v mip %v: part starts at %v,%v, want %v,%v", overlap, scale, mip, x0, y0, tx, ty)
+				}
+			}
+		}
+	}
+}
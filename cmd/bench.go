@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+)
+
+// startupBench logs how long each startup phase takes with -bench, as
+// "bench phase=<name> ms=<duration>" lines. Its methods do nothing on a nil
+// receiver so callers needn't check whether it is enabled.
+type startupBench struct {
+	start, last time.Time
+	// exit once the first frame has been drawn
+	exit bool
+	// the first frame has been drawn and timed
+	done bool
+}
+
+func newStartupBench(start time.Time, exit bool) *startupBench {
+	return &startupBench{start: start, last: start, exit: exit}
+}
+
+// mark logs the time since the previous mark as the named phase.
+func (b *startupBench) mark(phase string) {
+	if b == nil {
+		return
+	}
+	now := time.Now()
+	logPhase(phase, now.Sub(b.last))
+	b.last = now
+}
+
+// frameDrawn logs the first frame's render time, starting at frameStart,
+// and the total time from launch to it.
+func (b *startupBench) frameDrawn(frameStart time.Time) {
+	if b == nil || b.done {
+		return
+	}
+	b.done = true
+	now := time.Now()
+	logPhase("first frame", now.Sub(frameStart))
+	logPhase("total", now.Sub(b.start))
+}
+
+// finished reports whether the game should exit now that the benchmark is
+// over.
+func (b *startupBench) finished() bool {
+	return b != nil && b.exit && b.done
+}
+
+func logPhase(phase string, d time.Duration) {
+	slog.Info("bench", "phase", phase, "ms", float64(d.Microseconds())/1000)
+}
@@ -0,0 +1,161 @@
+package main
+
+import "math"
+
+// moveCameraTo centers the camera on world point (x, y). Unless instant,
+// camera smoothing is off or the point is further than
+// camera.snapDistance away, the camera eases there over the following
+// updates; repeated calls just retarget it. A move that isn't instant and
+// shifts the target by less than camera.minMove screen pixels is ignored.
+func (g *Game) moveCameraTo(x, y float64, instant bool) {
+	vw, vh := g.viewSize()
+	tx, ty := x-vw/2, y-vh/2
+	if !instant && g.belowMinMove(tx, ty) {
+		return
+	}
+	snap := g.cfg.Camera.SnapDistance
+	far := snap > 0 && math.Hypot(tx-g.vx, ty-g.vy) > snap
+	// the new position is the bare target, without any inertia drift
+	g.shownDX, g.shownDY = 0, 0
+	if instant || far || !g.cfg.Camera.Smooth || g.cfg.Camera.SmoothTime <= 0 {
+		g.vx, g.vy = tx, ty
+		g.camMoving = false
+		return
+	}
+	g.camTargetX, g.camTargetY = tx, ty
+	g.camMoving = true
+}
+
+// belowMinMove reports whether moving the camera's target to view origin
+// (tx, ty) is too small a change to bother with, measured from the target
+// of an eased move in progress or else from the view itself.
+func (g *Game) belowMinMove(tx, ty float64) bool {
+	minMove := g.cfg.Camera.MinMove
+	if minMove <= 0 {
+		return false
+	}
+	fx, fy := g.vx, g.vy
+	if g.camMoving {
+		fx, fy = g.camTargetX, g.camTargetY
+	}
+	scale, _, _ := g.viewTransform(g.screenW, g.screenH)
+	return math.Hypot(tx-fx, ty-fy)*scale < minMove
+}
+
+// stopCamera cancels an eased move, for input that moves the camera
+// directly.
+func (g *Game) stopCamera() {
+	g.camMoving = false
+}
+
+// easeToward moves v toward target, covering the fraction of the distance
+// an exponential ease with time constant tau covers in dt seconds.
+func easeToward(v, target, dt, tau float64) float64 {
+	if tau <= 0 {
+		return target
+	}
+	return v + (target-v)*(1-math.Exp(-dt/tau))
+}
+
+// updateCamera advances an eased camera move, finishing once the camera
+// is within half a screen pixel of the target. The target is clamped like
+// the view so a move toward the map edge can finish.
+func (g *Game) updateCamera() {
+	if !g.camMoving {
+		return
+	}
+	tx, ty := g.clampedView(g.camTargetX, g.camTargetY)
+	dt, tau := deltaTime(), g.cfg.Camera.SmoothTime
+	g.vx = easeToward(g.vx, tx, dt, tau)
+	g.vy = easeToward(g.vy, ty, dt, tau)
+	scale, _, _ := g.viewTransform(g.screenW, g.screenH)
+	if scale > 0 && math.Hypot(tx-g.vx, ty-g.vy)*scale < 0.5 {
+		g.vx, g.vy = tx, ty
+		g.camMoving = false
+	}
+}
+
+// driveCamera adds to the velocity, in world pixels per second, the camera
+// is being moved at this update, for camera inertia to carry on with once
+// nothing moves it.
+func (g *Game) driveCamera(vx, vy float64) {
+	g.camDriveX += vx
+	g.camDriveY += vy
+	g.camDriven = true
+}
+
+// glideCamera gives the camera momentum with camera.inertia. While it is
+// driven it takes on the driving velocity, capped at maxSpeed; after that
+// the velocity decays by friction per second. The free camera glides to a
+// stop; a following camera drifts past the player and eases back onto
+// them. The drift is an offset from where following put the camera: when
+// following skipped a move too small to make, the view still holds the
+// drift applied before, so only its change is added. It runs after
+// following and before the eased move and the clamp.
+func (g *Game) glideCamera() {
+	cfg := g.cfg.Camera.Inertia
+	dt := deltaTime()
+	driven := g.camDriven
+	driveX, driveY := g.camDriveX, g.camDriveY
+	g.camDriveX, g.camDriveY, g.camDriven = 0, 0, false
+	if !cfg.Enabled || cfg.Friction <= 0 {
+		g.camVX, g.camVY, g.driftX, g.driftY = 0, 0, 0, 0
+		g.shownDX, g.shownDY = 0, 0
+		return
+	}
+	scale, _, _ := g.viewTransform(g.screenW, g.screenH)
+	if scale <= 0 {
+		return
+	}
+	var glideX, glideY float64
+	if driven {
+		g.camVX, g.camVY = driveX, driveY
+		// a jump, like wrapping around the map, is no motion to carry on
+		if speed := math.Hypot(driveX, driveY) * scale; cfg.MaxSpeed > 0 && speed > cfg.MaxSpeed {
+			if speed > 2*cfg.MaxSpeed {
+				g.camVX, g.camVY = 0, 0
+			} else {
+				g.camVX *= cfg.MaxSpeed / speed
+				g.camVY *= cfg.MaxSpeed / speed
+			}
+		}
+	} else {
+		decay := math.Exp(-cfg.Friction * dt)
+		g.camVX *= decay
+		g.camVY *= decay
+		// settled once it moves less than a screen pixel a second
+		if math.Hypot(g.camVX, g.camVY)*scale < 1 {
+			g.camVX, g.camVY = 0, 0
+		}
+		glideX, glideY = g.camVX*dt, g.camVY*dt
+	}
+	if g.freeCam {
+		g.vx += glideX
+		g.vy += glideY
+		return
+	}
+	g.driftX = easeToward(g.driftX, 0, dt, 1/cfg.Friction) + glideX
+	g.driftY = easeToward(g.driftY, 0, dt, 1/cfg.Friction) + glideY
+	dx, dy := g.driftX-g.shownDX, g.driftY-g.shownDY
+	g.shownDX, g.shownDY = g.driftX, g.driftY
+	if g.camMoving {
+		g.camTargetX += dx
+		g.camTargetY += dy
+	} else {
+		g.vx += dx
+		g.vy += dy
+	}
+}
+
+// clampGlide clamps the view and stops any momentum on an axis the clamp
+// held back, so gliding never shows past the map edges.
+func (g *Game) clampGlide() {
+	vx, vy := g.vx, g.vy
+	g.clampView()
+	if g.vx != vx {
+		g.camVX, g.driftX, g.shownDX = 0, 0, 0
+	}
+	if g.vy != vy {
+		g.camVY, g.driftY, g.shownDY = 0, 0, 0
+	}
+}
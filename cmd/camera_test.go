@@ -0,0 +1,241 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEaseToward(t *testing.T) {
+	if got := easeToward(0, 100, 0.016, 0); got != 100 {
+		t.Errorf("easeToward with no time constant = %v, want the target", got)
+	}
+	// after one time constant, 1 - 1/e of the way there
+	if got, want := easeToward(0, 100, 0.5, 0.5), 100*(1-1/math.E); math.Abs(got-want) > 1e-9 {
+		t.Errorf("easeToward after tau = %v, want %v", got, want)
+	}
+	// successive steps close in on the target from one side
+	v := 0.0
+	for i := range 200 {
+		next := easeToward(v, 100, 1.0/60, 0.12)
+		if next <= v || next > 100 {
+			t.Fatalf("step %d went from %v to %v", i, v, next)
+		}
+		v = next
+	}
+	if 100-v > 1e-6 {
+		t.Errorf("after 200 steps still %v from the target", 100-v)
+	}
+}
+
+// cameraTarget is the view origin moveCameraTo(x, y) aims for.
+func cameraTarget(g *Game, x, y float64) (float64, float64) {
+	vw, vh := g.viewSize()
+	return g.clampedView(x-vw/2, y-vh/2)
+}
+
+func TestMoveCameraToInstant(t *testing.T) {
+	g := newTestGame(8192, 4096)
+	g.cfg.Camera.Smooth = true
+	g.moveCameraTo(3000, 2000, true)
+	if wx, wy := cameraTarget(g, 3000, 2000); g.vx != wx || g.vy != wy {
+		t.Errorf("instant move left the view at %v,%v, want %v,%v", g.vx, g.vy, wx, wy)
+	}
+	if g.camMoving {
+		t.Error("instant move left an eased move running")
+	}
+}
+
+func TestMoveCameraToSmoothConverges(t *testing.T) {
+	g := newTestGame(8192, 4096)
+	g.cfg.Camera.Smooth = true
+	g.vx, g.vy = 2000, 1000
+	g.moveCameraTo(3000, 2000, false)
+	if g.vx != 2000 || g.vy != 1000 {
+		t.Fatalf("smooth move jumped to %v,%v right away", g.vx, g.vy)
+	}
+	wx, wy := cameraTarget(g, 3000, 2000)
+	dist := math.Hypot(wx-g.vx, wy-g.vy)
+	for i := 0; g.camMoving; i++ {
+		if i > 600 {
+			t.Fatalf("still %v from the target after 10 seconds", dist)
+		}
+		g.updateCamera()
+		d := math.Hypot(wx-g.vx, wy-g.vy)
+		if d > dist {
+			t.Fatalf("update %d moved away from the target, %v to %v", i, dist, d)
+		}
+		dist = d
+	}
+	if g.vx != wx || g.vy != wy {
+		t.Errorf("smooth move settled at %v,%v, want %v,%v", g.vx, g.vy, wx, wy)
+	}
+}
+
+func TestMoveCameraToWithoutSmoothing(t *testing.T) {
+	g := newTestGame(8192, 4096)
+	g.cfg.Camera.Smooth = false
+	g.moveCameraTo(3000, 2000, false)
+	if wx, wy := cameraTarget(g, 3000, 2000); g.vx != wx || g.vy != wy || g.camMoving {
+		t.Errorf("move with smoothing off left the view at %v,%v (moving %v), want %v,%v", g.vx, g.vy, g.camMoving, wx, wy)
+	}
+}
+
+// Moves further than camera.snapDistance jump instead of easing.
+func TestMoveCameraToSnapsWhenFar(t *testing.T) {
+	g := newTestGame(8192, 4096)
+	g.cfg.Camera.Smooth = true
+	g.cfg.Camera.SnapDistance = 1500
+	g.vx, g.vy = 0, 0
+	g.moveCameraTo(7000, 3000, false)
+	if g.camMoving {
+		t.Error("far move eased instead of snapping")
+	}
+	if wx, wy := cameraTarget(g, 7000, 3000); g.vx != wx || g.vy != wy {
+		t.Errorf("far move left the view at %v,%v, want %v,%v", g.vx, g.vy, wx, wy)
+	}
+}
+
+// inertiaGame is a test game whose camera follows the player with inertia
+// and a minimum move, so a standing player leaves follow() with nothing
+// to do on most updates.
+func inertiaGame(smooth bool) *Game {
+	g := newTestGame(8192, 8192)
+	g.cfg.Camera.Inertia = CameraInertiaConfig{Enabled: true, Friction: 4}
+	g.cfg.Camera.MinMove = 2
+	g.cfg.Camera.Smooth = smooth
+	g.follow(true)
+	return g
+}
+
+// cameraUpdate runs the camera steps of one update for a following camera.
+func cameraUpdate(g *Game) {
+	g.follow(false)
+	g.glideCamera()
+	g.updateCamera()
+	g.clampGlide()
+}
+
+// The drift stays an offset from the follow target however often
+// following skips a small move, and dies away back onto the player.
+func TestGlideDriftIsAnOffset(t *testing.T) {
+	for _, smooth := range []bool{false, true} {
+		g := inertiaGame(smooth)
+		baseX, baseY := g.vx, g.vy
+		// one update of the camera being pushed right, then let go
+		g.driveCamera(300, 0)
+		cameraUpdate(g)
+		for i := range 600 {
+			cameraUpdate(g)
+			x := g.vx
+			if g.camMoving {
+				x = g.camTargetX
+			}
+			if d := x - baseX - g.driftX; math.Abs(d) > 1e-9 {
+				t.Fatalf("smooth %v, update %d: camera %v past the player with drift %v", smooth, i, x-baseX, g.driftX)
+			}
+			if g.vy != baseY {
+				t.Fatalf("smooth %v, update %d: camera moved vertically to %v", smooth, i, g.vy)
+			}
+		}
+		if g.driftX > 1e-3 || math.Abs(g.vx-baseX) > 0.5 {
+			t.Errorf("smooth %v: after 10s still %v past the player with drift %v", smooth, g.vx-baseX, g.driftX)
+		}
+	}
+}
+
+// The drift rises while the glide carries on and then eases back: it
+// never passes how far the velocity alone could carry the camera.
+func TestGlideDriftBounded(t *testing.T) {
+	g := inertiaGame(false)
+	baseX := g.vx
+	g.driveCamera(300, 0)
+	cameraUpdate(g)
+	// the velocity decays at friction 4, so it carries the camera at most
+	// 300/4 world pixels
+	peak := 0.0
+	for range 600 {
+		cameraUpdate(g)
+		peak = max(peak, g.vx-baseX)
+	}
+	if peak <= 0 || peak > 300.0/4 {
+		t.Errorf("drift peaked at %v, want within (0, %v]", peak, 300.0/4)
+	}
+}
+
+func TestBelowMinMove(t *testing.T) {
+	g := newTestGame(8192, 8192)
+	g.vx, g.vy = 1000, 1000
+	if g.belowMinMove(1000.1, 1000) {
+		t.Error("camera.minMove 0 ignored a move")
+	}
+	g.cfg.Camera.MinMove = 3
+	tests := []struct {
+		name   string
+		tx, ty float64
+		want   bool
+	}{
+		{"no move", 1000, 1000, true},
+		{"tiny", 1001, 1002, true},
+		{"just under", 1002.99, 1000, true},
+		{"at the limit", 1003, 1000, false},
+		{"diagonal past the limit", 1002.5, 1002.5, false},
+	}
+	for _, tt := range tests {
+		if got := g.belowMinMove(tt.tx, tt.ty); got != tt.want {
+			t.Errorf("%s: belowMinMove(%v, %v) = %v, want %v", tt.name, tt.tx, tt.ty, got, tt.want)
+		}
+	}
+	// the limit is in screen pixels, so zoomed in by 2 a world pixel and
+	// a half is enough
+	g.zoom = 2
+	if g.belowMinMove(1001.5, 1000) {
+		t.Error("at zoom 2, 1.5 world pixels (3 screen pixels) counted as too small")
+	}
+}
+
+// Small moves while easing are measured from the eased target, not from
+// where the camera happens to be.
+func TestBelowMinMoveMeasuresFromTarget(t *testing.T) {
+	g := newTestGame(8192, 8192)
+	g.cfg.Camera.MinMove = 3
+	g.vx, g.vy = 1000, 1000
+	g.camMoving = true
+	g.camTargetX, g.camTargetY = 1500, 1000
+	if !g.belowMinMove(1501, 1000) {
+		t.Error("a 1 pixel retarget counted as worth making")
+	}
+	if g.belowMinMove(1001, 1000) {
+		t.Error("moving the target back by 499 pixels counted as too small")
+	}
+}
+
+// A player shuffling by less than camera.minMove leaves the camera, and
+// its eased target, where they were.
+func TestMinMoveIgnoresJitter(t *testing.T) {
+	for _, smooth := range []bool{false, true} {
+		g := newTestGame(8192, 8192)
+		g.cfg.Camera.MinMove = 3
+		g.cfg.Camera.Smooth = smooth
+		g.follow(true)
+		vx, vy := g.vx, g.vy
+		for i := range 30 {
+			d := []float64{2, -2}[i%2]
+			g.px += d
+			g.py -= d
+			g.follow(false)
+			g.updateCamera()
+		}
+		if g.vx != vx || g.vy != vy || g.camMoving {
+			t.Errorf("smooth %v: jitter moved the camera to %v, %v (easing %v)", smooth, g.vx, g.vy, g.camMoving)
+		}
+		// a real move is still followed
+		g.px += 50
+		g.follow(false)
+		for range 120 {
+			g.updateCamera()
+		}
+		if want := vx + 50; math.Abs(g.vx-want) > 0.5 {
+			t.Errorf("smooth %v: after a 50 pixel move the camera is at %v, want %v", smooth, g.vx, want)
+		}
+	}
+}
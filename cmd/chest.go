@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"math/rand/v2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	chestColor    = color.RGBA{0x8b, 0x52, 0x1c, 0xff}
+	chestLidColor = color.RGBA{0xff, 0xc8, 0x30, 0xff}
+)
+
+// chest is a collectible placed on the map.
+type chest struct {
+	x, y      float64
+	collected bool
+}
+
+// placeChests puts the configured chests on the map, followed by
+// chests.count more at random walkable spots. The random spots come from
+// chests.seed, so they are the same every run and the saved collected
+// chests still match. Chests at a saved position start collected.
+func (g *Game) placeChests(collected [][2]float64) {
+	cfg := g.cfg.Chests
+	g.chests = nil
+	for _, p := range cfg.Positions {
+		g.chests = append(g.chests, chest{x: p.X, y: p.Y})
+	}
+	if cfg.Count > 0 && g.bg != nil {
+		rng := rand.New(rand.NewPCG(uint64(cfg.Seed), uint64(cfg.Seed)))
+		wb := g.worldBounds()
+		w, h := float64(g.playerW), float64(g.playerH)
+		for range cfg.Count {
+			var x, y float64
+			// give up on finding an open spot after a while rather than
+			// looping forever on a mostly blocked map
+			for range 32 {
+				x = wb.minX + w/2 + rng.Float64()*max(wb.maxX-wb.minX-w, 0)
+				y = wb.minY + h/2 + rng.Float64()*max(wb.maxY-wb.minY-h, 0)
+				if !g.blocked(x-w/2, y-h/2) {
+					break
+				}
+			}
+			g.chests = append(g.chests, chest{x: math.Round(x), y: math.Round(y)})
+		}
+	}
+	for i, c := range g.chests {
+		for _, p := range collected {
+			if c.x == p[0] && c.y == p[1] {
+				g.chests[i].collected = true
+			}
+		}
+	}
+}
+
+// chestCount is how many chests have been collected and how many there
+// are.
+func chestCount(chests []chest) (collected, total int) {
+	for _, c := range chests {
+		if c.collected {
+			collected++
+		}
+	}
+	return collected, len(chests)
+}
+
+// updateChests collects the chests the player is standing on, right away
+// with chests.autoCollect, otherwise when Space is pressed.
+func (g *Game) updateChests() {
+	if len(g.chests) == 0 {
+		return
+	}
+	if !g.cfg.Chests.AutoCollect && !g.justPressed(ebiten.KeySpace) {
+		return
+	}
+	for i, c := range g.chests {
+		if c.collected || c.x < g.px || c.y < g.py || c.x >= g.px+float64(g.playerW) || c.y >= g.py+float64(g.playerH) {
+			continue
+		}
+		g.chests[i].collected = true
+		n, total := chestCount(g.chests)
+		if n == total {
+			g.toast(fmt.Sprintf("All %d chests found!", total))
+		} else {
+			g.toast(fmt.Sprintf("Chest %d/%d", n, total))
+		}
+		g.rumble("chest", 1)
+		if g.chestSound != nil && g.audioContext != nil {
+			g.audioContext.NewPlayerFromBytes(g.chestSound).Play()
+		}
+	}
+}
+
+// chestText is the HUD counter line.
+func (g *Game) chestText() string {
+	n, total := chestCount(g.chests)
+	return fmt.Sprintf("Chests %d/%d", n, total)
+}
+
+// collectedChests lists the positions of the collected chests for the save.
+func (g *Game) collectedChests() [][2]float64 {
+	var out [][2]float64
+	for _, c := range g.chests {
+		if c.collected {
+			out = append(out, [2]float64{c.x, c.y})
+		}
+	}
+	return out
+}
+
+// drawChestIcon draws a small chest centered on (x, y).
+func drawChestIcon(dst *ebiten.Image, x, y float32) {
+	vector.FillRect(dst, x-6, y-4, 12, 9, chestColor, false)
+	vector.FillRect(dst, x-6, y-5, 12, 3, chestLidColor, false)
+	vector.FillRect(dst, x-1, y-2, 2, 3, chestLidColor, false)
+	vector.StrokeRect(dst, x-6, y-5, 12, 10, 1, color.Black, false)
+}
+
+// drawChests draws the chests not collected yet.
+func (g *Game) drawChests(screen *ebiten.Image) {
+	scale, tx, ty := g.worldTransform(g.screenW, g.screenH)
+	for _, c := range g.chests {
+		if c.collected {
+			continue
+		}
+		drawChestIcon(screen, float32(c.x*scale+tx), float32(c.y*scale+ty))
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestChestCount(t *testing.T) {
+	tests := []struct {
+		name             string
+		chests           []chest
+		collected, total int
+	}{
+		{"none", nil, 0, 0},
+		{"none collected", []chest{{x: 1}, {x: 2}}, 0, 2},
+		{"some collected", []chest{{collected: true}, {}, {collected: true}}, 2, 3},
+		{"all collected", []chest{{collected: true}, {collected: true}}, 2, 2},
+	}
+	for _, tt := range tests {
+		n, total := chestCount(tt.chests)
+		if n != tt.collected || total != tt.total {
+			t.Errorf("%s: chestCount = %d, %d, want %d, %d", tt.name, n, total, tt.collected, tt.total)
+		}
+	}
+}
+
+// Random chests land in the same spots for the same seed, so a saved
+// collected chest is found again on the next run.
+func TestPlaceChestsSeeded(t *testing.T) {
+	g := newTestGame(2000, 1000)
+	g.cfg.Chests.Count = 5
+	g.cfg.Chests.Seed = 42
+	g.cfg.Chests.Positions = []Label{{X: 100, Y: 200}}
+	g.placeChests(nil)
+	if len(g.chests) != 6 || g.chests[0].x != 100 || g.chests[0].y != 200 {
+		t.Fatalf("placed %v, want the fixed chest then 5 random ones", g.chests)
+	}
+	first := slices.Clone(g.chests)
+	for _, c := range first[1:] {
+		if c.x < 16 || c.x > 2000-16 || c.y < 16 || c.y > 1000-16 {
+			t.Errorf("chest at %v, %v is not inside the map", c.x, c.y)
+		}
+	}
+
+	g.chests[3].collected = true
+	saved := g.collectedChests()
+	g.placeChests(saved)
+	for i, c := range g.chests {
+		if c.x != first[i].x || c.y != first[i].y {
+			t.Errorf("chest %d moved from %v, %v to %v, %v", i, first[i].x, first[i].y, c.x, c.y)
+		}
+		if c.collected != (i == 3) {
+			t.Errorf("chest %d collected = %v after reloading", i, c.collected)
+		}
+	}
+}
+
+func TestUpdateChests(t *testing.T) {
+	g := newTestGame(2000, 1000)
+	keys := &fakeKeys{}
+	g.keys = keys
+	g.cfg.Chests.Positions = []Label{{X: 500, Y: 500}, {X: 900, Y: 500}}
+	g.cfg.Chests.AutoCollect = false
+	g.placeChests(nil)
+	g.px, g.py = 490, 490
+
+	keys.hold()
+	g.updateChests()
+	if n, _ := chestCount(g.chests); n != 0 {
+		t.Fatal("standing on a chest opened it without Space")
+	}
+	keys.hold(ebiten.KeySpace)
+	g.updateChests()
+	if !g.chests[0].collected || g.chests[1].collected {
+		t.Errorf("Space opened %v, want only the chest underfoot", g.chests)
+	}
+	if got := g.chestText(); got != "Chests 1/2" {
+		t.Errorf("chestText = %q, want Chests 1/2", got)
+	}
+
+	g.cfg.Chests.AutoCollect = true
+	g.px = 890
+	keys.hold()
+	g.updateChests()
+	if !g.chests[1].collected {
+		t.Error("walking onto a chest with autoCollect didn't open it")
+	}
+	if got := g.chestText(); got != "Chests 2/2" {
+		t.Errorf("chestText = %q, want Chests 2/2", got)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"log/slog"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// collisionMask marks map areas the player can't walk into. The mask image
+// may be smaller than the map; it is stretched to cover it.
+type collisionMask struct {
+	w, h    int
+	blocked []bool
+	// map pixels per mask pixel
+	scaleX, scaleY float64
+	// red tint over blocked pixels, built on first use
+	overlayImg *ebiten.Image
+}
+
+func loadCollisionMask(path string, mapW, mapH int) (*collisionMask, error) {
+	img, err := decodeImage(path)
+	if err != nil {
+		return nil, err
+	}
+	return newCollisionMask(img, mapW, mapH), nil
+}
+
+func newCollisionMask(img image.Image, mapW, mapH int) *collisionMask {
+	b := img.Bounds()
+	m := &collisionMask{
+		w:       b.Dx(),
+		h:       b.Dy(),
+		blocked: make([]bool, b.Dx()*b.Dy()),
+		scaleX:  float64(mapW) / float64(b.Dx()),
+		scaleY:  float64(mapH) / float64(b.Dy()),
+	}
+	for y := 0; y < m.h; y++ {
+		for x := 0; x < m.w; x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			lum := (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000
+			m.blocked[y*m.w+x] = c.A >= 128 && lum < 128
+		}
+	}
+	return m
+}
+
+// blockedAt reports whether the world position (x, y) is blocked.
+func (m *collisionMask) blockedAt(x, y float64) bool {
+	mx := int(x / m.scaleX)
+	my := int(y / m.scaleY)
+	if mx < 0 || my < 0 || mx >= m.w || my >= m.h {
+		return false
+	}
+	return m.blocked[my*m.w+mx]
+}
+
+// rectBlocked samples the corners and center of the world rectangle.
+func (m *collisionMask) rectBlocked(x, y, w, h float64) bool {
+	return m.blockedAt(x, y) || m.blockedAt(x+w-1, y) ||
+		m.blockedAt(x, y+h-1) || m.blockedAt(x+w-1, y+h-1) ||
+		m.blockedAt(x+w/2, y+h/2)
+}
+
+// overlay returns a mask-sized image with blocked pixels tinted red.
+func (m *collisionMask) overlay() *ebiten.Image {
+	if m.overlayImg == nil {
+		pix := make([]byte, 4*m.w*m.h)
+		for i, b := range m.blocked {
+			if b {
+				// premultiplied red at ~40% opacity
+				pix[4*i] = 100
+				pix[4*i+3] = 100
+			}
+		}
+		m.overlayImg = ebiten.NewImage(m.w, m.h)
+		m.overlayImg.WritePixels(pix)
+	}
+	return m.overlayImg
+}
+
+// blocked reports whether the player box at (x, y) overlaps the mask.
+func (g *Game) blocked(x, y float64) bool {
+	if g.collision == nil {
+		return false
+	}
+	return g.collision.rectBlocked(x, y, float64(g.playerW), float64(g.playerH))
+}
+
+// nearestFree searches outward from the player box at (x, y), one ring of
+// mask pixels at a time, for the closest position inside the world where
+// it isn't blocked. It gives up beyond radius world pixels.
+//
+// The rings are square, so a free corner of one ring can be farther away
+// than a free edge of the next; the search goes on until no later ring
+// can beat the best spot found.
+func (g *Game) nearestFree(x, y, radius float64) (fx, fy float64, ok bool) {
+	if !g.blocked(x, y) {
+		return x, y, true
+	}
+	step := max(g.collision.scaleX, g.collision.scaleY, 1)
+	best := math.Inf(1)
+	for k := 1; float64(k)*step <= min(radius, best); k++ {
+		try := func(i, j int) {
+			cx, cy := g.clampToMap(x+float64(i)*step, y+float64(j)*step)
+			if d := math.Hypot(cx-x, cy-y); d < best && !g.blocked(cx, cy) {
+				fx, fy, best = cx, cy, d
+			}
+		}
+		for i := -k; i <= k; i++ {
+			try(i, -k)
+			try(i, k)
+			try(-k, i)
+			try(k, i)
+		}
+	}
+	if math.IsInf(best, 1) {
+		return x, y, false
+	}
+	return fx, fy, true
+}
+
+// unstick moves the player off blocked ground to the nearest walkable spot
+// within collision.unstickRadius, for spawns and teleports that land in a
+// wall.
+func (g *Game) unstick() {
+	radius := g.cfg.Collision.UnstickRadius
+	if g.collision == nil || radius <= 0 || !g.blocked(g.px, g.py) {
+		return
+	}
+	x, y, ok := g.nearestFree(g.px, g.py, radius)
+	if !ok {
+		slog.Warn("no walkable spot near the player", "x", g.px, "y", g.py, "radius", radius)
+		return
+	}
+	g.px, g.py = x, y
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+// maskGame is a 100x100 map that is all wall apart from the free mask
+// pixels, with a 1x1 player so a position is free when its pixel is.
+func maskGame(free ...image.Point) *Game {
+	g := newTestGame(100, 100)
+	g.playerW, g.playerH = 1, 1
+	m := &collisionMask{w: 100, h: 100, blocked: make([]bool, 100*100), scaleX: 1, scaleY: 1}
+	for i := range m.blocked {
+		m.blocked[i] = true
+	}
+	for _, p := range free {
+		m.blocked[p.Y*m.w+p.X] = false
+	}
+	g.collision = m
+	return g
+}
+
+func TestNearestFree(t *testing.T) {
+	tests := []struct {
+		name   string
+		free   []image.Point
+		radius float64
+		want   image.Point
+		ok     bool
+	}{
+		{"already free", []image.Point{{50, 50}}, 10, image.Pt(50, 50), true},
+		{"next pixel", []image.Point{{51, 50}, {60, 50}}, 10, image.Pt(51, 50), true},
+		{"edge of a later ring beats a corner", []image.Point{{55, 55}, {56, 50}}, 10, image.Pt(56, 50), true},
+		{"corner beats a farther edge", []image.Point{{53, 53}, {56, 50}}, 10, image.Pt(53, 53), true},
+		{"later ring out of radius", []image.Point{{55, 55}, {56, 50}}, 5.5, image.Pt(55, 55), true},
+		{"nothing in radius", []image.Point{{70, 50}}, 10, image.Pt(50, 50), false},
+		{"nearer of two far spots", []image.Point{{99, 50}, {40, 50}}, 60, image.Pt(40, 50), true},
+	}
+	for _, tt := range tests {
+		g := maskGame(tt.free...)
+		x, y, ok := g.nearestFree(50, 50, tt.radius)
+		if got := image.Pt(int(x), int(y)); got != tt.want || ok != tt.ok {
+			t.Errorf("%s: nearestFree = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// Whatever the free pixels, the answer is the Euclidean nearest of them.
+func TestNearestFreeIsNearest(t *testing.T) {
+	free := []image.Point{{58, 51}, {44, 56}, {57, 57}, {50, 42}, {43, 45}}
+	for n := 1; n <= len(free); n++ {
+		g := maskGame(free[:n]...)
+		x, y, ok := g.nearestFree(50, 50, 20)
+		want, bestD := free[0], 1<<30
+		for _, p := range free[:n] {
+			if d := (p.X-50)*(p.X-50) + (p.Y-50)*(p.Y-50); d < bestD {
+				want, bestD = p, d
+			}
+		}
+		if got := image.Pt(int(x), int(y)); !ok || got != want {
+			t.Errorf("free %v: nearestFree = %v, %v, want %v", free[:n], got, ok, want)
+		}
+	}
+}
+
+func TestUnstick(t *testing.T) {
+	g := maskGame(image.Pt(53, 50))
+	g.cfg.Collision.UnstickRadius = 10
+	g.px, g.py = 50, 50
+	g.unstick()
+	if g.px != 53 || g.py != 50 {
+		t.Errorf("unstick moved the player to %v,%v, want 53,50", g.px, g.py)
+	}
+	g = maskGame(image.Pt(80, 50))
+	g.cfg.Collision.UnstickRadius = 10
+	g.px, g.py = 50, 50
+	g.unstick()
+	if g.px != 50 || g.py != 50 {
+		t.Errorf("unstick with nothing free moved the player to %v,%v", g.px, g.py)
+	}
+}
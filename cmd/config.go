@@ -0,0 +1,877 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Config holds the user settings read from a JSON file. Fields missing from
+// the file keep their defaults.
+type Config struct {
+	// enables debug-only keys and overlays
+	Debug     bool            `json:"debug"`
+	Map       MapConfig       `json:"map"`
+	Splash    SplashConfig    `json:"splash"`
+	Collision CollisionConfig `json:"collision"`
+	Movement  MovementConfig  `json:"movement"`
+	Shadow    ShadowConfig    `json:"shadow"`
+	Audio     AudioConfig     `json:"audio"`
+	Grid      GridConfig      `json:"grid"`
+	Zoom      ZoomConfig      `json:"zoom"`
+	Camera    CameraConfig    `json:"camera"`
+	Perf      PerfConfig      `json:"perf"`
+	TwoPlayer TwoPlayerConfig `json:"twoPlayer"`
+	Save      SaveConfig      `json:"save"`
+	DayNight  DayNightConfig  `json:"dayNight"`
+	Minimap   MinimapConfig   `json:"minimap"`
+	Locate    LocateConfig    `json:"locate"`
+	Overview  OverviewConfig  `json:"overview"`
+	Frame     FrameConfig     `json:"frame"`
+	Cursor    CursorConfig    `json:"cursor"`
+	Photo     PhotoConfig     `json:"photo"`
+	Controls  ControlsConfig  `json:"controls"`
+	Trail     TrailConfig     `json:"trail"`
+	Snap      SnapConfig      `json:"snap"`
+	Travel    TravelConfig    `json:"travel"`
+	Chests    ChestsConfig    `json:"chests"`
+	Explore   ExploreConfig   `json:"explore"`
+	Rumble    RumbleConfig    `json:"rumble"`
+	Reticle   ReticleConfig   `json:"reticle"`
+	Ping      PingConfig      `json:"ping"`
+	Magnifier MagnifierConfig `json:"magnifier"`
+	Ambient   AmbientConfig   `json:"ambient"`
+	Legend    LegendConfig    `json:"legend"`
+	HUD       HUDConfig       `json:"hud"`
+	Font      FontConfig      `json:"font"`
+	Notes     NotesConfig     `json:"notes"`
+	Speedrun  SpeedrunConfig  `json:"speedrun"`
+	// named world locations
+	Labels []Label `json:"labels"`
+	// label name or "x,y" the player starts at; empty means the first tile
+	Spawn string `json:"spawn"`
+	// CSV file of x,y,label,type rows adding markers and labels (also
+	// -import)
+	Import string `json:"import"`
+	// zooming to fit every marker with Z
+	MarkerFrame MarkerFrameConfig `json:"markerFrame"`
+	// zooming to a rectangle dragged out with Shift and the left button
+	ZoomSelect ZoomSelectConfig `json:"zoomSelect"`
+	// areas that react when the player walks into them
+	Triggers []TriggerZone `json:"triggers"`
+}
+
+type MapConfig struct {
+	// optional sidecar describing the map layout and places
+	Meta string `json:"meta"`
+	// glob matching the map part images, ordered by their trailing number
+	Parts string `json:"parts"`
+	// parts per row; 0 puts all parts in a single row
+	Columns int `json:"columns"`
+	// screen pixels each part is stretched by to hide seams between parts
+	SeamOverlap float64 `json:"seamOverlap"`
+	// seconds to crossfade when the background is swapped; 0 is instant
+	SwapFade float64 `json:"swapFade"`
+	// "scale" draws the map once; "tile" repeats it across the world
+	Mode string `json:"mode"`
+	// world size in tile mode; 0 repeats the map 8 times on each axis
+	WorldWidth  int `json:"worldWidth"`
+	WorldHeight int `json:"worldHeight"`
+	// join opposite map edges so the player and camera wrap around
+	Wrap bool `json:"wrap"`
+	// area of the map, in map pixels, the player and camera are kept in;
+	// the rest is scenery. A zero size uses the whole map
+	Bounds WorldRect `json:"bounds"`
+	// "nearest" keeps scaled pixels crisp, "linear" smooths them; F7
+	// switches at runtime
+	Filter string `json:"filter"`
+	// most screen pixels per map pixel; beyond it the map is letterboxed
+	// instead of stretched further. 0 is unlimited
+	MaxUpscale float64 `json:"maxUpscale"`
+	// map pixels per world unit, so readouts can be given in leagues or
+	// meters; 0 shows raw pixels
+	PixelsPerUnit float64 `json:"pixelsPerUnit"`
+	// name of the world unit shown after readouts
+	Unit string `json:"unit"`
+	// what fills the bars around the map when it doesn't cover the view
+	Backdrop BackdropConfig `json:"backdrop"`
+	// annotation image drawn over the map
+	Overlay OverlayConfig `json:"overlay"`
+	// lower resolution copies of the map used when zoomed out
+	LOD LODConfig `json:"lod"`
+	// load parts only as they come near the view
+	Lazy LazyConfig `json:"lazy"`
+}
+
+type OverlayConfig struct {
+	// image aligned with the map's top-left corner, one pixel per world
+	// pixel; none when empty
+	Image string `json:"image"`
+	// opacity from 0 to 1
+	Opacity float64 `json:"opacity"`
+	// shown at startup; O toggles it
+	Shown bool `json:"shown"`
+}
+
+type WorldRect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type BackdropConfig struct {
+	// "solid" fills with Color, "gradient" blends from Top down to Bottom
+	// and "texture" tiles the Texture image
+	Mode string `json:"mode"`
+	// colors as #rrggbb or #rrggbbaa
+	Color   string `json:"color"`
+	Top     string `json:"top"`
+	Bottom  string `json:"bottom"`
+	Texture string `json:"texture"`
+}
+
+type LazyConfig struct {
+	Enabled bool `json:"enabled"`
+	// how many parts beyond the view are loaded ahead of time
+	Prefetch int `json:"prefetch"`
+}
+
+type LODConfig struct {
+	Enabled bool `json:"enabled"`
+	// on-screen scales below which each successive half-size level is used
+	Thresholds []float64 `json:"thresholds"`
+	// fraction a threshold is widened by before switching back, to avoid
+	// flicker around it
+	Hysteresis float64 `json:"hysteresis"`
+	// seconds to crossfade between levels; 0 switches instantly
+	Fade float64 `json:"fade"`
+	// "box" halves each level sharply; "gaussian" blurs as it halves for a
+	// smoother look at the cost of a slower startup
+	Filter string `json:"filter"`
+}
+
+type SplashConfig struct {
+	// path to the logo image; no splash is shown when empty
+	Logo string `json:"logo"`
+	// seconds the splash stays up, including the fade out
+	Duration float64 `json:"duration"`
+	// seconds spent fading into the map at the end of the splash
+	Fade float64 `json:"fade"`
+	// background color as #rrggbb
+	Background string `json:"background"`
+	// start the music while the splash is shown instead of after it
+	MusicDuringSplash bool `json:"musicDuringSplash"`
+}
+
+type CollisionConfig struct {
+	// image whose dark opaque pixels block the player, stretched over the map
+	Mask string `json:"mask"`
+	// world pixels searched around a spawn or teleport that lands on
+	// blocked ground for a walkable spot to move the player to; 0 leaves
+	// them stuck
+	UnstickRadius float64 `json:"unstickRadius"`
+}
+
+type MovementConfig struct {
+	// decelerate near the map edges instead of stopping hard
+	SoftEdges bool `json:"softEdges"`
+	// distance from an edge, in map pixels, where slowing down starts
+	EdgeMargin float64 `json:"edgeMargin"`
+	// 8 allows diagonal movement at the same speed as straight; 4 moves
+	// along one axis at a time, the one pressed last
+	Directions int `json:"directions"`
+	// fast travel walks the player there, along the map's roads if it has
+	// any, instead of teleporting
+	FollowRoads bool `json:"followRoads"`
+	// mirror the sprite, drawn facing right, while walking left
+	FlipSprite bool `json:"flipSprite"`
+	// turn the sprite to face the way the player walks, for a top-down
+	// look; replaces FlipSprite
+	Rotate bool `json:"rotate"`
+	// degrees per second the sprite turns at; 0 turns at once
+	TurnSpeed float64 `json:"turnSpeed"`
+}
+
+type ShadowConfig struct {
+	// how much the shadow shrinks at the top of each step while walking,
+	// as a fraction of its size; 0 keeps it still
+	BobAmplitude float64 `json:"bobAmplitude"`
+	// steps per second
+	BobSpeed float64 `json:"bobSpeed"`
+	// fraction of the shadow's radius, from its rim inwards, over which it
+	// fades out; 0 draws it with a hard edge
+	Feather float64 `json:"feather"`
+}
+
+type AudioConfig struct {
+	// audio context rate in Hz; 0 uses the music file's own rate
+	SampleRate int `json:"sampleRate"`
+	// music volume from 0 to 1
+	Volume float64 `json:"volume"`
+	// seconds to crossfade when the music changes, e.g. between rooms
+	Crossfade float64 `json:"crossfade"`
+	// lower the music briefly when an event fires
+	Duck DuckConfig `json:"duck"`
+	// lower the music while zoomed out, as if stepping back from the map
+	ZoomFade ZoomFadeConfig `json:"zoomFade"`
+	// fade the music out while the pause menu is open
+	Pause PauseFadeConfig `json:"pause"`
+}
+
+type PauseFadeConfig struct {
+	Enabled bool `json:"enabled"`
+	// seconds the fade out, and back in, takes; 0 cuts straight away
+	Fade float64 `json:"fade"`
+}
+
+type ZoomFadeConfig struct {
+	// fraction of the volume taken away at the widest zoom and in the
+	// overview; 0 leaves the music alone
+	Amount float64 `json:"amount"`
+	// zoom from which the music plays at full volume
+	FullAt float64 `json:"fullAt"`
+	// seconds a full change of gain takes, keeping zooming from making the
+	// volume jump
+	Ramp float64 `json:"ramp"`
+}
+
+type DuckConfig struct {
+	// fraction of the volume taken away while ducked; 0 disables ducking
+	Amount float64 `json:"amount"`
+	// seconds the music stays ducked before ramping back
+	Duration float64 `json:"duration"`
+}
+
+type GridConfig struct {
+	// show the cell readout at startup; G toggles it
+	Show bool `json:"show"`
+	// world position of the top-left corner of cell A1
+	OriginX float64 `json:"originX"`
+	OriginY float64 `json:"originY"`
+	// cell size in world pixels; 0 uses the rendering tile size
+	CellW float64 `json:"cellW"`
+	CellH float64 `json:"cellH"`
+}
+
+type ZoomConfig struct {
+	// zoom is multiplied or divided by this per mouse wheel tick
+	Factor float64 `json:"factor"`
+	// add Step per tick instead of scaling by Factor
+	Linear bool `json:"linear"`
+	// zoom change per mouse wheel tick when Linear is set
+	Step float64 `json:"step"`
+	// largest zoom in; zooming out stops once the whole map fits
+	Max float64 `json:"max"`
+}
+
+type CameraConfig struct {
+	// free-cam pan speed in screen pixels per second
+	PanSpeed float64 `json:"panSpeed"`
+	// extra speed per second of holding a pan key, as a fraction of PanSpeed
+	PanAccel float64 `json:"panAccel"`
+	// cap on the accelerated speed as a multiple of PanSpeed
+	PanMaxFactor float64 `json:"panMaxFactor"`
+	// "linear" or "ease-in" ramp from PanSpeed to the cap
+	PanCurve string `json:"panCurve"`
+	// speed multiplier while Shift is held
+	PanFastFactor float64 `json:"panFastFactor"`
+	// ease camera moves (following, fast travel, mode switches) instead of
+	// snapping, with SmoothTime as the easing time constant in seconds
+	Smooth     bool    `json:"smooth"`
+	SmoothTime float64 `json:"smoothTime"`
+	// world pixels beyond which a camera move snaps instead of easing,
+	// so teleports don't pan across the whole map; 0 always eases
+	SnapDistance float64 `json:"snapDistance"`
+	// screen pixels the camera's target has to shift by before a follow
+	// or eased move changes it, to hide sub-pixel scrolling; 0 follows
+	// every change
+	MinMove float64 `json:"minMove"`
+	// fraction of the view the camera may show past a map edge, so the
+	// player near it drifts less far off-center; 0 holds the view inside
+	// the map and 0.5 keeps the player centered everywhere
+	EdgePeek float64 `json:"edgePeek"`
+	// snap the camera back onto the player when the window is resized
+	CenterOnResize bool `json:"centerOnResize"`
+	// round the view to whole screen pixels when the map is drawn at an
+	// integer scale, for crisp pixel art
+	PixelSnap bool `json:"pixelSnap"`
+	// pan the free camera when the cursor is near a screen edge
+	EdgeScroll EdgeScrollConfig `json:"edgeScroll"`
+	// let the camera carry on a little after whatever moved it stops
+	Inertia CameraInertiaConfig `json:"inertia"`
+	// drag the view around with the mouse, even while following
+	Peek CameraPeekConfig `json:"peek"`
+}
+
+type CameraPeekConfig struct {
+	Enabled bool `json:"enabled"`
+	// "middle" for the middle mouse button, or "ctrl-left" for Ctrl and
+	// the left button
+	Button string `json:"button"`
+	// seconds a following camera may stay dragged away before it returns
+	// to the player anyway; 0 waits for the button to be released
+	Timeout float64 `json:"timeout"`
+	// time constant, in seconds, of the ease back onto the player
+	ReturnTime float64 `json:"returnTime"`
+}
+
+type CameraInertiaConfig struct {
+	Enabled bool `json:"enabled"`
+	// rate per second at which the camera's momentum dies away; higher
+	// stops it sooner
+	Friction float64 `json:"friction"`
+	// most screen pixels per second the camera keeps moving at; 0 is
+	// uncapped
+	MaxSpeed float64 `json:"maxSpeed"`
+}
+
+type EdgeScrollConfig struct {
+	Enabled bool `json:"enabled"`
+	// distance from the edge, in screen pixels, that starts scrolling
+	Margin float64 `json:"margin"`
+	// screen pixels per second
+	Speed float64 `json:"speed"`
+}
+
+type PerfConfig struct {
+	// frames slower than this many milliseconds are drawn red in the graph
+	FrameThresholdMs float64 `json:"frameThresholdMs"`
+	// seconds the window size has to stay put before screen-sized images
+	// are rebuilt to fit it exactly
+	ResizeSettle float64 `json:"resizeSettle"`
+	// turn costly effects off while the frame rate is low
+	LowFPS LowFPSConfig `json:"lowFPS"`
+	// updates per second; 0 keeps the default of 60
+	TPS int `json:"tps"`
+	// draw the player and camera blended between the last two updates,
+	// for smooth motion when TPS is below the frame rate
+	Interpolate bool `json:"interpolate"`
+}
+
+type LowFPSConfig struct {
+	// frames per second below which effects are dropped; 0 never drops
+	// them
+	Threshold float64 `json:"threshold"`
+	// how far above the threshold the frame rate has to recover before
+	// effects come back
+	Recover float64 `json:"recover"`
+	// effects that may be dropped: "shadow", "night", "room tint",
+	// "trail" and "pings"
+	Effects []string `json:"effects"`
+}
+
+type TwoPlayerConfig struct {
+	// add a second player on the arrow keys (also -two-player)
+	Enabled bool `json:"enabled"`
+	// sprite for player two
+	Sprite string `json:"sprite"`
+	// world pixels kept around both players when framing them
+	Padding float64 `json:"padding"`
+}
+
+type DayNightConfig struct {
+	Enabled bool `json:"enabled"`
+	// seconds for a full day
+	DayLength float64 `json:"dayLength"`
+	// opacity of the overlay at midnight
+	NightAlpha float64 `json:"nightAlpha"`
+	// light around the player at night
+	Light LightConfig `json:"light"`
+	Clock ClockConfig `json:"clock"`
+}
+
+type ClockConfig struct {
+	// show the in-game time at startup; T toggles it
+	Show bool `json:"show"`
+	// top-left, top-right, bottom-left or bottom-right
+	Corner string `json:"corner"`
+}
+
+type LightConfig struct {
+	// radius in world pixels; 0 turns the light off
+	Radius float64 `json:"radius"`
+	// how much of the darkness is lifted at the center, from 0 to 1
+	Intensity float64 `json:"intensity"`
+}
+
+type MinimapConfig struct {
+	// show the minimap at startup; Tab toggles it
+	Show bool `json:"show"`
+	// top-left, top-right, bottom-left or bottom-right
+	Corner string `json:"corner"`
+	// longest side as a fraction of the smaller screen dimension
+	Size float64 `json:"size"`
+	// opacity from 0 to 1 while the cursor is near
+	Opacity float64 `json:"opacity"`
+	Border  bool    `json:"border"`
+	// fade to FadedOpacity while the cursor is more than this many screen
+	// pixels away; 0 never fades
+	FadeDistance float64 `json:"fadeDistance"`
+	FadedOpacity float64 `json:"fadedOpacity"`
+}
+
+type LocateConfig struct {
+	// ring color as #rrggbb or #rrggbbaa
+	Color string `json:"color"`
+	// final ring radius in screen pixels
+	Radius float64 `json:"radius"`
+	// seconds the animation lasts
+	Duration float64 `json:"duration"`
+	Rings    int     `json:"rings"`
+}
+
+type OverviewConfig struct {
+	// lines through the player across the screen in the overview
+	Crosshair CrosshairConfig `json:"crosshair"`
+}
+
+type CrosshairConfig struct {
+	Enabled bool `json:"enabled"`
+	// line color as #rrggbb or #rrggbbaa
+	Color string `json:"color"`
+	// line width in screen pixels
+	Thickness float64 `json:"thickness"`
+	// length of the dashes and the gaps between them in screen pixels; 0
+	// draws solid lines
+	Dash float64 `json:"dash"`
+}
+
+type FrameConfig struct {
+	// 9-slice border image drawn around the screen; none when empty
+	Image string `json:"image"`
+	// size of the corners in the image, in pixels; 0 uses a third
+	Slice int `json:"slice"`
+	// border thickness on screen in pixels; 0 uses Slice
+	Width int `json:"width"`
+	// lay the map out inside the border instead of under it
+	Inset bool `json:"inset"`
+}
+
+type CursorConfig struct {
+	// hide the mouse cursor while walking around; it still shows in
+	// menus, free-cam and overview, and whenever the mouse moves
+	HideInGameplay bool `json:"hideInGameplay"`
+	// seconds the cursor stays visible after the mouse last moved
+	ShowAfterMove float64 `json:"showAfterMove"`
+}
+
+type HUDConfig struct {
+	// seconds without input before the readouts, clock and toasts fade
+	// out; 0 keeps them shown
+	HideAfter float64 `json:"hideAfter"`
+	// seconds the fade out takes
+	Fade float64 `json:"fade"`
+}
+
+type FontConfig struct {
+	// TTF or OTF font for the HUD, menus, labels and toasts; the built-in
+	// debug font when empty
+	Path string `json:"path"`
+	// size in pixels for a 720 pixel tall window
+	Size float64 `json:"size"`
+	// grow and shrink the text with the window height
+	Scale bool `json:"scale"`
+}
+
+type LegendConfig struct {
+	// "top-left", "top-right", "bottom-left" or "bottom-right"
+	Corner string `json:"corner"`
+	// opacity of the panel behind the entries
+	Opacity float64 `json:"opacity"`
+}
+
+type AmbientConfig struct {
+	Sources []AmbientSource `json:"sources"`
+	// pan each source toward its side of the listener
+	Pan bool `json:"pan"`
+	// strongest pan, from 0 (centered) to 1 (one ear only)
+	MaxPan float64 `json:"maxPan"`
+}
+
+type MagnifierConfig struct {
+	// how many times closer than the main view the loupe shows the map
+	Magnification float64 `json:"magnification"`
+	// loupe diameter in screen pixels
+	Size int `json:"size"`
+}
+
+type PingConfig struct {
+	// ring color as #rrggbb or #rrggbbaa
+	Color string `json:"color"`
+	// radius the rings grow to, in screen pixels
+	Radius float64 `json:"radius"`
+	// seconds a ping stays; 0 turns pings off
+	Duration float64 `json:"duration"`
+	// optional MP3 played with each ping
+	Sound string `json:"sound"`
+}
+
+type TrailConfig struct {
+	// seconds between auto-markers dropped at the player; 0 turns the
+	// trail off
+	Interval float64 `json:"interval"`
+	// most auto-markers kept, dropping the oldest; 0 keeps them all
+	Max int `json:"max"`
+}
+
+type ReticleConfig struct {
+	// mark the world point under the cursor that cursor actions act on
+	Enabled bool `json:"enabled"`
+	// shown at startup; B toggles it
+	Shown bool `json:"shown"`
+	// "ring" or "cross"
+	Style string `json:"style"`
+	// diameter in screen pixels
+	Size float64 `json:"size"`
+	// #rrggbb or #rrggbbaa
+	Color string `json:"color"`
+	// line width in screen pixels
+	Thickness float64 `json:"thickness"`
+}
+
+type RumbleConfig struct {
+	// vibrate gamepads on the events below
+	Enabled bool `json:"enabled"`
+	// motor strength from 0 to 1
+	Intensity float64 `json:"intensity"`
+	// seconds each rumble lasts
+	Duration float64 `json:"duration"`
+	// "shake" (scaled by how hard), "trigger" and "chest"
+	Events []string `json:"events"`
+}
+
+type ExploreConfig struct {
+	// percentages of the map explored that are congratulated with a toast
+	Milestones []float64 `json:"milestones"`
+}
+
+type ChestsConfig struct {
+	// chests scattered at random walkable spots, on top of Positions
+	Count int `json:"count"`
+	// seed for the random spots, so they stay put between runs
+	Seed int64 `json:"seed"`
+	// chests at fixed world positions; names are ignored
+	Positions []Label `json:"positions"`
+	// open a chest by walking over it; otherwise Space opens the one the
+	// player stands on
+	AutoCollect bool `json:"autoCollect"`
+	// sound played when a chest is opened; none when empty
+	Sound string `json:"sound"`
+}
+
+type TravelConfig struct {
+	// seconds of the fade to black and back when fast traveling, with the
+	// player moved at its midpoint; 0 travels at once
+	Fade float64 `json:"fade"`
+	// seconds after a fast travel before another is allowed
+	Cooldown float64 `json:"cooldown"`
+}
+
+type ZoomSelectConfig struct {
+	Enabled bool `json:"enabled"`
+	// seconds the glide to the selection takes; 0 jumps there
+	Duration float64 `json:"duration"`
+	// smallest selection side, in screen pixels, that is zoomed to
+	MinSize float64 `json:"minSize"`
+}
+
+type SpeedrunConfig struct {
+	// name of the trigger zone a run ends in; empty turns the timer off
+	Goal string `json:"goal"`
+}
+
+type NotesConfig struct {
+	// Ctrl+right click adds a note and a left click on one opens it
+	Enabled bool `json:"enabled"`
+	// lines of a note shown when hovering over its icon
+	PreviewLines int `json:"previewLines"`
+}
+
+type MarkerFrameConfig struct {
+	// world pixels kept around the markers when framing them
+	Padding float64 `json:"padding"`
+}
+
+type SnapConfig struct {
+	// world pixels within which a marker placed with Alt held lines up
+	// with an existing one; 0 turns marker snapping off
+	Radius float64 `json:"radius"`
+	// grid spacing in world pixels the other axes snap to; 0 is no grid
+	Grid float64 `json:"grid"`
+}
+
+type ControlsConfig struct {
+	// movement key preset: "wasd", "esdf", "arrows" or "vim"
+	Preset string `json:"preset"`
+}
+
+type PhotoConfig struct {
+	// output width of photo exports in pixels; the height follows the
+	// area's aspect ratio
+	Width int `json:"width"`
+	// draw label names and the players into photos
+	Labels bool `json:"labels"`
+	Player bool `json:"player"`
+}
+
+type SaveConfig struct {
+	// seconds between autosaves; 0 only saves on exit
+	AutosaveInterval float64 `json:"autosaveInterval"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Map: MapConfig{
+			Meta:        "assets/map.json",
+			Parts:       "assets/map-part*.jpg",
+			SeamOverlap: 1,
+			SwapFade:    0.5,
+			Mode:        "scale",
+			Filter:      "nearest",
+			Backdrop: BackdropConfig{
+				Mode:   "solid",
+				Color:  "#000000",
+				Top:    "#1c2440",
+				Bottom: "#05070d",
+			},
+			Overlay: OverlayConfig{
+				Opacity: 1,
+				Shown:   true,
+			},
+			Lazy: LazyConfig{
+				Prefetch: 1,
+			},
+			LOD: LODConfig{
+				Thresholds: []float64{0.5, 0.25},
+				Hysteresis: 0.15,
+				Fade:       0.25,
+				Filter:     "box",
+			},
+		},
+		Splash: SplashConfig{
+			Duration:   2.5,
+			Fade:       0.6,
+			Background: "#000000",
+		},
+		Collision: CollisionConfig{
+			UnstickRadius: 256,
+		},
+		Movement: MovementConfig{
+			EdgeMargin: 48,
+			Directions: 8,
+			FlipSprite: true,
+			TurnSpeed:  540,
+		},
+		Shadow: ShadowConfig{
+			BobAmplitude: 0.1,
+			BobSpeed:     3,
+		},
+		Zoom: ZoomConfig{
+			Factor: 1.1,
+			Step:   0.1,
+			Max:    8,
+		},
+		Camera: CameraConfig{
+			PanSpeed:       600,
+			PanAccel:       1.5,
+			PanMaxFactor:   4,
+			PanCurve:       "linear",
+			PanFastFactor:  3,
+			SmoothTime:     0.12,
+			SnapDistance:   1500,
+			CenterOnResize: true,
+			EdgeScroll: EdgeScrollConfig{
+				Margin: 24,
+				Speed:  800,
+			},
+			Inertia: CameraInertiaConfig{
+				Friction: 6,
+				MaxSpeed: 2400,
+			},
+			Peek: CameraPeekConfig{
+				Enabled:    true,
+				Button:     "middle",
+				ReturnTime: 0.15,
+			},
+		},
+		Perf: PerfConfig{
+			FrameThresholdMs: 20,
+			ResizeSettle:     0.15,
+			LowFPS: LowFPSConfig{
+				Recover: 10,
+				Effects: []string{"shadow", "night", "room tint", "trail", "pings"},
+			},
+		},
+		TwoPlayer: TwoPlayerConfig{
+			Sprite:  "assets/link.gif",
+			Padding: 64,
+		},
+		DayNight: DayNightConfig{
+			DayLength:  240,
+			NightAlpha: 0.75,
+			Light: LightConfig{
+				Radius:    160,
+				Intensity: 0.9,
+			},
+			Clock: ClockConfig{
+				Show:   true,
+				Corner: "bottom-right",
+			},
+		},
+		Minimap: MinimapConfig{
+			Show:         true,
+			Corner:       "top-right",
+			Size:         0.25,
+			Opacity:      0.9,
+			Border:       true,
+			FadeDistance: 150,
+			FadedOpacity: 0.35,
+		},
+		Locate: LocateConfig{
+			Color:    "#ffffff",
+			Radius:   60,
+			Duration: 0.7,
+			Rings:    2,
+		},
+		Overview: OverviewConfig{
+			Crosshair: CrosshairConfig{
+				Color:     "#ff4040c0",
+				Thickness: 1,
+			},
+		},
+		Save: SaveConfig{
+			AutosaveInterval: 60,
+		},
+		Cursor: CursorConfig{
+			ShowAfterMove: 2,
+		},
+		HUD: HUDConfig{
+			Fade: 0.5,
+		},
+		Font: FontConfig{
+			Size:  14,
+			Scale: true,
+		},
+		Legend: LegendConfig{
+			Corner:  "bottom-right",
+			Opacity: 0.7,
+		},
+		Ambient: AmbientConfig{
+			Pan:    true,
+			MaxPan: 0.8,
+		},
+		Magnifier: MagnifierConfig{
+			Magnification: 4,
+			Size:          180,
+		},
+		Ping: PingConfig{
+			Color:    "#ff4040",
+			Radius:   40,
+			Duration: 1.5,
+		},
+		Trail: TrailConfig{
+			Max: 2000,
+		},
+		Reticle: ReticleConfig{
+			Shown:     true,
+			Style:     "ring",
+			Size:      14,
+			Color:     "#ffffffc0",
+			Thickness: 1.5,
+		},
+		Rumble: RumbleConfig{
+			Intensity: 0.6,
+			Duration:  0.2,
+			Events:    []string{"shake", "trigger", "chest"},
+		},
+		Explore: ExploreConfig{
+			Milestones: []float64{25, 50, 75, 100},
+		},
+		Chests: ChestsConfig{
+			Seed:        1,
+			AutoCollect: true,
+		},
+		ZoomSelect: ZoomSelectConfig{
+			Enabled:  true,
+			Duration: 0.4,
+			MinSize:  8,
+		},
+		MarkerFrame: MarkerFrameConfig{
+			Padding: 64,
+		},
+		Notes: NotesConfig{
+			Enabled:      true,
+			PreviewLines: 3,
+		},
+		Snap: SnapConfig{
+			Radius: 16,
+		},
+		Controls: ControlsConfig{
+			Preset: "wasd",
+		},
+		Photo: PhotoConfig{
+			Width:  4096,
+			Labels: true,
+			Player: true,
+		},
+		Audio: AudioConfig{
+			SampleRate: defaultSampleRate,
+			Volume:     1,
+			Crossfade:  1.5,
+			Duck: DuckConfig{
+				Amount:   0.6,
+				Duration: 1.5,
+			},
+			ZoomFade: ZoomFadeConfig{
+				FullAt: 1,
+				Ramp:   0.5,
+			},
+			Pause: PauseFadeConfig{
+				Fade: 0.5,
+			},
+		},
+	}
+}
+
+// loadConfig reads path over the defaults. A missing file is not an error.
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parseHexColor parses #rrggbb or #rrggbbaa. The alpha is straight in the
+// string, but the result is premultiplied like every color.RGBA ebiten
+// draws with.
+func parseHexColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	var c color.NRGBA
+	switch len(s) {
+	case 6:
+		c.A = 0xff
+		if _, err := fmt.Sscanf(s, "%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+		}
+	case 8:
+		if _, err := fmt.Sscanf(s, "%02x%02x%02x%02x", &c.R, &c.G, &c.B, &c.A); err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+		}
+	default:
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	return color.RGBAModel.Convert(c).(color.RGBA), nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.RGBA
+		ok   bool
+	}{
+		{"#ff4040", color.RGBA{0xff, 0x40, 0x40, 0xff}, true},
+		{"ff4040", color.RGBA{0xff, 0x40, 0x40, 0xff}, true},
+		{"#ffffffff", color.RGBA{0xff, 0xff, 0xff, 0xff}, true},
+		// straight alpha in the string comes out premultiplied
+		{"#ffffffc0", color.RGBA{0xc0, 0xc0, 0xc0, 0xc0}, true},
+		{"#ffd08020", color.RGBA{0x20, 0x1a, 0x10, 0x20}, true},
+		{"#ff000000", color.RGBA{}, true},
+		{"#fff", color.RGBA{}, false},
+		{"#gg0000", color.RGBA{}, false},
+		{"", color.RGBA{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseHexColor(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseHexColor(%q) error = %v, want ok %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && got != tt.want {
+			t.Errorf("parseHexColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+		if got.R > got.A || got.G > got.A || got.B > got.A {
+			t.Errorf("parseHexColor(%q) = %v is not premultiplied", tt.in, got)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// KeyBindings are the keys player one walks with.
+type KeyBindings struct {
+	Up, Down, Left, Right ebiten.Key
+}
+
+// controlPreset is a named set of bindings.
+type controlPreset struct {
+	name     string
+	bindings KeyBindings
+}
+
+// controlPresets are the built-in schemes, in the order F6 cycles through
+// them.
+var controlPresets = []controlPreset{
+	{"wasd", KeyBindings{Up: ebiten.KeyW, Down: ebiten.KeyS, Left: ebiten.KeyA, Right: ebiten.KeyD}},
+	{"esdf", KeyBindings{Up: ebiten.KeyE, Down: ebiten.KeyD, Left: ebiten.KeyS, Right: ebiten.KeyF}},
+	{"arrows", KeyBindings{Up: ebiten.KeyArrowUp, Down: ebiten.KeyArrowDown, Left: ebiten.KeyArrowLeft, Right: ebiten.KeyArrowRight}},
+	{"vim", KeyBindings{Up: ebiten.KeyK, Down: ebiten.KeyJ, Left: ebiten.KeyH, Right: ebiten.KeyL}},
+}
+
+// fixedKeys are the keys bound to other actions, which movement bindings
+// should stay clear of.
+var fixedKeys = map[ebiten.Key]string{
+	ebiten.KeyF:            "free camera",
+	ebiten.KeyM:            "overview",
+	ebiten.KeyTab:          "minimap",
+	ebiten.KeyT:            "clock",
+	ebiten.KeyComma:        "time back",
+	ebiten.KeyPeriod:       "time forward",
+	ebiten.KeyL:            "locate",
+	ebiten.KeyV:            "fog",
+	ebiten.KeyU:            "explored readout",
+	ebiten.KeyX:            "explored export",
+	ebiten.KeyP:            "photo",
+	ebiten.KeyI:            "magnifier",
+	ebiten.KeyH:            "heatmap",
+	ebiten.KeyO:            "map overlay",
+	ebiten.KeyB:            "reticle",
+	ebiten.KeyG:            "grid readout",
+	ebiten.KeyC:            "copy coordinates",
+	ebiten.KeyN:            "nearest place",
+	ebiten.KeyQ:            "ping",
+	ebiten.KeyR:            "return to marker",
+	ebiten.KeyBracketLeft:  "previous marker",
+	ebiten.KeyBracketRight: "next marker",
+	ebiten.KeyZ:            "frame markers",
+	ebiten.KeyEscape:       "pause menu",
+	ebiten.KeyDelete:       "clear trail",
+	ebiten.KeySpace:        "open chest",
+	ebiten.KeyBackspace:    "reset speedrun",
+	ebiten.KeyF1:           "legend",
+	ebiten.KeyF2:           "frame graph",
+	ebiten.KeyF5:           "reload map",
+	ebiten.KeyF6:           "cycle controls",
+	ebiten.KeyF7:           "scaling filter",
+	ebiten.KeyF8:           "readout units",
+	ebiten.KeyF9:           "map part",
+	ebiten.KeyF10:          "pixel color",
+	ebiten.KeyF11:          "streaming stats",
+	ebiten.KeyF12:          "view rectangle",
+}
+
+// presetIndex finds a preset by name, ignoring case.
+func presetIndex(name string) (int, bool) {
+	for i, p := range controlPresets {
+		if strings.EqualFold(p.name, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// bindingConflicts lists every key that more than one action is bound to,
+// counting the fixed action keys and, in two-player mode, the arrow keys.
+func bindingConflicts(b KeyBindings, twoPlayer bool) []string {
+	used := map[ebiten.Key]string{}
+	for k, action := range fixedKeys {
+		used[k] = action
+	}
+	if twoPlayer {
+		used[ebiten.KeyArrowUp] = "player two"
+		used[ebiten.KeyArrowDown] = "player two"
+		used[ebiten.KeyArrowLeft] = "player two"
+		used[ebiten.KeyArrowRight] = "player two"
+	}
+	var conflicts []string
+	for _, m := range []struct {
+		action string
+		key    ebiten.Key
+	}{{"up", b.Up}, {"down", b.Down}, {"left", b.Left}, {"right", b.Right}} {
+		if other, ok := used[m.key]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s is bound to both %s and %s", m.key, other, m.action))
+			continue
+		}
+		used[m.key] = m.action
+	}
+	return conflicts
+}
+
+// setPreset switches to a control preset and warns about keys it shares
+// with other actions.
+func (g *Game) setPreset(i int) {
+	g.preset = i
+	g.bindings = controlPresets[i].bindings
+	for _, c := range bindingConflicts(g.bindings, g.second != nil) {
+		slog.Warn("conflicting key binding", "preset", controlPresets[i].name, "conflict", c)
+	}
+}
+
+// updateControls cycles the control presets on F6.
+func (g *Game) updateControls() {
+	if !g.justPressed(ebiten.KeyF6) {
+		return
+	}
+	g.setPreset((g.preset + 1) % len(controlPresets))
+	g.toast("Controls: " + controlPresets[g.preset].name)
+}
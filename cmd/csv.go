@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var csvHeader = []string{"x", "y", "label", "type"}
+
+// parsePlaceRow reads one x,y,label,type row. type is "marker" or
+// "label", and a marker when left out.
+func parsePlaceRow(row []string) (p Label, isLabel bool, err error) {
+	if len(row) < 3 || len(row) > 4 {
+		return Label{}, false, fmt.Errorf("want 3 or 4 fields, got %d", len(row))
+	}
+	x, err := parseFinite(row[0])
+	if err != nil {
+		return Label{}, false, fmt.Errorf("x: %w", err)
+	}
+	y, err := parseFinite(row[1])
+	if err != nil {
+		return Label{}, false, fmt.Errorf("y: %w", err)
+	}
+	name := strings.TrimSpace(row[2])
+	if name == "" {
+		return Label{}, false, errors.New("empty label")
+	}
+	kind := "marker"
+	if len(row) == 4 && strings.TrimSpace(row[3]) != "" {
+		kind = strings.ToLower(strings.TrimSpace(row[3]))
+	}
+	switch kind {
+	case "marker":
+	case "label":
+		isLabel = true
+	default:
+		return Label{}, false, fmt.Errorf("unknown type %q", kind)
+	}
+	return Label{Name: name, X: x, Y: y}, isLabel, nil
+}
+
+// readPlacesCSV reads markers and labels from CSV rows of x,y,label,type,
+// with an optional header row and # comments. Malformed rows are logged
+// and skipped.
+func readPlacesCSV(r io.Reader, source string) (labels, markers []Label, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	for first := true; ; first = false {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return labels, markers, nil
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				slog.Warn("skipping malformed CSV row", "path", source, "line", perr.Line, "err", perr.Err)
+				continue
+			}
+			return nil, nil, err
+		}
+		if first && strings.EqualFold(strings.TrimSpace(row[0]), csvHeader[0]) {
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		p, isLabel, err := parsePlaceRow(row)
+		if err != nil {
+			slog.Warn("skipping malformed CSV row", "path", source, "line", line, "err", err)
+			continue
+		}
+		if isLabel {
+			labels = append(labels, p)
+		} else {
+			markers = append(markers, p)
+		}
+	}
+}
+
+// loadPlacesCSV reads markers and labels from a CSV file.
+func loadPlacesCSV(path string) (labels, markers []Label, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return readPlacesCSV(f, path)
+}
+
+// appendNew adds the places not already in list, so importing the same
+// file again, or on top of a save that kept earlier imports, doesn't
+// duplicate them.
+func appendNew(list, places []Label) []Label {
+	for _, p := range places {
+		if !slices.Contains(list, p) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// writePlacesCSV writes labels and markers as x,y,label,type rows with a
+// header, readable again by readPlacesCSV.
+func writePlacesCSV(path string, labels, markers []Label) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write(csvHeader)
+	write := func(places []Label, kind string) {
+		for _, p := range places {
+			w.Write([]string{strconv.FormatFloat(p.X, 'f', -1, 64), strconv.FormatFloat(p.Y, 'f', -1, 64), p.Name, kind})
+		}
+	}
+	write(labels, "label")
+	write(markers, "marker")
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParsePlaceRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     []string
+		want    Label
+		isLabel bool
+		err     bool
+	}{
+		{"marker", []string{"10", "20", "Camp"}, Label{Name: "Camp", X: 10, Y: 20}, false, false},
+		{"explicit marker", []string{"10", "20", "Camp", "marker"}, Label{Name: "Camp", X: 10, Y: 20}, false, false},
+		{"label", []string{"1.5", "-2", "Lake", "label"}, Label{Name: "Lake", X: 1.5, Y: -2}, true, false},
+		{"type case and spaces", []string{" 3 ", " 4 ", "  Inn ", " LABEL "}, Label{Name: "Inn", X: 3, Y: 4}, true, false},
+		{"empty type", []string{"3", "4", "Inn", ""}, Label{Name: "Inn", X: 3, Y: 4}, false, false},
+		{"too few fields", []string{"3", "4"}, Label{}, false, true},
+		{"too many fields", []string{"3", "4", "Inn", "label", "extra"}, Label{}, false, true},
+		{"bad x", []string{"three", "4", "Inn"}, Label{}, false, true},
+		{"bad y", []string{"3", "4px", "Inn"}, Label{}, false, true},
+		{"NaN", []string{"NaN", "4", "Inn"}, Label{}, false, true},
+		{"infinity", []string{"3", "-Inf", "Inn"}, Label{}, false, true},
+		{"empty label", []string{"3", "4", "  "}, Label{}, false, true},
+		{"unknown type", []string{"3", "4", "Inn", "shop"}, Label{}, false, true},
+	}
+	for _, tt := range tests {
+		p, isLabel, err := parsePlaceRow(tt.row)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: parsePlaceRow(%q) error = %v, want error %v", tt.name, tt.row, err, tt.err)
+			continue
+		}
+		if p != tt.want || isLabel != tt.isLabel {
+			t.Errorf("%s: parsePlaceRow(%q) = %+v, %v, want %+v, %v", tt.name, tt.row, p, isLabel, tt.want, tt.isLabel)
+		}
+	}
+}
+
+func TestReadPlacesCSV(t *testing.T) {
+	camp := Label{Name: "Camp", X: 10, Y: 20}
+	lake := Label{Name: "Lake", X: 1.5, Y: -2}
+	tests := []struct {
+		name            string
+		in              string
+		labels, markers []Label
+	}{
+		{"empty", "", nil, nil},
+		{"header only", "x,y,label,type\n", nil, nil},
+		{"with header", "x,y,label,type\n10,20,Camp,marker\n1.5,-2,Lake,label\n", []Label{lake}, []Label{camp}},
+		{"header in capitals", "X, Y ,Label\n10,20,Camp\n", nil, []Label{camp}},
+		{"no header", "10,20,Camp\n", nil, []Label{camp}},
+		{"header only counts first", "10,20,Camp\nx,y,label\n", nil, []Label{camp}},
+		{"blank lines and comments", "\n# places\n10,20,Camp\n\n\n1.5,-2,Lake,label\n", []Label{lake}, []Label{camp}},
+		{"CRLF", "x,y,label\r\n10,20,Camp\r\n", nil, []Label{camp}},
+		{"quoted fields", "10,20,\"Camp, north\"\n1,2,\"The \"\"Old\"\" Inn\",label\n",
+			[]Label{{Name: `The "Old" Inn`, X: 1, Y: 2}}, []Label{{Name: "Camp, north", X: 10, Y: 20}}},
+		{"quoted number", "\"10\",\"20\",Camp\n", nil, []Label{camp}},
+		{"bad numbers skipped", "ten,20,Camp\n10,20,Camp\n10,NaN,Lake\n", nil, []Label{camp}},
+		{"wrong column counts skipped", "10,20\n10,20,Camp\n1,2,A,label,extra\n", nil, []Label{camp}},
+		{"unknown type skipped", "10,20,Camp,shop\n1.5,-2,Lake,label\n", []Label{lake}, nil},
+		{"bare quote skipped", "10,20,Ca\"mp\n1.5,-2,Lake,label\n", []Label{lake}, nil},
+		{"unterminated quote at the end", "1.5,-2,Lake,label\n10,20,\"Camp\n", []Label{lake}, nil},
+	}
+	for _, tt := range tests {
+		labels, markers, err := readPlacesCSV(strings.NewReader(tt.in), tt.name)
+		if err != nil {
+			t.Errorf("%s: readPlacesCSV error %v", tt.name, err)
+			continue
+		}
+		if !slices.Equal(labels, tt.labels) || !slices.Equal(markers, tt.markers) {
+			t.Errorf("%s: readPlacesCSV = %+v, %+v, want %+v, %+v", tt.name, labels, markers, tt.labels, tt.markers)
+		}
+	}
+}
+
+// What writePlacesCSV writes reads back the same, names that need quoting
+// included.
+func TestPlacesCSVRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "places.csv")
+	labels := []Label{{Name: "Lake, east", X: 1.25, Y: -3}, {Name: `Say "hi"`, X: 0, Y: 0}}
+	markers := []Label{{Name: "Camp", X: 1e6, Y: 20}}
+	if err := writePlacesCSV(path, labels, markers); err != nil {
+		t.Fatal(err)
+	}
+	gotL, gotM, err := loadPlacesCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(gotL, labels) || !slices.Equal(gotM, markers) {
+		data, _ := os.ReadFile(path)
+		t.Errorf("read back %+v, %+v from\n%s", gotL, gotM, data)
+	}
+}
+
+func TestAppendNew(t *testing.T) {
+	a, b := Label{Name: "A"}, Label{Name: "B", X: 1}
+	got := appendNew([]Label{a}, []Label{a, b, b})
+	if want := []Label{a, b}; !slices.Equal(got, want) {
+		t.Errorf("appendNew = %v, want %v", got, want)
+	}
+}
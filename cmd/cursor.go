@@ -0,0 +1,32 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// cursorNeeded reports whether the current context uses the mouse, or the
+// window has lost focus and the cursor must be given back.
+func (g *Game) cursorNeeded() bool {
+	return g.menu != nil || g.freeCam || g.overview || !ebiten.IsFocused()
+}
+
+// updateCursor hides the mouse cursor during plain gameplay when
+// configured, bringing it back for a while whenever the mouse moves so
+// markers can still be placed.
+func (g *Game) updateCursor() {
+	if !g.cfg.Cursor.HideInGameplay {
+		return
+	}
+	cx, cy := ebiten.CursorPosition()
+	if cx != g.cursorX || cy != g.cursorY {
+		g.cursorX, g.cursorY = cx, cy
+		g.cursorIdle = 0
+	} else {
+		g.cursorIdle += deltaTime()
+	}
+	mode := ebiten.CursorModeHidden
+	if g.cursorNeeded() || g.cursorIdle < g.cfg.Cursor.ShowAfterMove {
+		mode = ebiten.CursorModeVisible
+	}
+	if ebiten.CursorMode() != mode {
+		ebiten.SetCursorMode(mode)
+	}
+}
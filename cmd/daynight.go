@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// lightSize is the resolution of the radial light sprite; it is scaled to
+// the configured radius when drawn.
+const lightSize = 256
+
+var nightColor = color.RGBA{0x05, 0x08, 0x20, 0xff}
+
+// darkness is how dark the night overlay is at a time of day t in [0, 1),
+// where 0 is noon and 0.5 midnight.
+func darkness(t float64) float64 {
+	return (1 - math.Cos(2*math.Pi*t)) / 2
+}
+
+// scrubHoursPerSecond is how fast , and . move the clock while held, in
+// in-game hours per real second.
+const scrubHoursPerSecond = 3.0
+
+// updateDayNight advances the time of day, or scrubs it with , and .
+func (g *Game) updateDayNight() {
+	cfg := g.cfg.DayNight
+	if !cfg.Enabled || cfg.DayLength <= 0 {
+		return
+	}
+	step := deltaTime() / cfg.DayLength
+	if ebiten.IsKeyPressed(ebiten.KeyPeriod) {
+		step = scrubHoursPerSecond * deltaTime() / 24
+	} else if ebiten.IsKeyPressed(ebiten.KeyComma) {
+		step = -scrubHoursPerSecond * deltaTime() / 24
+	}
+	g.timeOfDay = math.Mod(g.timeOfDay+step+1, 1)
+}
+
+// clockTime converts a time of day (0 at noon) to a 24-hour HH:MM.
+func clockTime(t float64) string {
+	minutes := int(math.Mod(t*24*60+12*60, 24*60))
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// drawClock shows the in-game time in the configured corner.
+func (g *Game) drawClock(screen *ebiten.Image) {
+	const margin = 8
+	text := clockTime(g.timeOfDay)
+	textW, lineH := textWidth(text), textLineHeight()
+	// sized to reach the far corner so it also covers a sub-image of the
+	// screen, whose bounds don't start at the origin
+	sw, sh := screen.Bounds().Max.X, screen.Bounds().Max.Y
+	x, y := margin, margin
+	switch g.cfg.DayNight.Clock.Corner {
+	case "top-right":
+		x = sw - margin - textW
+	case "bottom-left":
+		y = sh - margin - lineH
+	case "bottom-right":
+		x, y = sw-margin-textW, sh-margin-lineH
+	}
+	drawText(screen, text, x, y)
+}
+
+// lightSprite is a white disc fading out from the center, used to cut the
+// player's light out of the night overlay.
+func (g *Game) lightSprite() *ebiten.Image {
+	if g.light == nil {
+		pix := make([]byte, 4*lightSize*lightSize)
+		for y := range lightSize {
+			for x := range lightSize {
+				dx := (float64(x)+0.5)/lightSize*2 - 1
+				dy := (float64(y)+0.5)/lightSize*2 - 1
+				a := 1 - math.Hypot(dx, dy)
+				if a <= 0 {
+					continue
+				}
+				// ease the edge so the light has no visible rim
+				v := byte(255 * a * a * (3 - 2*a))
+				i := 4 * (y*lightSize + x)
+				pix[i], pix[i+1], pix[i+2], pix[i+3] = v, v, v, v
+			}
+		}
+		g.light = ebiten.NewImage(lightSize, lightSize)
+		g.light.WritePixels(pix)
+	}
+	return g.light
+}
+
+// drawNight darkens the map for the time of day, leaving a pool of light
+// around the player when enabled.
+func (g *Game) drawNight(screen *ebiten.Image, scale, tx, ty float64) {
+	cfg := g.cfg.DayNight
+	if !cfg.Enabled || !g.effectOn("night") {
+		return
+	}
+	alpha := darkness(g.timeOfDay) * cfg.NightAlpha
+	if alpha <= 0 {
+		return
+	}
+	// sized to reach the far corner so it also covers a sub-image of the
+	// screen, whose bounds don't start at the origin
+	sw, sh := screen.Bounds().Max.X, screen.Bounds().Max.Y
+	if g.night == nil || g.night.Bounds().Dx() < sw || g.night.Bounds().Dy() < sh {
+		if g.night != nil {
+			g.night.Deallocate()
+		}
+		g.night = ebiten.NewImage(g.screenImageSize(sw, sh))
+	}
+	// a larger image left over from a resize is drawn from its corner
+	night := g.night.SubImage(image.Rect(0, 0, sw, sh)).(*ebiten.Image)
+	night.Fill(nightColor)
+
+	if cfg.Light.Radius > 0 && cfg.Light.Intensity > 0 {
+		r := cfg.Light.Radius * scale
+		lights := [][2]float64{{g.px, g.py}}
+		if g.second != nil {
+			lights = append(lights, [2]float64{g.second.x, g.second.y})
+		}
+		for _, p := range lights {
+			cx := (p[0]+float64(g.playerW)/2)*scale + tx
+			cy := (p[1]+float64(g.playerH)/2)*scale + ty
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(2*r/lightSize, 2*r/lightSize)
+			op.GeoM.Translate(cx-r, cy-r)
+			op.ColorScale.ScaleAlpha(float32(min(cfg.Light.Intensity, 1)))
+			op.Blend = ebiten.BlendDestinationOut
+			op.Filter = ebiten.FilterLinear
+			night.DrawImage(g.lightSprite(), op)
+		}
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(float32(alpha))
+	screen.DrawImage(night, op)
+}
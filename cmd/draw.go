@@ -0,0 +1,29 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+var whitePixelImage *ebiten.Image
+
+// whitePixel is a 1x1 white image for drawing tinted rectangles.
+func whitePixel() *ebiten.Image {
+	if whitePixelImage == nil {
+		whitePixelImage = ebiten.NewImage(1, 1)
+		whitePixelImage.Fill(color.White)
+	}
+	return whitePixelImage
+}
+
+// drawText draws HUD text with its top-left corner at (x, y), in the
+// configured font or else the debug font.
+func drawText(dst *ebiten.Image, msg string, x, y int) {
+	if uiFace != nil {
+		drawFontText(dst, msg, x, y)
+		return
+	}
+	ebitenutil.DebugPrintAt(dst, msg, x, y)
+}
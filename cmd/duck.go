@@ -0,0 +1,101 @@
+package main
+
+// duckRamp is how long, in seconds, the music takes to fade down into a
+// duck and back up after it.
+const duckRamp = 0.25
+
+// duckMusic lowers the music by amount (0..1 of the base volume) for
+// duration seconds, then lets updateDuck ramp it back. Overlapping ducks
+// keep the deepest amount and the latest end instead of stacking.
+func (g *Game) duckMusic(amount, duration float64) {
+	if amount <= 0 || duration <= 0 {
+		return
+	}
+	amount = min(amount, 1)
+	if g.duckLeft > 0 {
+		g.duckDepth = max(g.duckDepth, amount)
+	} else {
+		g.duckDepth = amount
+	}
+	g.duckLeft = max(g.duckLeft, duration)
+}
+
+// rampToward moves v toward target by at most step.
+func rampToward(v, target, step float64) float64 {
+	if v < target {
+		return min(v+step, target)
+	}
+	return max(v-step, target)
+}
+
+// zoomGainTarget is the music gain for the current zoom: full from
+// audio.zoomFade.fullAt in, falling linearly to 1 - amount at the widest
+// zoom and in the overview.
+func (g *Game) zoomGainTarget() float64 {
+	cfg := g.cfg.Audio.ZoomFade
+	if cfg.Amount <= 0 {
+		return 1
+	}
+	t := 0.0
+	if !g.overview && cfg.FullAt > g.minZoom {
+		t = min(max((g.zoom-g.minZoom)/(cfg.FullAt-g.minZoom), 0), 1)
+	}
+	return 1 - min(cfg.Amount, 1)*(1-t)
+}
+
+// musicGain is the factor the configured music volume is scaled by.
+func (g *Game) musicGain() float64 {
+	return g.duckGain * g.zoomGain * g.pauseGain
+}
+
+// updatePauseFade fades the music out while the pause menu is open, with
+// audio.pause.enabled, and back in once it closes. It always ramps toward
+// the gain for the current state, so pausing and resuming quickly just
+// turns the fade around. The music is paused once fully faded, keeping
+// its place for when it fades back in.
+func (g *Game) updatePauseFade() {
+	cfg := g.cfg.Audio.Pause
+	target := 1.0
+	if cfg.Enabled && g.menu != nil {
+		target = 0
+	}
+	if cfg.Fade > 0 {
+		g.pauseGain = rampToward(g.pauseGain, target, deltaTime()/cfg.Fade)
+	} else {
+		g.pauseGain = target
+	}
+	if g.audioPlayer == nil {
+		return
+	}
+	switch {
+	case g.pauseGain == 0 && g.audioPlayer.IsPlaying():
+		g.audioPlayer.Pause()
+		g.musicHeld = true
+	case g.pauseGain > 0 && g.musicHeld:
+		g.audioPlayer.Play()
+		g.musicHeld = false
+	}
+}
+
+// updateDuck moves the music gain toward its ducked or full level and the
+// gains for the zoom and pausing, ramping them, and applies them on top of
+// the configured volume.
+func (g *Game) updateDuck() {
+	dt := deltaTime()
+	target := 1.0
+	if g.duckLeft > 0 {
+		g.duckLeft -= dt
+		target = 1 - g.duckDepth
+	}
+	step := dt / duckRamp
+	g.duckGain = rampToward(g.duckGain, target, step)
+	if ramp := g.cfg.Audio.ZoomFade.Ramp; ramp > 0 {
+		g.zoomGain = rampToward(g.zoomGain, g.zoomGainTarget(), dt/ramp)
+	} else {
+		g.zoomGain = g.zoomGainTarget()
+	}
+	g.updatePauseFade()
+	if g.audioPlayer != nil {
+		g.audioPlayer.SetVolume(g.cfg.Audio.Volume * g.musicGain() * g.musicFadeIn())
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// stateDump is the snapshot written for bug reports. It only holds plain
+// values so it never tries to marshal images or audio players.
+type stateDump struct {
+	Time     string      `json:"time"`
+	Scene    int         `json:"scene"`
+	Player   [2]float64  `json:"player"`
+	PlayerW  int         `json:"playerW"`
+	PlayerH  int         `json:"playerH"`
+	Second   *[2]float64 `json:"second,omitempty"`
+	View     [2]float64  `json:"view"`
+	Zoom     float64     `json:"zoom"`
+	MinZoom  float64     `json:"minZoom"`
+	Screen   [2]int      `json:"screen"`
+	Tile     [2]int      `json:"tile"`
+	World    [2]int      `json:"world"`
+	FreeCam  bool        `json:"freeCam"`
+	Flags    dumpFlags   `json:"flags"`
+	Markers  int         `json:"markers"`
+	Labels   int         `json:"labels"`
+	Toasts   []string    `json:"toasts,omitempty"`
+	MapParts []string    `json:"mapParts"`
+	// lazy loading counters, only for lazy maps
+	PartCache *partCacheStats `json:"partCache,omitempty"`
+}
+
+type partCacheStats struct {
+	Loaded int `json:"loaded"`
+	Failed int `json:"failed"`
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+type dumpFlags struct {
+	Fog        bool `json:"fog"`
+	Heatmap    bool `json:"heatmap"`
+	Collision  bool `json:"collision"`
+	Grid       bool `json:"grid"`
+	Nearest    bool `json:"nearest"`
+	FrameGraph bool `json:"frameGraph"`
+	Debug      bool `json:"debug"`
+	Overview   bool `json:"overview"`
+	Minimap    bool `json:"minimap"`
+	Clock      bool `json:"clock"`
+	Legend     bool `json:"legend"`
+	Magnifier  bool `json:"magnifier"`
+	Reticle    bool `json:"reticle"`
+	Overlay    bool `json:"overlay"`
+	Explored   bool `json:"explored"`
+	Pixel      bool `json:"pixel"`
+	ViewRect   bool `json:"viewRect"`
+	LazyStats  bool `json:"lazyStats"`
+}
+
+// dumpState serializes the current game state as indented JSON.
+func (g *Game) dumpState() ([]byte, error) {
+	d := stateDump{
+		Time:    time.Now().Format(time.RFC3339),
+		Scene:   int(g.scene),
+		Player:  [2]float64{g.px, g.py},
+		PlayerW: g.playerW,
+		PlayerH: g.playerH,
+		View:    [2]float64{g.vx, g.vy},
+		Zoom:    g.zoom,
+		MinZoom: g.minZoom,
+		Screen:  [2]int{g.screenW, g.screenH},
+		Tile:    [2]int{g.tileW, g.tileH},
+		FreeCam: g.freeCam,
+		Flags: dumpFlags{
+			Fog:        g.showFog,
+			Heatmap:    g.showHeatmap,
+			Collision:  g.showCollision,
+			Grid:       g.showGrid,
+			Nearest:    g.showNearest,
+			FrameGraph: g.showFrameGraph,
+			Debug:      g.cfg.Debug,
+			Overview:   g.overview,
+			Minimap:    g.showMinimap,
+			Clock:      g.showClock,
+			Legend:     g.showLegend,
+			Magnifier:  g.showMagnifier,
+			Reticle:    g.showReticle,
+			Overlay:    g.showOverlay,
+			Explored:   g.showExplored,
+			Pixel:      g.showPixel,
+			ViewRect:   g.showViewRect,
+			LazyStats:  g.showLazyStats,
+		},
+		Markers: len(g.markers),
+		Labels:  len(g.cfg.Labels),
+	}
+	if g.second != nil {
+		d.Second = &[2]float64{g.second.x, g.second.y}
+	}
+	if g.bg != nil {
+		d.World[0], d.World[1] = g.bg.size()
+		for _, p := range g.bg.parts {
+			d.MapParts = append(d.MapParts, p.path)
+		}
+		if l := g.bg.lazy; l != nil {
+			d.PartCache = &partCacheStats{Hits: l.hits, Misses: l.misses}
+			for i, p := range g.bg.parts {
+				if p.img != nil {
+					d.PartCache.Loaded++
+				}
+				if l.failed[i] {
+					d.PartCache.Failed++
+				}
+			}
+		}
+	}
+	for _, t := range g.toasts {
+		d.Toasts = append(d.Toasts, t.msg)
+	}
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// writeStateDump writes dumpState to a timestamped file in the working
+// directory.
+func (g *Game) writeStateDump() {
+	data, err := g.dumpState()
+	if err != nil {
+		slog.Warn("failed to dump state", "err", err)
+		return
+	}
+	path := fmt.Sprintf("state-%s.json", time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		slog.Warn("failed to write state dump", "path", path, "err", err)
+		return
+	}
+	slog.Info("dumped game state", "path", path)
+	g.toast("Dumped state to " + path)
+}
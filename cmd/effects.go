@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// effectsWarmup is how many seconds after startup the frame rate is left
+// alone, while ActualFPS is still settling.
+const effectsWarmup = 2.0
+
+// shouldDropEffects decides, with hysteresis, whether effects should be
+// off at fps: they go below threshold and only come back once fps is
+// recover frames a second above it.
+func shouldDropEffects(dropped bool, fps, threshold, recover float64) bool {
+	if dropped {
+		return fps < threshold+recover
+	}
+	return fps < threshold
+}
+
+// updateEffects turns the effects listed in perf.lowFPS.effects off while
+// the frame rate is below perf.lowFPS.threshold.
+func (g *Game) updateEffects() {
+	cfg := g.cfg.Perf.LowFPS
+	if cfg.Threshold <= 0 {
+		return
+	}
+	if g.effectsClock < effectsWarmup {
+		g.effectsClock += deltaTime()
+		return
+	}
+	fps := ebiten.ActualFPS()
+	dropped := shouldDropEffects(g.effectsDropped, fps, cfg.Threshold, cfg.Recover)
+	if dropped == g.effectsDropped {
+		return
+	}
+	g.effectsDropped = dropped
+	if dropped {
+		slog.Info("frame rate low, dropping effects", "fps", fps, "effects", cfg.Effects)
+	} else {
+		slog.Info("frame rate recovered, restoring effects", "fps", fps)
+	}
+}
+
+// effectOn reports whether the named effect should be drawn: always,
+// unless it is droppable and the frame rate is low.
+func (g *Game) effectOn(name string) bool {
+	return !g.effectsDropped || !slices.Contains(g.cfg.Perf.LowFPS.Effects, name)
+}
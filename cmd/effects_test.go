@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestShouldDropEffects(t *testing.T) {
+	const threshold, recover = 30, 10
+	tests := []struct {
+		name    string
+		dropped bool
+		fps     float64
+		want    bool
+	}{
+		{"fast", false, 60, false},
+		{"at threshold", false, 30, false},
+		{"just below", false, 29.9, true},
+		{"still low", true, 25, true},
+		{"back at threshold", true, 30, true},
+		{"within recover", true, 39.9, true},
+		{"recovered", true, 40, false},
+		{"well recovered", true, 60, false},
+	}
+	for _, tt := range tests {
+		if got := shouldDropEffects(tt.dropped, tt.fps, threshold, recover); got != tt.want {
+			t.Errorf("%s: shouldDropEffects(%v, %v) = %v, want %v", tt.name, tt.dropped, tt.fps, got, tt.want)
+		}
+	}
+}
+
+// A frame rate hovering around the threshold switches effects off once
+// and only back on after it clears the recover margin.
+func TestShouldDropEffectsHysteresis(t *testing.T) {
+	fps := []float64{45, 31, 29, 31, 29, 35, 38, 29, 41, 35, 31}
+	want := []bool{false, false, true, true, true, true, true, true, false, false, false}
+	dropped, switches := false, 0
+	for i, f := range fps {
+		next := shouldDropEffects(dropped, f, 30, 10)
+		if next != dropped {
+			switches++
+		}
+		dropped = next
+		if dropped != want[i] {
+			t.Errorf("sample %d at %v fps: dropped = %v, want %v", i, f, dropped, want[i])
+		}
+	}
+	if switches != 2 {
+		t.Errorf("effects switched %d times, want 2", switches)
+	}
+}
+
+func TestEffectOn(t *testing.T) {
+	g := newTestGame(100, 100)
+	g.cfg.Perf.LowFPS.Effects = []string{"night", "shadow"}
+	if !g.effectOn("night") {
+		t.Error("night off before the frame rate dropped")
+	}
+	g.effectsDropped = true
+	if g.effectOn("night") || g.effectOn("shadow") {
+		t.Error("a droppable effect stayed on at a low frame rate")
+	}
+	if !g.effectOn("minimap") {
+		t.Error("an effect not listed was dropped")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// exploredPercent is the share of fog cells revealed, from 0 to 100.
+func exploredPercent(f *fogLayer) float64 {
+	if f == nil || len(f.explored) == 0 {
+		return 0
+	}
+	return 100 * float64(f.seen) / float64(len(f.explored))
+}
+
+// exploreMilestones counts the configured milestones already reached at pct.
+func exploreMilestones(milestones []float64, pct float64) int {
+	n := 0
+	for _, m := range milestones {
+		if pct >= m {
+			n++
+		}
+	}
+	return n
+}
+
+// updateExplore toggles the exploration readout with U and congratulates
+// the player on each milestone they pass. Milestones already passed in a
+// restored save are not announced again.
+func (g *Game) updateExplore() {
+	if g.justPressed(ebiten.KeyU) {
+		g.showExplored = !g.showExplored
+	}
+	if g.fog == nil {
+		return
+	}
+	pct := exploredPercent(g.fog)
+	reached := exploreMilestones(g.cfg.Explore.Milestones, pct)
+	if reached > g.exploreReached {
+		// announce only the highest one if several were passed at once
+		best := 0.0
+		for _, m := range g.cfg.Explore.Milestones {
+			if pct >= m {
+				best = max(best, m)
+			}
+		}
+		if best >= 100 {
+			g.toast("The whole map is explored!")
+		} else {
+			g.toast(fmt.Sprintf("%.0f%% of the map explored", best))
+		}
+	}
+	g.exploreReached = reached
+}
+
+// exploreText is the HUD line for the exploration readout.
+func (g *Game) exploreText() string {
+	return fmt.Sprintf("Explored %.1f%%", exploredPercent(g.fog))
+}
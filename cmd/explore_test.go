@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExploredPercent(t *testing.T) {
+	if got := exploredPercent(nil); got != 0 {
+		t.Errorf("exploredPercent(nil) = %v, want 0", got)
+	}
+	f := newFogLayer(10*fogCellSize, 10*fogCellSize)
+	if got := exploredPercent(f); got != 0 {
+		t.Errorf("fresh fog is %v%% explored, want 0", got)
+	}
+	f.reveal(5*fogCellSize, 5*fogCellSize, fogRevealRadius)
+	want := 100 * float64(f.seen) / 100
+	if got := exploredPercent(f); got != want || got <= 0 {
+		t.Errorf("after one reveal %v%% explored, want %v", got, want)
+	}
+	// revealing the same spot again adds nothing
+	f.reveal(5*fogCellSize, 5*fogCellSize, fogRevealRadius)
+	if got := exploredPercent(f); got != want {
+		t.Errorf("revealing again changed %v%% to %v%%", want, got)
+	}
+	for y := range 10 {
+		for x := range 10 {
+			f.reveal((float64(x)+0.5)*fogCellSize, (float64(y)+0.5)*fogCellSize, 1)
+		}
+	}
+	if got := exploredPercent(f); got != 100 {
+		t.Errorf("after revealing every cell %v%% explored, want 100", got)
+	}
+}
+
+func TestExploreMilestones(t *testing.T) {
+	ms := []float64{10, 25, 50, 100}
+	tests := []struct {
+		pct  float64
+		want int
+	}{
+		{0, 0},
+		{9.99, 0},
+		{10, 1},
+		{30, 2},
+		{99.9, 3},
+		{100, 4},
+	}
+	for _, tt := range tests {
+		if got := exploreMilestones(ms, tt.pct); got != tt.want {
+			t.Errorf("exploreMilestones at %v%% = %d, want %d", tt.pct, got, tt.want)
+		}
+	}
+	if got := exploreMilestones(nil, 100); got != 0 {
+		t.Errorf("no milestones gave %d", got)
+	}
+}
+
+// Passing several milestones at once announces only the highest, and a
+// milestone is never announced twice.
+func TestUpdateExploreAnnounces(t *testing.T) {
+	g := newTestGame(10*fogCellSize, 10*fogCellSize)
+	g.cfg.Explore.Milestones = []float64{10, 25, 50, 100}
+	g.fog = newFogLayer(10*fogCellSize, 10*fogCellSize)
+	// reveals the next n cells in reading order
+	reveal := func(n int) {
+		for range n {
+			f := g.fog
+			x, y := f.seen%10, f.seen/10
+			f.reveal((float64(x)+0.5)*fogCellSize, (float64(y)+0.5)*fogCellSize, 1)
+		}
+	}
+	var toasts []string
+	update := func() {
+		before := len(g.toasts)
+		g.updateExplore()
+		for _, ts := range g.toasts[before:] {
+			toasts = append(toasts, ts.msg)
+		}
+	}
+	reveal(30)
+	update()
+	update()
+	reveal(70)
+	update()
+	want := []string{"25% of the map explored", "The whole map is explored!"}
+	if len(toasts) != len(want) || toasts[0] != want[0] || toasts[1] != want[1] {
+		t.Errorf("toasts %q, want %q", toasts, want)
+	}
+	if g.exploreReached != 4 || math.Abs(exploredPercent(g.fog)-100) > 1e-9 {
+		t.Errorf("milestone %d at %v%%, want 4 at 100%%", g.exploreReached, exploredPercent(g.fog))
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// parseFilter maps a map.filter setting to an ebiten filter.
+func parseFilter(name string) ebiten.Filter {
+	switch name {
+	case "linear":
+		return ebiten.FilterLinear
+	case "nearest", "":
+		return ebiten.FilterNearest
+	}
+	slog.Warn("unknown map filter, using nearest", "filter", name)
+	return ebiten.FilterNearest
+}
+
+// updateFilter switches the map, sprite and minimap scaling between
+// nearest and linear on F7.
+func (g *Game) updateFilter() {
+	if !g.justPressed(ebiten.KeyF7) {
+		return
+	}
+	if g.filter == ebiten.FilterNearest {
+		g.filter = ebiten.FilterLinear
+		g.toast("Filter: linear")
+	} else {
+		g.filter = ebiten.FilterNearest
+		g.toast("Filter: nearest")
+	}
+}
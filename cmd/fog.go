@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// size of one fog cell in background pixels
+	fogCellSize = 16
+	// radius around the player that gets revealed, in background pixels
+	fogRevealRadius = 96.0
+	// alpha used for unexplored cells, both on screen and in exports
+	fogAlpha = 170
+)
+
+// fogLayer tracks which parts of the map the player has already seen as a
+// coarse grid of cells.
+type fogLayer struct {
+	cols, rows int
+	explored   []bool
+	// how many cells are explored
+	seen int
+	// cols x rows image, one pixel per cell, rebuilt when dirty
+	img    *ebiten.Image
+	pixels []byte
+	dirty  bool
+}
+
+func newFogLayer(w, h int) *fogLayer {
+	cols := (w + fogCellSize - 1) / fogCellSize
+	rows := (h + fogCellSize - 1) / fogCellSize
+	if cols < 1 {
+		cols = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return &fogLayer{
+		cols:     cols,
+		rows:     rows,
+		explored: make([]bool, cols*rows),
+		dirty:    true,
+	}
+}
+
+// reveal marks every cell whose center lies within radius of (cx, cy).
+func (f *fogLayer) reveal(cx, cy, radius float64) {
+	minCol := int((cx - radius) / fogCellSize)
+	maxCol := int((cx + radius) / fogCellSize)
+	minRow := int((cy - radius) / fogCellSize)
+	maxRow := int((cy + radius) / fogCellSize)
+	for row := max(minRow, 0); row <= min(maxRow, f.rows-1); row++ {
+		for col := max(minCol, 0); col <= min(maxCol, f.cols-1); col++ {
+			i := row*f.cols + col
+			if f.explored[i] {
+				continue
+			}
+			ddx := (float64(col)+0.5)*fogCellSize - cx
+			ddy := (float64(row)+0.5)*fogCellSize - cy
+			if ddx*ddx+ddy*ddy <= radius*radius {
+				f.explored[i] = true
+				f.seen++
+				f.dirty = true
+			}
+		}
+	}
+}
+
+func (f *fogLayer) isExplored(col, row int) bool {
+	if col < 0 || row < 0 || col >= f.cols || row >= f.rows {
+		return false
+	}
+	return f.explored[row*f.cols+col]
+}
+
+// image returns the fog as a cols x rows image meant to be drawn scaled up
+// by fogCellSize.
+func (f *fogLayer) image() *ebiten.Image {
+	if f.img == nil {
+		f.img = ebiten.NewImage(f.cols, f.rows)
+		f.pixels = make([]byte, 4*f.cols*f.rows)
+	}
+	if f.dirty {
+		for i, seen := range f.explored {
+			a := byte(fogAlpha)
+			if seen {
+				a = 0
+			}
+			// premultiplied black, so only alpha matters
+			f.pixels[4*i+3] = a
+		}
+		f.img.WritePixels(f.pixels)
+		f.dirty = false
+	}
+	return f.img
+}
+
+// fogData is the persisted form of a fog layer, one bit per cell.
+type fogData struct {
+	Cols int    `json:"cols"`
+	Rows int    `json:"rows"`
+	Bits []byte `json:"bits"`
+}
+
+func (f *fogLayer) data() *fogData {
+	bits := make([]byte, (len(f.explored)+7)/8)
+	for i, seen := range f.explored {
+		if seen {
+			bits[i/8] |= 1 << (i % 8)
+		}
+	}
+	return &fogData{Cols: f.cols, Rows: f.rows, Bits: bits}
+}
+
+// restore loads saved explored cells if they were recorded on the same
+// grid.
+func (f *fogLayer) restore(d *fogData) {
+	if d.Cols != f.cols || d.Rows != f.rows || len(d.Bits) != (len(f.explored)+7)/8 {
+		return
+	}
+	f.seen = 0
+	for i := range f.explored {
+		f.explored[i] = d.Bits[i/8]&(1<<(i%8)) != 0
+		if f.explored[i] {
+			f.seen++
+		}
+	}
+	f.dirty = true
+}
+
+// snapshot copies the explored cells so they can be read off the main
+// goroutine.
+func (f *fogLayer) snapshot() *fogLayer {
+	explored := make([]bool, len(f.explored))
+	copy(explored, f.explored)
+	return &fogLayer{cols: f.cols, rows: f.rows, explored: explored, seen: f.seen}
+}
+
+// darkenUnexplored shades the unexplored cells of a full-resolution map
+// image in place.
+func darkenUnexplored(img *image.RGBA, fog *fogLayer) {
+	shade := image.NewUniform(color.RGBA{A: fogAlpha})
+	for row := 0; row < fog.rows; row++ {
+		for col := 0; col < fog.cols; col++ {
+			if fog.isExplored(col, row) {
+				continue
+			}
+			cell := image.Rect(col*fogCellSize, row*fogCellSize, (col+1)*fogCellSize, (row+1)*fogCellSize)
+			draw.Draw(img, cell.Intersect(img.Bounds()), shade, image.Point{}, draw.Over)
+		}
+	}
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exportExplored writes the explored map to a timestamped PNG in the
+// background so the game loop doesn't hitch on large maps.
+func (g *Game) exportExplored() {
+	if g.bg == nil || g.fog == nil {
+		return
+	}
+	if !g.exporting.CompareAndSwap(false, true) {
+		slog.Info("export already in progress")
+		return
+	}
+	fog := g.fog.snapshot()
+	bg := g.bg
+	path := fmt.Sprintf("explored-%s.png", time.Now().Format("20060102-150405"))
+	go func() {
+		defer g.exporting.Store(false)
+		img := bg.compose()
+		darkenUnexplored(img, fog)
+		if err := writePNG(path, img); err != nil {
+			slog.Warn("failed to export explored map", "path", path, "err", err)
+			return
+		}
+		slog.Info("exported explored map", "path", path)
+	}()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const (
+	// size of the debug font's glyphs, used when no font is configured
+	debugCharW, debugLineH = 6, 16
+	// window height the configured font size is meant for
+	fontBaseHeight = 720
+)
+
+// uiFace is the font all text is drawn with, nil for the debug font. It
+// is kept for the whole run so its glyph cache stays warm; only its size
+// changes with the window.
+var uiFace *text.GoTextFace
+
+// loadFont loads the configured TTF/OTF font, leaving the debug font in
+// use when there is none or it can't be read.
+func loadFont(cfg FontConfig) {
+	if cfg.Path == "" {
+		return
+	}
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		slog.Warn("failed to load font", "path", cfg.Path, "err", err)
+		return
+	}
+	src, err := text.NewGoTextFaceSource(bytes.NewReader(data))
+	if err != nil {
+		slog.Warn("failed to parse font", "path", cfg.Path, "err", err)
+		return
+	}
+	uiFace = &text.GoTextFace{Source: src, Size: max(cfg.Size, 1)}
+}
+
+// resizeFont scales the font with the window height when font.scale is
+// on, so text keeps roughly the same share of the screen. It stays within
+// half and twice the configured size.
+func resizeFont(cfg FontConfig, screenH int) {
+	if uiFace == nil || !cfg.Scale || screenH <= 0 {
+		return
+	}
+	s := min(max(float64(screenH)/fontBaseHeight, 0.5), 2)
+	// whole pixel sizes so a slow resize doesn't churn the glyph cache
+	uiFace.Size = max(math.Round(cfg.Size*s), 1)
+}
+
+// textLineHeight is the height of one line of text.
+func textLineHeight() int {
+	if uiFace == nil {
+		return debugLineH
+	}
+	m := uiFace.Metrics()
+	return int(math.Ceil(m.HAscent + m.HDescent + m.HLineGap))
+}
+
+// textWidth is how wide msg is drawn.
+func textWidth(msg string) int {
+	if uiFace == nil {
+		return debugCharW * len(msg)
+	}
+	return int(math.Ceil(text.Advance(msg, uiFace)))
+}
+
+// drawFontText draws msg with the configured font, top-left at (x, y).
+func drawFontText(dst *ebiten.Image, msg string, x, y int) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	op.LineSpacing = float64(textLineHeight())
+	text.Draw(dst, msg, uiFace, op)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"image"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// screenFrame is a decorative border drawn around the screen edges from a
+// 9-slice image.
+type screenFrame struct {
+	img *ebiten.Image
+	// source pixels making up each corner and edge
+	slice int
+	// drawn border thickness in screen pixels
+	width int
+}
+
+// newScreenFrame loads the configured border. It returns nil when none is
+// configured or it fails to load.
+func newScreenFrame(cfg FrameConfig) *screenFrame {
+	if cfg.Image == "" {
+		return nil
+	}
+	img, err := loadImage(cfg.Image)
+	if err != nil {
+		slog.Warn("failed to load frame", "path", cfg.Image, "err", err)
+		return nil
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	slice := cfg.Slice
+	if slice <= 0 || 2*slice >= min(w, h) {
+		slice = min(w, h) / 3
+	}
+	width := cfg.Width
+	if width <= 0 {
+		width = slice
+	}
+	return &screenFrame{img: img, slice: slice, width: width}
+}
+
+// mapArea is the part of a sw x sh screen the map is laid out in: inside
+// the frame when it is set to inset the map, otherwise the whole screen.
+func (g *Game) mapArea(sw, sh int) image.Rectangle {
+	r := image.Rect(0, 0, sw, sh)
+	if g.frame == nil || !g.cfg.Frame.Inset {
+		return r
+	}
+	inner := r.Inset(g.frame.width)
+	if inner.Empty() {
+		return r
+	}
+	return inner
+}
+
+// nineSlice returns the nine source and destination rectangles of a
+// 9-slice: corners keep their size, edges stretch along one axis and the
+// center along both.
+func nineSlice(src, dst image.Rectangle, slice, width int) (srcs, dsts [9]image.Rectangle) {
+	sx := [4]int{src.Min.X, src.Min.X + slice, src.Max.X - slice, src.Max.X}
+	sy := [4]int{src.Min.Y, src.Min.Y + slice, src.Max.Y - slice, src.Max.Y}
+	dx := [4]int{dst.Min.X, dst.Min.X + width, dst.Max.X - width, dst.Max.X}
+	dy := [4]int{dst.Min.Y, dst.Min.Y + width, dst.Max.Y - width, dst.Max.Y}
+	for row := range 3 {
+		for col := range 3 {
+			i := row*3 + col
+			srcs[i] = image.Rect(sx[col], sy[row], sx[col+1], sy[row+1])
+			dsts[i] = image.Rect(dx[col], dy[row], dx[col+1], dy[row+1])
+		}
+	}
+	return srcs, dsts
+}
+
+// draw stretches the border over the screen edges, leaving the center
+// open so the map shows through. It adapts to the screen size each frame.
+func (f *screenFrame) draw(screen *ebiten.Image) {
+	srcs, dsts := nineSlice(f.img.Bounds(), screen.Bounds(), f.slice, f.width)
+	for i := range srcs {
+		if i == 4 || srcs[i].Empty() || dsts[i].Empty() {
+			// the center is where the map goes
+			continue
+		}
+		piece := f.img.SubImage(srcs[i]).(*ebiten.Image)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(float64(dsts[i].Dx())/float64(srcs[i].Dx()), float64(dsts[i].Dy())/float64(srcs[i].Dy()))
+		op.GeoM.Translate(float64(dsts[i].Min.X), float64(dsts[i].Min.Y))
+		op.Filter = ebiten.FilterLinear
+		screen.DrawImage(piece, op)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+// newTestGame is a game on a blank bw x bh map, seen through a 640x480
+// screen showing a 640x480 tile at zoom 1, with a 32x32 player at the
+// center of the map. Nothing is loaded from disk and no image is created,
+// so it works without a GPU. No keys are down until the test holds some
+// on its fakeKeys.
+func newTestGame(bw, bh int) *Game {
+	g := &Game{
+		cfg:          defaultConfig(),
+		scene:        sceneMap,
+		bg:           &background{w: bw, h: bh},
+		zoom:         1,
+		minZoom:      1,
+		duckGain:     1,
+		zoomGain:     1,
+		pauseGain:    1,
+		keys:         &fakeKeys{},
+		bindings:     controlPresets[0].bindings,
+		markerIdx:    -1,
+		autosaveDone: make(chan error, 1),
+		photoDone:    make(chan photoResult, 1),
+		tileW:        640,
+		tileH:        480,
+		screenW:      640,
+		screenH:      480,
+		playerW:      32,
+		playerH:      32,
+		facing:       1,
+		hudAlpha:     1,
+	}
+	g.px = float64(bw)/2 - 16
+	g.py = float64(bh)/2 - 16
+	g.spawnX, g.spawnY = float64(bw)/2, float64(bh)/2
+	return g
+}
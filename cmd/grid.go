@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+// gridColumnName names a zero-based column like a spreadsheet:
+// A..Z, AA..AZ, BA and so on.
+func gridColumnName(col int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name
+}
+
+// gridCell returns the zero-based cell containing the world point. Points
+// before the origin fall into the first row or column.
+func gridCell(x, y, originX, originY, cellW, cellH float64) (col, row int) {
+	col = int(math.Floor((x - originX) / cellW))
+	row = int(math.Floor((y - originY) / cellH))
+	return max(col, 0), max(row, 0)
+}
+
+// gridCellName formats a cell battleship style, e.g. "E5".
+func gridCellName(col, row int) string {
+	return gridColumnName(col) + strconv.Itoa(row+1)
+}
+
+// playerGridCell names the grid cell the player's center is in.
+func (g *Game) playerGridCell() string {
+	cw, ch := g.cfg.Grid.CellW, g.cfg.Grid.CellH
+	if cw <= 0 {
+		cw = float64(g.tileW)
+	}
+	if ch <= 0 {
+		ch = float64(g.tileH)
+	}
+	col, row := gridCell(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2,
+		g.cfg.Grid.OriginX, g.cfg.Grid.OriginY, cw, ch)
+	return gridCellName(col, row)
+}
@@ -0,0 +1,92 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// heatmapAlpha is the opacity of the hottest cell in the overlay.
+const heatmapAlpha = 0.45
+
+// heatmap accumulates how long the player has spent in each tile.
+type heatmap struct {
+	cols, rows int
+	dwell      []float64
+	// cols x rows image, one pixel per tile
+	img    *ebiten.Image
+	pixels []byte
+}
+
+// heatmapData is the persisted form of a heatmap.
+type heatmapData struct {
+	Cols  int       `json:"cols"`
+	Rows  int       `json:"rows"`
+	Dwell []float64 `json:"dwell"`
+}
+
+func newHeatmap(worldW, worldH, tileW, tileH int) *heatmap {
+	cols := max((worldW+tileW-1)/tileW, 1)
+	rows := max((worldH+tileH-1)/tileH, 1)
+	return &heatmap{cols: cols, rows: rows, dwell: make([]float64, cols*rows)}
+}
+
+// add records dt seconds spent in the given tile.
+func (h *heatmap) add(col, row int, dt float64) {
+	if col < 0 || row < 0 || col >= h.cols || row >= h.rows {
+		return
+	}
+	h.dwell[row*h.cols+col] += dt
+}
+
+func (h *heatmap) data() *heatmapData {
+	dwell := make([]float64, len(h.dwell))
+	copy(dwell, h.dwell)
+	return &heatmapData{Cols: h.cols, Rows: h.rows, Dwell: dwell}
+}
+
+// restore loads saved dwell times if they were recorded on the same grid.
+func (h *heatmap) restore(d *heatmapData) {
+	if d.Cols != h.cols || d.Rows != h.rows || len(d.Dwell) != len(h.dwell) {
+		return
+	}
+	copy(h.dwell, d.Dwell)
+}
+
+// heatColor maps t in [0, 1] from green through yellow to red.
+func heatColor(t float64) (r, g, b float64) {
+	return min(2*t, 1), min(2*(1-t), 1), 0
+}
+
+// image renders the dwell times normalized by the largest one.
+func (h *heatmap) image() *ebiten.Image {
+	if h.img == nil {
+		h.img = ebiten.NewImage(h.cols, h.rows)
+		h.pixels = make([]byte, 4*h.cols*h.rows)
+	}
+	peak := 0.0
+	for _, d := range h.dwell {
+		peak = max(peak, d)
+	}
+	for i, d := range h.dwell {
+		var r, gr, b, a float64
+		if peak > 0 && d > 0 {
+			t := d / peak
+			r, gr, b = heatColor(t)
+			a = heatmapAlpha
+		}
+		// premultiplied alpha
+		h.pixels[4*i] = byte(r * a * 255)
+		h.pixels[4*i+1] = byte(gr * a * 255)
+		h.pixels[4*i+2] = byte(b * a * 255)
+		h.pixels[4*i+3] = byte(a * 255)
+	}
+	h.img.WritePixels(h.pixels)
+	return h.img
+}
+
+// updateHeatmap adds this frame to the tile the player is standing in.
+func (g *Game) updateHeatmap() {
+	if g.heatmap == nil {
+		return
+	}
+	col := int(g.px+float64(g.playerW)/2) / g.tileW
+	row := int(g.py+float64(g.playerH)/2) / g.tileH
+	g.heatmap.add(col, row, deltaTime())
+}
@@ -0,0 +1,49 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// drawHUD draws the text readouts in the top-left corner.
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	lineHeight := textLineHeight()
+	y := 8
+	if g.showGrid {
+		drawText(screen, "Cell "+g.playerGridCell(), 8, y)
+		y += lineHeight
+	}
+	if name := g.meta.regionAt(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2); name != "" {
+		drawText(screen, name, 8, y)
+		y += lineHeight
+	}
+	if g.room.name != "" {
+		drawText(screen, g.room.name, 8, y)
+		y += lineHeight
+	}
+	if text := g.markerText(); text != "" {
+		drawText(screen, text, 8, y)
+		y += lineHeight
+	}
+	if g.showNearest {
+		drawText(screen, g.nearestText(), 8, y)
+		y += lineHeight
+	}
+	if g.showExplored && g.fog != nil {
+		drawText(screen, g.exploreText(), 8, y)
+		y += lineHeight
+	}
+	if g.cfg.Speedrun.Goal != "" {
+		drawText(screen, g.speedrunText(), 8, y)
+		y += lineHeight
+	}
+	if len(g.chests) > 0 {
+		drawText(screen, g.chestText(), 8, y)
+		y += lineHeight
+	}
+	if g.showPixel && g.bg != nil {
+		drawText(screen, g.pixelText(), 8, y)
+		y += lineHeight
+	}
+	if g.showViewRect {
+		drawText(screen, g.viewRectText(), 8, y)
+		y += lineHeight
+	}
+}
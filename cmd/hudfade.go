@@ -0,0 +1,81 @@
+package main
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// active reports whether there was any input or movement this update.
+func (g *Game) active() bool {
+	if len(inpututil.AppendPressedKeys(nil)) > 0 || g.walkTime > 0 || len(g.route) > 0 {
+		return true
+	}
+	for _, b := range []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight, ebiten.MouseButtonMiddle} {
+		if ebiten.IsMouseButtonPressed(b) {
+			return true
+		}
+	}
+	if wx, wy := ebiten.Wheel(); wx != 0 || wy != 0 {
+		return true
+	}
+	cx, cy := ebiten.CursorPosition()
+	moved := cx != g.activityX || cy != g.activityY
+	g.activityX, g.activityY = cx, cy
+	return moved
+}
+
+// updateHUDFade tracks inactivity and fades the HUD out hud.fade seconds
+// after hud.hideAfter seconds without input, bringing it back at once on
+// any input.
+func (g *Game) updateHUDFade() {
+	cfg := g.cfg.HUD
+	if cfg.HideAfter <= 0 {
+		g.hudAlpha = 1
+		return
+	}
+	if g.active() {
+		g.idle = 0
+	} else {
+		g.idle += deltaTime()
+	}
+	over := g.idle - cfg.HideAfter
+	switch {
+	case over <= 0:
+		g.hudAlpha = 1
+	case cfg.Fade <= 0:
+		g.hudAlpha = 0
+	default:
+		g.hudAlpha = max(1-over/cfg.Fade, 0)
+	}
+}
+
+// hudLayer is where the HUD readouts, clock and toasts are drawn: the
+// screen itself while they are fully shown, otherwise an offscreen layer
+// composited at hudAlpha by drawHUDLayer.
+func (g *Game) hudLayer(screen *ebiten.Image) *ebiten.Image {
+	if g.hudAlpha >= 1 {
+		return screen
+	}
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if g.hud == nil || g.hud.Bounds().Dx() < sw || g.hud.Bounds().Dy() < sh {
+		if g.hud != nil {
+			g.hud.Deallocate()
+		}
+		g.hud = ebiten.NewImage(sw, sh)
+	}
+	layer := g.hud.SubImage(image.Rect(0, 0, sw, sh)).(*ebiten.Image)
+	layer.Clear()
+	return layer
+}
+
+// drawHUDLayer composites a faded HUD layer onto the screen.
+func (g *Game) drawHUDLayer(screen, layer *ebiten.Image) {
+	if layer == screen || g.hudAlpha <= 0 {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(float32(g.hudAlpha))
+	screen.DrawImage(layer, op)
+}
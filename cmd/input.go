@@ -0,0 +1,133 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// stick deflection below this is treated as centered, so drift on a
+	// worn stick doesn't count as input
+	stickDeadZone = 0.5
+	// seconds a menu direction is held before it starts repeating, and
+	// between repeats after that
+	menuRepeatDelay    = 0.4
+	menuRepeatInterval = 0.12
+)
+
+// keySource reports keyboard state. The game reads keys through it rather
+// than calling ebiten directly, so input handling can be driven by a
+// scripted source instead of a real keyboard.
+type keySource interface {
+	// justPressed reports whether key went down this update; it stays
+	// false while the key is held
+	justPressed(key ebiten.Key) bool
+	pressed(key ebiten.Key) bool
+}
+
+// ebitenKeys is the keySource backed by the real keyboard.
+type ebitenKeys struct{}
+
+func (ebitenKeys) justPressed(key ebiten.Key) bool { return inpututil.IsKeyJustPressed(key) }
+func (ebitenKeys) pressed(key ebiten.Key) bool     { return ebiten.IsKeyPressed(key) }
+
+// justPressed is the edge-triggered check every toggle goes through, so a
+// held key flips its toggle once rather than every update.
+func (g *Game) justPressed(key ebiten.Key) bool {
+	return g.keys.justPressed(key)
+}
+
+// menuAction is one navigation step, from the keyboard or any gamepad.
+type menuAction int
+
+const (
+	menuNone menuAction = iota
+	menuUp
+	menuDown
+	menuConfirm
+	menuCancel
+	menuPause
+)
+
+// menuInput turns keyboard and gamepad state into menu actions. Both
+// devices work at the same time.
+type menuInput struct {
+	// direction currently held (menuUp, menuDown or menuNone) and the
+	// seconds until it repeats
+	held     menuAction
+	repeatIn float64
+}
+
+// pausePressed reports Escape or Start on any gamepad.
+func pausePressed(keys keySource) bool {
+	if keys.justPressed(ebiten.KeyEscape) {
+		return true
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButtonCenterRight) {
+			return true
+		}
+	}
+	return false
+}
+
+// heldDirection is the menu direction held on the keyboard, a d-pad or a
+// stick pushed past the dead zone.
+func heldDirection(keys keySource) menuAction {
+	if keys.pressed(ebiten.KeyArrowUp) || keys.pressed(ebiten.KeyW) {
+		return menuUp
+	}
+	if keys.pressed(ebiten.KeyArrowDown) || keys.pressed(ebiten.KeyS) {
+		return menuDown
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		v := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+		if ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButtonLeftTop) || v < -stickDeadZone {
+			return menuUp
+		}
+		if ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButtonLeftBottom) || v > stickDeadZone {
+			return menuDown
+		}
+	}
+	return menuNone
+}
+
+// next returns this update's menu action.
+func (in *menuInput) next(keys keySource) menuAction {
+	if pausePressed(keys) {
+		return menuPause
+	}
+	if keys.justPressed(ebiten.KeyEnter) || keys.justPressed(ebiten.KeySpace) {
+		return menuConfirm
+	}
+	if keys.justPressed(ebiten.KeyBackspace) {
+		return menuCancel
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		// A and B on the standard layout
+		if inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButtonRightBottom) {
+			return menuConfirm
+		}
+		if inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButtonRightRight) {
+			return menuCancel
+		}
+	}
+
+	dir := heldDirection(keys)
+	if dir != in.held {
+		in.held, in.repeatIn = dir, menuRepeatDelay
+		return dir
+	}
+	if dir == menuNone {
+		return menuNone
+	}
+	in.repeatIn -= deltaTime()
+	if in.repeatIn > 0 {
+		return menuNone
+	}
+	in.repeatIn = menuRepeatInterval
+	return dir
+}
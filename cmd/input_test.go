@@ -0,0 +1,184 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fakeKeys is a scripted keySource: each call to hold sets the keys that
+// are down for the next update.
+type fakeKeys struct {
+	now, before map[ebiten.Key]bool
+}
+
+// hold starts a new update with exactly keys held down.
+func (f *fakeKeys) hold(keys ...ebiten.Key) {
+	f.before = f.now
+	f.now = make(map[ebiten.Key]bool, len(keys))
+	for _, k := range keys {
+		f.now[k] = true
+	}
+}
+
+func (f *fakeKeys) justPressed(key ebiten.Key) bool { return f.now[key] && !f.before[key] }
+func (f *fakeKeys) pressed(key ebiten.Key) bool     { return f.now[key] }
+
+func TestFakeKeys(t *testing.T) {
+	f := &fakeKeys{}
+	steps := []struct {
+		held         []ebiten.Key
+		just, pressd bool
+	}{
+		{nil, false, false},
+		{[]ebiten.Key{ebiten.KeyG}, true, true},
+		{[]ebiten.Key{ebiten.KeyG}, false, true},
+		{[]ebiten.Key{ebiten.KeyG, ebiten.KeyH}, false, true},
+		{nil, false, false},
+		{[]ebiten.Key{ebiten.KeyG}, true, true},
+	}
+	for i, s := range steps {
+		f.hold(s.held...)
+		if got := f.justPressed(ebiten.KeyG); got != s.just {
+			t.Errorf("update %d: justPressed = %v, want %v", i, got, s.just)
+		}
+		if got := f.pressed(ebiten.KeyG); got != s.pressd {
+			t.Errorf("update %d: pressed = %v, want %v", i, got, s.pressd)
+		}
+	}
+}
+
+// A toggle key held down for many updates flips its toggle once per press,
+// not once per update.
+func TestHeldKeyTogglesOnce(t *testing.T) {
+	g := newTestGame(1024, 1024)
+	keys := &fakeKeys{}
+	g.keys = keys
+	g.fog = nil
+	script := []struct {
+		key     ebiten.Key
+		updates int
+	}{
+		{ebiten.KeyU, 30}, // press and hold
+		{-1, 5},           // release
+		{ebiten.KeyU, 1},  // tap
+		{-1, 1},
+		{ebiten.KeyU, 90}, // long hold
+	}
+	flips := 0
+	for _, s := range script {
+		for range s.updates {
+			if s.key < 0 {
+				keys.hold()
+			} else {
+				keys.hold(s.key)
+			}
+			was := g.showExplored
+			g.updateExplore()
+			if g.showExplored != was {
+				flips++
+			}
+		}
+	}
+	if flips != 3 {
+		t.Errorf("three presses of U flipped the exploration readout %d times, want 3", flips)
+	}
+	if !g.showExplored {
+		t.Error("after three presses the exploration readout is off, want on")
+	}
+}
+
+// Holding Escape opens the pause menu once and leaves it open; it only
+// closes on the next press.
+func TestHeldEscapeOpensMenuOnce(t *testing.T) {
+	g := newTestGame(1024, 1024)
+	keys := &fakeKeys{}
+	g.keys = keys
+	for i := range 60 {
+		keys.hold(ebiten.KeyEscape)
+		g.updateMenu()
+		if g.menu == nil {
+			t.Fatalf("update %d: holding Escape closed the menu again", i)
+		}
+	}
+	keys.hold()
+	g.updateMenu()
+	keys.hold(ebiten.KeyEscape)
+	g.updateMenu()
+	if g.menu != nil {
+		t.Error("a second press of Escape left the menu open")
+	}
+}
+
+func TestMenuInputConfirmOnce(t *testing.T) {
+	keys := &fakeKeys{}
+	var in menuInput
+	confirms := 0
+	for range 60 {
+		keys.hold(ebiten.KeyEnter)
+		if in.next(keys) == menuConfirm {
+			confirms++
+		}
+	}
+	if confirms != 1 {
+		t.Errorf("holding Enter confirmed %d times, want 1", confirms)
+	}
+}
+
+// A held direction acts at once, then repeats after menuRepeatDelay and
+// every menuRepeatInterval after that.
+func TestMenuInputRepeat(t *testing.T) {
+	keys := &fakeKeys{}
+	var in menuInput
+	dt := deltaTime()
+	var at []float64
+	for i := range 120 {
+		keys.hold(ebiten.KeyArrowDown)
+		switch a := in.next(keys); a {
+		case menuDown:
+			at = append(at, float64(i)*dt)
+		case menuNone:
+		default:
+			t.Fatalf("update %d: holding down gave action %d", i, a)
+		}
+	}
+	if len(at) < 3 || at[0] != 0 {
+		t.Fatalf("holding down for two seconds acted at %v", at)
+	}
+	// each repeat fires on the first update at or past its due time
+	if got := at[1]; got < menuRepeatDelay-1e-9 || got > menuRepeatDelay+dt {
+		t.Errorf("first repeat at %.3fs, want %.3fs", got, menuRepeatDelay)
+	}
+	for k := 2; k < len(at); k++ {
+		if gap := at[k] - at[k-1]; gap < menuRepeatInterval-1e-9 || gap > menuRepeatInterval+dt {
+			t.Errorf("repeat %d came %.3fs after the one before, want %.3fs", k, gap, menuRepeatInterval)
+		}
+	}
+
+	// letting go stops the repeat, and pressing again acts at once
+	keys.hold()
+	if a := in.next(keys); a != menuNone {
+		t.Errorf("after release got action %d, want none", a)
+	}
+	keys.hold(ebiten.KeyArrowDown)
+	if a := in.next(keys); a != menuDown {
+		t.Errorf("pressing down again got action %d, want down", a)
+	}
+}
+
+// Switching direction acts on the new one at once rather than waiting out
+// the old repeat delay.
+func TestMenuInputSwitchDirection(t *testing.T) {
+	keys := &fakeKeys{}
+	var in menuInput
+	keys.hold(ebiten.KeyArrowUp)
+	if a := in.next(keys); a != menuUp {
+		t.Fatalf("pressing up got action %d", a)
+	}
+	keys.hold(ebiten.KeyArrowUp)
+	in.next(keys)
+	keys.hold(ebiten.KeyS)
+	if a := in.next(keys); a != menuDown {
+		t.Errorf("switching to S got action %d, want down", a)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// colorAt is the map color at a world pixel. It reads the decoded source
+// image, or the GPU copy for lazy parts that have none, and is false
+// outside the parts or over a lazy part that isn't loaded.
+func (b *background) colorAt(x, y int) (color.Color, bool) {
+	if b.wrap {
+		x, y = mod(x, b.w), mod(y, b.h)
+	}
+	if b.tile {
+		x, y = mod(x, b.pw), mod(y, b.ph)
+	}
+	for _, p := range b.parts {
+		if x < p.x || y < p.y || x >= p.x+p.w || y >= p.y+p.h {
+			continue
+		}
+		if p.src != nil {
+			o := p.src.Bounds().Min
+			return p.src.At(o.X+x-p.x, o.Y+y-p.y), true
+		}
+		if p.img != nil {
+			return p.img.At(x-p.x, y-p.y), true
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+func mod(a, n int) int {
+	if n <= 0 {
+		return a
+	}
+	return (a%n + n) % n
+}
+
+// pixelText is the HUD line for the map pixel under the cursor, its color
+// as #rrggbbaa.
+func (g *Game) pixelText() string {
+	wx, wy := g.cursorWorld()
+	x, y := int(math.Floor(wx)), int(math.Floor(wy))
+	c, ok := g.bg.colorAt(x, y)
+	if !ok {
+		return fmt.Sprintf("Pixel %d,%d: none", x, y)
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("Pixel %d,%d: #%02x%02x%02x%02x", x, y, n.R, n.G, n.B, n.A)
+}
+
+// viewRectText is the HUD line for the world rectangle visible in the map
+// area, from its corners through screenToWorld, for checking the scaling
+// and clamping.
+func (g *Game) viewRectText() string {
+	area := g.mapArea(g.screenW, g.screenH)
+	x0, y0 := g.screenToWorld(float64(area.Min.X), float64(area.Min.Y))
+	x1, y1 := g.screenToWorld(float64(area.Max.X), float64(area.Max.Y))
+	return fmt.Sprintf("View %.1f,%.1f to %.1f,%.1f (%.1fx%.1f)", x0, y0, x1, y1, x1-x0, y1-y0)
+}
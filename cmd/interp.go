@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// interpMaxJump is the furthest, in world pixels, the player or camera can
+// move in one update and still be interpolated; longer moves are
+// teleports and are drawn where they land.
+const interpMaxJump = 256
+
+// tickState is the part of the game Draw interpolates between updates.
+type tickState struct {
+	px, py, vx, vy float64
+}
+
+func (g *Game) tickState() tickState {
+	return tickState{px: g.px, py: g.py, vx: g.vx, vy: g.vy}
+}
+
+func (g *Game) setTickState(s tickState) {
+	g.px, g.py, g.vx, g.vy = s.px, s.py, s.vx, s.vy
+}
+
+// startTick remembers the state the last update left, at the start of
+// the next one, so Draw can blend from it toward what this update makes.
+func (g *Game) startTick() {
+	g.prevTick = g.tickState()
+	g.tickAt = time.Now()
+}
+
+// interpFactor is how far through an update interval of 1/tps seconds a
+// frame drawn since after that update falls, from 0 to 1.
+func interpFactor(since time.Duration, tps float64) float64 {
+	if tps <= 0 {
+		return 1
+	}
+	return min(max(since.Seconds()*tps, 0), 1)
+}
+
+// lerpTick blends two states, keeping b for whatever jumped too far.
+func lerpTick(a, b tickState, t float64) tickState {
+	lerp := func(x, y float64) float64 { return x + (y-x)*t }
+	out := b
+	if math.Hypot(b.px-a.px, b.py-a.py) <= interpMaxJump {
+		out.px, out.py = lerp(a.px, b.px), lerp(a.py, b.py)
+	}
+	if math.Hypot(b.vx-a.vx, b.vy-a.vy) <= interpMaxJump {
+		out.vx, out.vy = lerp(a.vx, b.vx), lerp(a.vy, b.vy)
+	}
+	return out
+}
+
+// drawInterpolated draws the world with the player and camera blended
+// between the last two updates, so motion stays smooth when the update
+// rate is below the frame rate.
+func (g *Game) drawInterpolated(draw func()) {
+	if !g.cfg.Perf.Interpolate || g.tickAt.IsZero() {
+		draw()
+		return
+	}
+	cur := g.tickState()
+	g.setTickState(lerpTick(g.prevTick, cur, interpFactor(time.Since(g.tickAt), 1/deltaTime())))
+	draw()
+	g.setTickState(cur)
+}
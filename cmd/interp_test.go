@@ -0,0 +1,90 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestInterpFactor(t *testing.T) {
+	tests := []struct {
+		since time.Duration
+		tps   float64
+		want  float64
+	}{
+		{0, 30, 0},
+		{time.Second / 60, 30, 0.5},
+		{time.Second / 30, 30, 1},
+		{time.Second, 30, 1},
+		{-time.Millisecond, 30, 0},
+		{10 * time.Millisecond, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := interpFactor(tt.since, tt.tps); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("interpFactor(%v, %v) = %v, want %v", tt.since, tt.tps, got, tt.want)
+		}
+	}
+}
+
+func TestLerpTick(t *testing.T) {
+	a := tickState{px: 0, py: 0, vx: 100, vy: 100}
+	b := tickState{px: 10, py: -20, vx: 110, vy: 100}
+	tests := []struct {
+		t    float64
+		want tickState
+	}{
+		{0, a},
+		{1, b},
+		{0.5, tickState{5, -10, 105, 100}},
+	}
+	for _, tt := range tests {
+		if got := lerpTick(a, b, tt.t); got != tt.want {
+			t.Errorf("lerpTick at %v = %+v, want %+v", tt.t, got, tt.want)
+		}
+	}
+}
+
+// A teleport or a camera jump is drawn where it lands, while the other
+// half of the state still blends.
+func TestLerpTickJumps(t *testing.T) {
+	a := tickState{px: 0, py: 0, vx: 0, vy: 0}
+	b := tickState{px: 1000, py: 0, vx: 8, vy: 6}
+	got := lerpTick(a, b, 0.5)
+	if want := (tickState{1000, 0, 4, 3}); got != want {
+		t.Errorf("player teleport: lerpTick = %+v, want %+v", got, want)
+	}
+	b = tickState{px: 8, py: 6, vx: 0, vy: -interpMaxJump - 1}
+	got = lerpTick(a, b, 0.5)
+	if want := (tickState{4, 3, 0, -interpMaxJump - 1}); got != want {
+		t.Errorf("camera jump: lerpTick = %+v, want %+v", got, want)
+	}
+	// exactly the limit still blends
+	b = tickState{px: interpMaxJump}
+	if got := lerpTick(a, b, 0.5); got.px != interpMaxJump/2 {
+		t.Errorf("a move of exactly interpMaxJump drawn at %v, want %v", got.px, interpMaxJump/2)
+	}
+}
+
+// Drawing interpolated leaves the game state as the update left it.
+func TestDrawInterpolatedRestoresState(t *testing.T) {
+	g := newTestGame(1000, 1000)
+	g.cfg.Perf.Interpolate = true
+	g.px, g.py, g.vx, g.vy = 0, 0, 0, 0
+	g.startTick()
+	g.tickAt = time.Now().Add(-time.Hour)
+	g.prevTick = tickState{px: -10, py: -10, vx: -10, vy: -10}
+	// an hour since the update, so the frame shows the final state
+	var drawn tickState
+	g.drawInterpolated(func() { drawn = g.tickState() })
+	if drawn != (tickState{}) {
+		t.Errorf("drawn at %+v, want the current state", drawn)
+	}
+	g.tickAt = time.Now()
+	g.drawInterpolated(func() { drawn = g.tickState() })
+	if drawn.px > -9 {
+		t.Errorf("drawn at %+v right after the update, want close to the previous state", drawn)
+	}
+	if g.tickState() != (tickState{}) {
+		t.Errorf("state after drawing is %+v, want it restored", g.tickState())
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"image"
+	"log/slog"
+	"math"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// partLoader decodes the parts of a lazy background on other goroutines
+// and hands them back to the game goroutine, which alone creates their
+// GPU images.
+type partLoader struct {
+	// parts already requested, loaded or not, and those that failed to
+	// decode; failed parts are not requested again
+	requested, failed []bool
+	// decoded parts waiting to be uploaded; sized to hold every part so a
+	// decoder never blocks even if the background was dropped meanwhile
+	loaded chan partLoad
+	// frames a part was in the main view and ready, or still missing,
+	// counted once per part a frame
+	hits, misses int
+}
+
+type partLoad struct {
+	i   int
+	src image.Image
+	err error
+}
+
+// imageSize reads just the dimensions of an image file.
+func imageSize(path string) (image.Point, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Point{}, err
+	}
+	defer f.Close()
+	c, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return image.Point{}, err
+	}
+	return image.Pt(c.Width, c.Height), nil
+}
+
+// newLazyBackground lays out parts that are loaded later by prefetch.
+func newLazyBackground(paths []string, sizes, offsets []image.Point) *background {
+	bg := &background{lazy: &partLoader{
+		requested: make([]bool, len(paths)),
+		failed:    make([]bool, len(paths)),
+		loaded:    make(chan partLoad, len(paths)),
+	}}
+	for i, path := range paths {
+		off, size := offsets[i], sizes[i]
+		bg.parts = append(bg.parts, mapPart{path: path, x: off.X, y: off.Y, w: size.X, h: size.Y})
+		bg.w = max(bg.w, off.X+size.X)
+		bg.h = max(bg.h, off.Y+size.Y)
+	}
+	return bg
+}
+
+// prefetch starts decoding every part within margin parts of the world
+// rectangle (x0, y0)-(x1, y1). Tiled and wrapping maps repeat every part
+// on screen, so they request them all.
+func (b *background) prefetch(x0, y0, x1, y1 float64, margin int) {
+	if b.lazy == nil {
+		return
+	}
+	all := b.tile || b.wrap
+	for i := range b.parts {
+		p := &b.parts[i]
+		if b.lazy.requested[i] {
+			continue
+		}
+		mx, my := float64(margin*p.w), float64(margin*p.h)
+		near := float64(p.x+p.w) > x0-mx && float64(p.y+p.h) > y0-my &&
+			float64(p.x) < x1+mx && float64(p.y) < y1+my
+		if !all && !near {
+			continue
+		}
+		b.lazy.requested[i] = true
+		go func(i int, path string) {
+			src, err := decodeImage(path)
+			b.lazy.loaded <- partLoad{i: i, src: src, err: err}
+		}(i, p.path)
+	}
+}
+
+// receive uploads the parts decoded since the last call and reports
+// whether there were any.
+func (b *background) receive() bool {
+	if b.lazy == nil {
+		return false
+	}
+	got := false
+	for {
+		select {
+		case l := <-b.lazy.loaded:
+			p := &b.parts[l.i]
+			if l.err != nil {
+				slog.Warn("failed to load map part", "path", p.path, "err", l.err)
+				b.lazy.failed[l.i] = true
+				continue
+			}
+			p.img = ebiten.NewImageFromImage(l.src)
+			if b.mipLevels > 0 {
+				p.buildMips(b.mipLevels, b.mipFilter, l.src)
+			}
+			got = true
+			slog.Debug("loaded map part", "path", p.path, "hits", b.lazy.hits, "misses", b.lazy.misses)
+		default:
+			return got
+		}
+	}
+}
+
+// updateLazy loads the parts around the visible area of a lazy map.
+func (g *Game) updateLazy() {
+	if g.bg == nil || g.bg.lazy == nil {
+		return
+	}
+	area := g.mapArea(g.screenW, g.screenH)
+	x0, y0 := g.screenToWorld(float64(area.Min.X), float64(area.Min.Y))
+	x1, y1 := g.screenToWorld(float64(area.Max.X), float64(area.Max.Y))
+	// the overview fits the whole map on screen
+	if g.overview {
+		x0, y0, x1, y1 = math.Inf(-1), math.Inf(-1), math.Inf(1), math.Inf(1)
+	}
+	g.bg.prefetch(x0, y0, x1, y1, g.cfg.Map.Lazy.Prefetch)
+	if g.bg.receive() {
+		// rebuild the minimap thumbnail with the new parts
+		g.minimapOf = nil
+	}
+}
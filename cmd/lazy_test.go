@@ -0,0 +1,128 @@
+package main
+
+import (
+	"image"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// receiveAll keeps receiving until want parts have come back or a second
+// has passed.
+func receiveAll(t *testing.T, b *background, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		b.receive()
+		done := 0
+		for i := range b.parts {
+			if b.lazy.failed[i] || b.parts[i].img != nil {
+				done++
+			}
+		}
+		if done >= want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d parts came back", done, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// A part that fails to decode is marked failed: it no longer counts as
+// loading and is not requested again.
+func TestLazyDecodeFailure(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "missing-0.png"), filepath.Join(dir, "missing-1.png")}
+	sizes := []image.Point{{100, 100}, {100, 100}}
+	offsets := []image.Point{{0, 0}, {100, 0}}
+	b := newLazyBackground(paths, sizes, offsets)
+
+	// only the first part is near the view
+	b.prefetch(0, 0, 50, 50, 0)
+	if _, pending, _ := b.residentParts(); pending != 1 {
+		t.Fatalf("after prefetch %d parts pending, want 1", pending)
+	}
+	receiveAll(t, b, 1)
+	if !b.lazy.failed[0] || b.lazy.failed[1] {
+		t.Fatalf("failed = %v, want only the first part", b.lazy.failed)
+	}
+	resident, pending, _ := b.residentParts()
+	if resident != 0 || pending != 0 {
+		t.Errorf("after the failure %d resident, %d pending, want 0 and 0", resident, pending)
+	}
+
+	// prefetching the whole map again requests only the untried part
+	b.prefetch(0, 0, 200, 100, 0)
+	if _, pending, _ := b.residentParts(); pending != 1 {
+		t.Errorf("second prefetch left %d parts pending, want 1", pending)
+	}
+	receiveAll(t, b, 2)
+	select {
+	case l := <-b.lazy.loaded:
+		t.Errorf("part %d was decoded again", l.i)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestPrefetchMargin(t *testing.T) {
+	paths := make([]string, 5)
+	sizes := make([]image.Point, 5)
+	offsets := make([]image.Point, 5)
+	for i := range paths {
+		paths[i] = filepath.Join(t.TempDir(), "missing.png")
+		sizes[i] = image.Pt(100, 100)
+		offsets[i] = image.Pt(100*i, 0)
+	}
+	b := newLazyBackground(paths, sizes, offsets)
+	b.prefetch(210, 10, 290, 90, 1)
+	want := []bool{false, true, true, true, false}
+	for i, r := range b.lazy.requested {
+		if r != want[i] {
+			t.Errorf("part %d requested = %v, want %v", i, r, want[i])
+		}
+	}
+	receiveAll(t, b, 3)
+}
+
+// Each part in view counts once, however many wrapped copies or tiles of
+// it the view shows.
+func TestCountVisible(t *testing.T) {
+	tests := []struct {
+		name          string
+		wrap, tile    bool
+		sb            image.Rectangle
+		scale, tx, ty float64
+		hits, misses  int
+	}{
+		{"one loaded part", false, false, image.Rect(0, 0, 50, 50), 1, 0, 0, 1, 0},
+		{"one missing part", false, false, image.Rect(0, 0, 50, 50), 1, -120, 0, 0, 1},
+		{"whole map", false, false, image.Rect(0, 0, 640, 480), 1, 0, 0, 1, 3},
+		{"edges only touch", false, false, image.Rect(0, 0, 100, 100), 1, 0, 0, 1, 0},
+		{"off the map", false, false, image.Rect(0, 0, 640, 480), 1, -1000, 0, 0, 0},
+		{"scaled", false, false, image.Rect(0, 0, 300, 150), 2, 0, 0, 1, 1},
+		{"sub-image bounds", false, false, image.Rect(100, 0, 200, 50), 1, 0, 0, 0, 1},
+		{"across the wrap", true, false, image.Rect(0, 0, 100, 50), 1, -150, 0, 1, 1},
+		{"wrapped copies", true, false, image.Rect(0, 0, 1000, 1000), 1, 0, 0, 1, 3},
+		{"tiles", false, true, image.Rect(0, 0, 1000, 1000), 1, 0, 0, 1, 3},
+		{"inside one tile", false, true, image.Rect(0, 0, 40, 30), 1, -250, -410, 1, 0},
+	}
+	for _, tt := range tests {
+		sizes := []image.Point{{100, 100}, {100, 100}, {100, 100}, {100, 100}}
+		offsets := []image.Point{{0, 0}, {100, 0}, {0, 100}, {100, 100}}
+		b := newLazyBackground(make([]string, 4), sizes, offsets)
+		b.parts[0].img = new(ebiten.Image)
+		b.wrap = tt.wrap
+		if tt.tile {
+			b.tile, b.pw, b.ph = true, b.w, b.h
+			b.w, b.h = 1000, 1000
+		}
+		b.countVisible(tt.sb, tt.scale, tt.tx, tt.ty)
+		if b.lazy.hits != tt.hits || b.lazy.misses != tt.misses {
+			t.Errorf("%s: hits, misses = %d, %d, want %d, %d", tt.name, b.lazy.hits, b.lazy.misses, tt.hits, tt.misses)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	partLoadedColor  = color.RGBA{0x40, 0xc0, 0x40, 0xff}
+	partPendingColor = color.RGBA{0xe0, 0xc0, 0x30, 0xff}
+	partIdleColor    = color.RGBA{0x50, 0x50, 0x50, 0xff}
+	partFailedColor  = color.RGBA{0xc0, 0x30, 0x30, 0xff}
+)
+
+// lazyStats is what the streaming overlay shows, with the draw counters
+// turned into per-second rates once a second.
+type lazyStats struct {
+	clock                      float64
+	lastHits, lastMisses       int
+	hitsPerSec, missesPerSec   int
+	resident, pending, texture int
+}
+
+// residentParts counts the parts uploaded and the parts requested but not
+// yet loaded or failed, and estimates the texture memory of the uploaded ones and
+// their LOD levels at four bytes a pixel.
+func (b *background) residentParts() (resident, pending, bytes int) {
+	for i, p := range b.parts {
+		if p.img == nil {
+			if b.lazy != nil && b.lazy.requested[i] && !b.lazy.failed[i] {
+				pending++
+			}
+			continue
+		}
+		resident++
+		levels := p.mips
+		if len(levels) == 0 {
+			levels = []*ebiten.Image{p.img}
+		}
+		for _, m := range levels {
+			bytes += 4 * m.Bounds().Dx() * m.Bounds().Dy()
+		}
+	}
+	return resident, pending, bytes
+}
+
+// updateLazyStats toggles the streaming overlay with F11 (debug only) and
+// refreshes its numbers while it is shown.
+func (g *Game) updateLazyStats() {
+	if g.justPressed(ebiten.KeyF11) && g.cfg.Debug && g.bg != nil && g.bg.lazy != nil {
+		g.showLazyStats = !g.showLazyStats
+	}
+	if !g.showLazyStats || g.bg == nil || g.bg.lazy == nil {
+		return
+	}
+	s := &g.lazyStats
+	s.resident, s.pending, s.texture = g.bg.residentParts()
+	s.clock += deltaTime()
+	if s.clock >= 1 {
+		s.hitsPerSec = int(float64(g.bg.lazy.hits-s.lastHits) / s.clock)
+		s.missesPerSec = int(float64(g.bg.lazy.misses-s.lastMisses) / s.clock)
+		s.lastHits, s.lastMisses = g.bg.lazy.hits, g.bg.lazy.misses
+		s.clock = 0
+	}
+}
+
+// drawLazyStats draws the streaming numbers and a diagram of the parts,
+// loaded, loading, failed or not requested, with the view outlined, stacked
+// above the frame graph's spot in the bottom-right corner.
+func (g *Game) drawLazyStats(screen *ebiten.Image) {
+	if !g.showLazyStats || g.bg == nil || g.bg.lazy == nil {
+		return
+	}
+	const (
+		width = frameSamples * 2
+		// room left below for the frame graph
+		graphH = 60 + 8
+		pad    = 4
+		diagH  = 80
+	)
+	lineH := textLineHeight()
+	s := g.lazyStats
+	lines := []string{
+		fmt.Sprintf("Parts %d/%d resident, %d loading", s.resident, len(g.bg.parts), s.pending),
+		fmt.Sprintf("Hits %d/s  misses %d/s", s.hitsPerSec, s.missesPerSec),
+		fmt.Sprintf("Texture ~%.1f MiB", float64(s.texture)/(1<<20)),
+	}
+	h := 2*pad + lineH*len(lines) + diagH + pad
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	x0, y0 := sw-width-8, sh-graphH-8-h
+	vector.FillRect(screen, float32(x0), float32(y0), width, float32(h), frameBackColor, false)
+	for i, l := range lines {
+		drawText(screen, l, x0+pad, y0+pad+i*lineH)
+	}
+
+	// the world scaled into the diagram box, keeping its aspect
+	bw, bh := g.bg.size()
+	boxW, boxH := float64(width-2*pad), float64(diagH)
+	k := min(boxW/float64(bw), boxH/float64(bh))
+	dx := float64(x0+pad) + (boxW-float64(bw)*k)/2
+	dy := float64(y0+pad+lineH*len(lines)) + (boxH-float64(bh)*k)/2
+	for i, p := range g.bg.parts {
+		c := partIdleColor
+		switch {
+		case p.img != nil:
+			c = partLoadedColor
+		case g.bg.lazy.failed[i]:
+			c = partFailedColor
+		case g.bg.lazy.requested[i]:
+			c = partPendingColor
+		}
+		x, y := float32(dx+float64(p.x)*k), float32(dy+float64(p.y)*k)
+		w, ph := float32(float64(p.w)*k), float32(float64(p.h)*k)
+		vector.FillRect(screen, x, y, max(w-1, 1), max(ph-1, 1), c, false)
+	}
+	area := g.mapArea(g.screenW, g.screenH)
+	vx0, vy0 := g.screenToWorld(float64(area.Min.X), float64(area.Min.Y))
+	vx1, vy1 := g.screenToWorld(float64(area.Max.X), float64(area.Max.Y))
+	vector.StrokeRect(screen, float32(dx+vx0*k), float32(dy+vy0*k), float32((vx1-vx0)*k), float32((vy1-vy0)*k), 1, color.White, false)
+}
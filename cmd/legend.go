@@ -0,0 +1,91 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// legendEntry is one row of the legend: an icon drawn centered at (x, y)
+// and what it means.
+type legendEntry struct {
+	icon func(dst *ebiten.Image, x, y float32)
+	text string
+}
+
+// legendEntries lists the icon types the map can currently show, drawn
+// with the same colors and shapes as on the map so the legend can't drift
+// from them.
+func (g *Game) legendEntries() []legendEntry {
+	entries := []legendEntry{
+		{func(dst *ebiten.Image, x, y float32) {
+			vector.FillCircle(dst, x, y, 5, markerColor, true)
+			vector.StrokeCircle(dst, x, y, 5, 1, color.Black, true)
+		}, "Marker (right-click)"},
+		{func(dst *ebiten.Image, x, y float32) {
+			vector.StrokeCircle(dst, x, y, 6, 2, nearestColor, true)
+		}, "Nearest place (N)"},
+	}
+	if g.cfg.Notes.Enabled || len(g.notes) > 0 {
+		entries = append(entries, legendEntry{drawNoteIcon, "Note (Ctrl+right-click)"})
+	}
+	if g.cfg.Trail.Interval > 0 || len(g.trail) > 0 {
+		entries = append(entries, legendEntry{func(dst *ebiten.Image, x, y float32) {
+			vector.FillCircle(dst, x, y, 2.5, trailColor, true)
+		}, "Trail"})
+	}
+	if len(g.chests) > 0 {
+		label := "Chest (walk over it)"
+		if !g.cfg.Chests.AutoCollect {
+			label = "Chest (Space to open)"
+		}
+		entries = append(entries, legendEntry{drawChestIcon, label})
+	}
+	if g.cfg.Ping.Duration > 0 {
+		entries = append(entries, legendEntry{func(dst *ebiten.Image, x, y float32) {
+			c := color.RGBA{0xff, 0x40, 0x40, 0xff}
+			if g.pingColor != nil {
+				c = *g.pingColor
+			}
+			vector.FillCircle(dst, x, y, 2, c, true)
+			vector.StrokeCircle(dst, x, y, 6, 1, c, true)
+		}, "Ping (Q)"})
+	}
+	return entries
+}
+
+// drawLegend draws the legend panel in its configured corner.
+func (g *Game) drawLegend(screen *ebiten.Image) {
+	if !g.showLegend {
+		return
+	}
+	const (
+		margin, pad = 8, 8
+		iconW       = 20
+	)
+	lineH := textLineHeight()
+	entries := g.legendEntries()
+	textW := 0
+	for _, e := range entries {
+		textW = max(textW, textWidth(e.text))
+	}
+	w, h := 2*pad+iconW+textW, 2*pad+lineH*len(entries)
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	x, y := margin, margin
+	switch g.cfg.Legend.Corner {
+	case "top-right":
+		x = sw - margin - w
+	case "bottom-left":
+		y = sh - margin - h
+	case "bottom-right":
+		x, y = sw-margin-w, sh-margin-h
+	}
+	a := uint8(max(min(g.cfg.Legend.Opacity, 1), 0) * 0xff)
+	vector.FillRect(screen, float32(x), float32(y), float32(w), float32(h), color.RGBA{A: a}, false)
+	for i, e := range entries {
+		ry := y + pad + i*lineH
+		e.icon(screen, float32(x+pad+iconW/2-2), float32(ry+lineH/2))
+		drawText(screen, e.text, x+pad+iconW, ry)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"image/color"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// locateRingDelay is the fraction of the animation each ring starts after
+// the previous one.
+const locateRingDelay = 0.25
+
+// locate starts the "locate me" rings around player one.
+func (g *Game) locate() {
+	g.locateLeft = g.cfg.Locate.Duration
+}
+
+func (g *Game) updateLocate() {
+	if g.locateLeft > 0 {
+		g.locateLeft -= deltaTime()
+	}
+}
+
+// drawLocate draws rings expanding from the player and fading out.
+func (g *Game) drawLocate(screen *ebiten.Image) {
+	cfg := g.cfg.Locate
+	if g.locateLeft <= 0 || cfg.Duration <= 0 {
+		return
+	}
+	if g.locateColor == nil {
+		c, err := parseHexColor(cfg.Color)
+		if err != nil {
+			slog.Warn("invalid locate color", "err", err)
+			c = color.RGBA{0xff, 0xff, 0xff, 0xff}
+		}
+		g.locateColor = &c
+	}
+	cx, cy := g.worldToScreen(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2)
+	t := 1 - g.locateLeft/cfg.Duration
+	// many rings start closer together so the last still has time to grow
+	delay := locateRingDelay
+	if cfg.Rings > 1 {
+		delay = min(delay, 0.5/float64(cfg.Rings-1))
+	}
+	// each ring runs over the part of the animation after its delay
+	span := 1 - delay*float64(cfg.Rings-1)
+	for i := range cfg.Rings {
+		p := (t - delay*float64(i)) / span
+		if p <= 0 || p >= 1 {
+			continue
+		}
+		c := *g.locateColor
+		a := 1 - p
+		c = color.RGBA{uint8(float64(c.R) * a), uint8(float64(c.G) * a), uint8(float64(c.B) * a), uint8(float64(c.A) * a)}
+		vector.StrokeCircle(screen, float32(cx), float32(cy), float32(p*cfg.Radius), 2, c, true)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+// lodLevel picks the background LOD level for an on-screen scale, starting
+// from the current level cur. thresholds[k] is the scale below which level
+// k+1 is used; hysteresis widens each threshold by that fraction in the
+// direction of travel so a zoom hovering near it doesn't flip back and
+// forth.
+func lodLevel(cur int, scale float64, thresholds []float64, hysteresis float64) int {
+	cur = min(max(cur, 0), len(thresholds))
+	for cur < len(thresholds) && scale < thresholds[cur]*(1-hysteresis) {
+		cur++
+	}
+	for cur > 0 && scale > thresholds[cur-1]*(1+hysteresis) {
+		cur--
+	}
+	return cur
+}
+
+// updateLOD switches the background level for the current zoom, starting
+// a crossfade when it changes.
+func (g *Game) updateLOD() {
+	cfg := g.cfg.Map.LOD
+	if g.lodFade > 0 {
+		g.lodFade -= deltaTime()
+	}
+	if !cfg.Enabled || g.screenW <= 0 || g.screenH <= 0 {
+		return
+	}
+	scale, _, _ := g.worldTransform(g.screenW, g.screenH)
+	next := lodLevel(g.lod, scale, cfg.Thresholds, cfg.Hysteresis)
+	if next == g.lod {
+		return
+	}
+	g.lodPrev, g.lod = g.lod, next
+	g.lodFade = cfg.Fade
+}
+
+// lodAlpha is the opacity of the new level during a LOD crossfade.
+func (g *Game) lodAlpha() float32 {
+	if g.cfg.Map.LOD.Fade <= 0 {
+		return 1
+	}
+	return float32(min(max(1-g.lodFade/g.cfg.Map.LOD.Fade, 0), 1))
+}
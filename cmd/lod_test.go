@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestLODLevel(t *testing.T) {
+	thresholds := []float64{0.5, 0.25}
+	tests := []struct {
+		name       string
+		cur        int
+		scale      float64
+		thresholds []float64
+		hysteresis float64
+		want       int
+	}{
+		{"full scale", 0, 1, thresholds, 0, 0},
+		{"at the first threshold", 0, 0.5, thresholds, 0, 0},
+		{"just below the first threshold", 0, 0.4999, thresholds, 0, 1},
+		{"at the second threshold", 1, 0.25, thresholds, 0, 1},
+		{"just below the second threshold", 1, 0.2499, thresholds, 0, 2},
+		{"back to level 1 at the first threshold", 1, 0.5, thresholds, 0, 1},
+		{"back to level 0 just above it", 1, 0.5001, thresholds, 0, 0},
+		{"skips levels zooming out", 0, 0.1, thresholds, 0, 2},
+		{"skips levels zooming in", 2, 2, thresholds, 0, 0},
+		{"hysteresis delays the step down", 0, 0.46, thresholds, 0.1, 0},
+		{"hysteresis step down", 0, 0.449, thresholds, 0.1, 1},
+		{"hysteresis delays the step up", 1, 0.54, thresholds, 0.1, 1},
+		{"hysteresis step up", 1, 0.551, thresholds, 0.1, 0},
+		{"out of range level clamped", 7, 0.3, thresholds, 0, 1},
+		{"negative level clamped", -3, 1, thresholds, 0, 0},
+		{"no thresholds", 0, 0.01, nil, 0, 0},
+		{"no thresholds, stale level", 2, 0.01, nil, 0.1, 0},
+	}
+	for _, tt := range tests {
+		if got := lodLevel(tt.cur, tt.scale, tt.thresholds, tt.hysteresis); got != tt.want {
+			t.Errorf("%s: lodLevel(%d, %v, %v, %v) = %d, want %d", tt.name, tt.cur, tt.scale, tt.thresholds, tt.hysteresis, got, tt.want)
+		}
+	}
+}
+
+// A zoom wobbling around a threshold within the hysteresis band keeps the
+// level it started on instead of flickering between two.
+func TestLODLevelNoFlicker(t *testing.T) {
+	thresholds := []float64{0.5, 0.25}
+	for _, start := range []int{0, 1} {
+		cur := start
+		for i := range 100 {
+			scale := 0.48
+			if i%2 == 1 {
+				scale = 0.52
+			}
+			next := lodLevel(cur, scale, thresholds, 0.1)
+			if next != cur {
+				t.Fatalf("starting at level %d, scale %v switched to level %d on update %d", start, scale, next, i)
+			}
+			cur = next
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// a log file larger than this at startup is rotated
+	logFileMaxSize = 1 << 20
+	// rotated log files kept next to the current one
+	logFileBackups = 3
+)
+
+// parseLogLevel parses debug, info, warn or error.
+func parseLogLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return level, nil
+}
+
+// rotateLog shifts path to path.1, path.1 to path.2 and so on once path has
+// grown past logFileMaxSize, dropping the oldest.
+func rotateLog(path string) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < logFileMaxSize {
+		return nil
+	}
+	for i := logFileBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	return os.Rename(path, path+".1")
+}
+
+// setupLogging installs the default slog logger at level, writing to
+// stderr and, with toFile, also to a log file in the data directory. The
+// returned function closes the file.
+func setupLogging(level slog.Level, toFile bool) (func(), error) {
+	var w io.Writer = os.Stderr
+	closeFn := func() {}
+	var fileErr error
+	if toFile {
+		f, err := openLogFile()
+		if err != nil {
+			fileErr = err
+		} else {
+			w = io.MultiWriter(os.Stderr, f)
+			closeFn = func() { f.Close() }
+		}
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})))
+	return closeFn, fileErr
+}
+
+func openLogFile() (*os.File, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "hyrule-map-explorer.log")
+	if err := rotateLog(path); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
+// fatal logs an error and exits; used for assets the game can't run
+// without.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// magnifierOffset is how far from the cursor, in screen pixels, the
+// loupe's edge sits so it doesn't cover what is being pointed at.
+const magnifierOffset = 24
+
+// loupeImages returns the offscreen loupe and its circular mask, built
+// for the configured size.
+func (g *Game) loupeImages() (loupe, mask *ebiten.Image) {
+	size := max(g.cfg.Magnifier.Size, 16)
+	if g.loupe == nil || g.loupe.Bounds().Dx() != size {
+		g.loupe = ebiten.NewImage(size, size)
+		g.loupeMask = ebiten.NewImage(size, size)
+		r := float32(size) / 2
+		vector.FillCircle(g.loupeMask, r, r, r, color.White, true)
+	}
+	return g.loupe, g.loupeMask
+}
+
+// drawMagnifier shows the map around the cursor magnified in a circular
+// inset beside it.
+func (g *Game) drawMagnifier(screen *ebiten.Image) {
+	if !g.showMagnifier || g.bg == nil {
+		return
+	}
+	loupe, mask := g.loupeImages()
+	size := float64(loupe.Bounds().Dx())
+	cx, cy := ebiten.CursorPosition()
+	// world point under the cursor, drawn at the loupe's center
+	wx, wy := g.screenToWorld(float64(cx), float64(cy))
+	scale, _, _ := g.worldTransform(g.screenW, g.screenH)
+	ls := scale * max(g.cfg.Magnifier.Magnification, 1)
+	loupe.Clear()
+	g.bg.draw(loupe, 0, ls, size/2-wx*ls, size/2-wy*ls, 0, 1, g.filter)
+	maskOp := &ebiten.DrawImageOptions{}
+	maskOp.Blend = ebiten.BlendDestinationIn
+	loupe.DrawImage(mask, maskOp)
+
+	// beside the cursor, flipped to the other side near the screen edges
+	x := float64(cx) + magnifierOffset
+	if x+size > float64(g.screenW) {
+		x = float64(cx) - magnifierOffset - size
+	}
+	y := float64(cy) + magnifierOffset
+	if y+size > float64(g.screenH) {
+		y = float64(cy) - magnifierOffset - size
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(loupe, op)
+	r := float32(size) / 2
+	vector.StrokeCircle(screen, float32(x)+r, float32(y)+r, r, 2, color.White, true)
+	// crosshair on the magnified point
+	vector.StrokeLine(screen, float32(x)+r-6, float32(y)+r, float32(x)+r+6, float32(y)+r, 1, color.White, true)
+	vector.StrokeLine(screen, float32(x)+r, float32(y)+r-6, float32(x)+r, float32(y)+r+6, 1, color.White, true)
+}
@@ -1,36 +1,291 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"image"
 	"image/color"
+	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
-	"log"
-	"math"
+	"log/slog"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"golang.design/x/clipboard"
 )
 
 type Game struct {
-	bg *ebiten.Image
+	cfg   Config
+	scene scene
+	// shown while scene is sceneSplash
+	splash *splash
+	// map part shown on its own with F9, 1-based, 0 for the stitched map,
+	// the stitched map's part paths to cycle through, and its state put
+	// aside while a part is shown
+	soloPart  int
+	partPaths []string
+	stitched  *stitchedView
+	// fast travel under way while scene is sceneTravel, and the seconds
+	// until the next one is allowed
+	travel         *travel
+	travelCooldown float64
+
+	bg *background
+	// map sidecar metadata, nil when there is none
+	meta *MapMeta
+	// in-progress crossfade from a previous background
+	swap *bgSwap
+	// background LOD level drawn, the one faded from and seconds of that
+	// fade left
+	lod, lodPrev int
+	lodFade      float64
 	// viewport in background image coordinates (top-left)
-	vx, vy int
+	vx, vy float64
+	// zoom factor applied on top of the cover scale, clamped to
+	// [minZoom, cfg.Zoom.Max]
+	zoom, minZoom float64
+	// camera detached from the player and panned with the arrow keys
+	freeCam bool
+	// seconds left of the "locate me" rings and their parsed color
+	locateLeft  float64
+	locateColor *color.RGBA
+	// what is drawn behind the map, cached at the map area's size, and its
+	// parsed colors
+	backdrop                                   *ebiten.Image
+	backdropSolid, backdropTop, backdropBottom *color.RGBA
+	// droppable effects are off while the frame rate is low, judged once
+	// effectsClock has run through the warm-up
+	effectsDropped bool
+	effectsClock   float64
+	// parsed overview crosshair color, cached on first use
+	crosshairColor *color.RGBA
+	// cursor reticle, its world position and its parsed color
+	showReticle        bool
+	reticleX, reticleY float64
+	reticleColor       *color.RGBA
+	// target of an eased camera move, see moveCameraTo
+	camTargetX, camTargetY float64
+	camMoving              bool
+	// camera momentum: its velocity in world pixels per second, the
+	// velocity it was driven at this update, how far a following camera
+	// has drifted past the player, and how much of that drift the view
+	// (or the eased target) already includes
+	camVX, camVY         float64
+	camDriveX, camDriveY float64
+	camDriven            bool
+	driftX, driftY       float64
+	shownDX, shownDY     float64
+	// showing the whole map, and the camera (vx, vy, zoom) to return to
+	overview    bool
+	preOverview [3]float64
+	// seconds each pan direction (right, left, down, up) has been held,
+	// for acceleration
+	panHeld [4]float64
 	// tile size in background pixels
 	tileW, tileH int
+	// screen size from the last Layout call with a usable window
+	screenW, screenH int
+	// the window is minimized or too small to draw into
+	minimized bool
+	// last cursor position and seconds since it moved, for hiding it
+	cursorX, cursorY int
+	cursorIdle       float64
+	// seconds without input, the HUD's resulting opacity, the offscreen
+	// layer it is faded through and the cursor position last seen
+	idle                 float64
+	hudAlpha             float64
+	hud                  *ebiten.Image
+	activityX, activityY int
+	// show readouts in map pixels even when a world unit is configured
+	rawPixels bool
+	// how the map, sprites and minimap are sampled when scaled
+	filter ebiten.Filter
+	// seconds until a resize counts as settled, 0 when not resizing
+	resizeSettle float64
+	// show the icon legend
+	showLegend bool
+	// magnifier toggle, and its offscreen inset and circular mask
+	showMagnifier    bool
+	loupe, loupeMask *ebiten.Image
+	// ambient sources playing around the map
+	ambient []*ambient
+	// active pings, their color once parsed and the decoded ping sound
+	pings     []ping
+	pingColor *color.RGBA
+	pingSound []byte
+	// collectibles on the map, the sound played when one is opened, and
+	// the collected positions from the save until they are placed
+	chests      []chest
+	chestSound  []byte
+	savedChests [][2]float64
+	// recently placed or visited markers, newest last, and the spawn point
+	// to fall back to
+	markerHistory  []Label
+	spawnX, spawnY float64
+	// roads from the map metadata, and the points player one is walking
+	// through on the way to a destination
+	roads *roadGraph
+	route [][2]float64
+	// auto-markers dropped along the way and seconds since the last one
+	trail      []Label
+	sinceTrail float64
+	// player one's movement keys and the preset they came from
+	bindings KeyBindings
+	preset   int
 	// player position in world coordinates (pixels)
 	px, py float64
 	// player sprite and size
 	playerSprite     *ebiten.Image
 	playerW, playerH int
+	// a left/right key was pressed more recently than up/down
+	horizontalLast bool
+	// seconds player one has been walking, 0 when standing still
+	walkTime float64
+	// last horizontal direction player one moved in, 1 right or -1 left
+	facing float64
+	// direction player one's sprite is turned to with movement.rotate, in
+	// radians clockwise from facing right
+	angle float64
+	// shadow drawn under both players, built on first use
+	shadow *ebiten.Image
+	// player two in two-player mode, nil otherwise
+	second *player
 	// audio
 	audioContext *audio.Context
 	audioPlayer  *audio.Player
+	// track audioPlayer is playing, the one being faded out after a switch
+	// and seconds left of that crossfade
+	musicPath     string
+	oldMusic      *audio.Player
+	musicFadeLeft float64
+	// rooms from the map metadata by grid cell, and the one the player is
+	// in
+	rooms     map[[2]int]room
+	room      room
+	roomCell  [2]int
+	roomKnown bool
+	// music ducking: current gain, depth of the active duck and seconds
+	// left before it releases
+	duckGain, duckDepth, duckLeft float64
+	// music gain following the zoom, ramped toward zoomGainTarget
+	zoomGain float64
+	// music gain faded out while paused, and whether the music was
+	// paused for it, so loopMusic doesn't take it for finished
+	pauseGain float64
+	musicHeld bool
+	// explored area and whether it is drawn over the map
+	fog     *fogLayer
+	showFog bool
+	// exploration readout, and how many milestones have been announced
+	showExplored   bool
+	exploreReached int
+	// player and camera as the previous update left them, and when the
+	// current one ran, for interpolated drawing
+	prevTick tickState
+	tickAt   time.Time
+	// dragging the view with the peek button: where the drag started on
+	// screen, the view then and for how long it has been held; spent
+	// after a timeout until the button is let go. Returning while easing
+	// back onto the player
+	peeking, peekSpent, peekReturning bool
+	peekStartX, peekStartY            int
+	peekViewX, peekViewY, peekTime    float64
+	// annotation layer drawn over the map, nil when there is none
+	overlay     *background
+	showOverlay bool
+	// set while an exploration export or photo is being written, and the
+	// channel photos report back on
+	exporting atomic.Bool
+	photoDone chan photoResult
+	// optional walkability mask and its debug overlay toggle
+	collision     *collisionMask
+	showCollision bool
+	// show the map color under the cursor in the HUD (debug mode)
+	showPixel bool
+	// show the world rectangle in view in the HUD (debug mode)
+	showViewRect bool
+	// Shift+drag selection being drawn from its screen start, and the
+	// glide toward the last one
+	selecting        bool
+	selectX, selectY int
+	zoomGlide        *zoomGlide
+	// time spent per tile and whether it is drawn over the map
+	heatmap     *heatmap
+	showHeatmap bool
+	// time of day in [0, 1) from noon, and the images the night overlay
+	// and the player's light are drawn with
+	timeOfDay    float64
+	night, light *ebiten.Image
+	// show the in-game clock
+	showClock bool
+	// camera shake and the offset it currently adds to the viewport
+	shakeAmp, shakeDuration, shakeLeft float64
+	shakeX, shakeY                     float64
+	// seconds since the last autosave, the in-flight guard and the
+	// channel the writer reports back on
+	sinceAutosave float64
+	autosaving    atomic.Bool
+	autosaveDone  chan error
+	// trigger zones the player is inside, and the grid they are looked up
+	// in
+	inTrigger   []int
+	triggerGrid *spatialGrid
+	// markers by position, for snapping new ones to them
+	markerGrid *spatialGrid
+	// recent frame times and whether the graph is shown
+	frames         frameGraph
+	showFrameGraph bool
+	// lazy map streaming overlay and its numbers
+	showLazyStats bool
+	lazyStats     lazyStats
+	// decorative border around the screen, nil when none is configured
+	frame *screenFrame
+	// minimap placement, its current fade, whether it is shown, and the
+	// thumbnail with the background it was made from
+	minimapRect  image.Rectangle
+	minimapAlpha float64
+	showMinimap  bool
+	minimapImg   *ebiten.Image
+	minimapOf    *background
+	// show the grid cell readout in the HUD
+	showGrid bool
+	// player-placed markers and the counter used to name new ones
+	markers    []Label
+	nextMarker int
+	// notes attached to world points, and the one open in the editor
+	notes    []Note
+	noteEdit *noteEditor
+	// the speedrun in progress and the best times by goal zone
+	run      speedrun
+	runBests map[string]float64
+	// marker last focused with [ and ], -1 for none
+	markerIdx int
+	// labels and markers for nearest queries, and the last hit while the
+	// nearest readout is shown
+	places      placeIndex
+	nearest     *placeHit
+	showNearest bool
+	// keyboard state, see keySource
+	keys keySource
+	// open menu, nil while playing, and the input it is driven by
+	menu      *menu
+	menuInput menuInput
+	// set by the Quit menu item to end the game
+	quit bool
+	// startup timings logged with -bench, nil otherwise
+	bench *startupBench
+	// short messages shown at the bottom of the screen
+	toasts []toast
+	// set once the system clipboard has been initialized
+	clipboardOK bool
 }
 
-func loadImage(path string) (*ebiten.Image, error) {
+func decodeImage(path string) (image.Image, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -40,213 +295,608 @@ func loadImage(path string) (*ebiten.Image, error) {
 	if err != nil {
 		return nil, err
 	}
+	return img, nil
+}
+
+// playerSpritePath is player one's sprite.
+const playerSpritePath = "assets/chest.png"
+
+// targetTile is the approximate tile size, in background pixels, the map
+// is split into.
+const targetTile = 512
+
+// deriveTileSize derives the tile size from the image by splitting it into
+// a grid based on a target tile size (in pixels). This calculates how many
+// columns and rows are needed so each tile is about `target` pixels
+// wide/tall.
+func deriveTileSize(bw, bh, target int) (tileW, tileH int) {
+	cols := (bw + target - 1) / target // ceil(bw/target)
+	rows := (bh + target - 1) / target // ceil(bh/target)
+	if cols < 1 {
+		cols = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	tileW = bw / cols
+	tileH = bh / rows
+	if tileW <= 0 {
+		tileW = bw
+	}
+	if tileH <= 0 {
+		tileH = bh
+	}
+	return tileW, tileH
+}
+
+func loadImage(path string) (*ebiten.Image, error) {
+	img, err := decodeImage(path)
+	if err != nil {
+		return nil, err
+	}
 	return ebiten.NewImageFromImage(img), nil
 }
 
-func (g *Game) Update() error {
-	// check if audio player has finished and restart for loop
-	if g.audioPlayer != nil && !g.audioPlayer.IsPlaying() {
+// playerSpeed is how far a player moves per 60th of a second, in world
+// pixels.
+const playerSpeed = 3.0
+
+// playerStep is how far a player moves in one update at the current tick
+// rate.
+func playerStep() float64 {
+	return playerSpeed * 60 * deltaTime()
+}
+
+// minimizedSize is the largest window dimension treated as minimized.
+const minimizedSize = 2
+
+// deltaTime is the time covered by one Update call, in seconds.
+func deltaTime() float64 {
+	return 1 / float64(ebiten.TPS())
+}
+
+// resizeSlack is what screen-sized images are rounded up to while the
+// window is being resized, so they aren't reallocated for every pixel.
+const resizeSlack = 256
+
+// roundUp rounds n up to a multiple of step.
+func roundUp(n, step int) int {
+	return (n + step - 1) / step * step
+}
+
+// screenImageSize is the size to allocate a screen-sized image at for a
+// sw x sh screen: exact once the size has settled, and rounded up while a
+// resize is still going on so there is room to grow into.
+func (g *Game) screenImageSize(sw, sh int) (int, int) {
+	if g.resizeSettle > 0 {
+		return roundUp(sw, resizeSlack), roundUp(sh, resizeSlack)
+	}
+	return sw, sh
+}
+
+// resized updates the cheap size-dependent layout right away and defers
+// rebuilding screen-sized images until the size settles. Unless the camera
+// is detached it also puts the player back in the middle of the new view
+// right away instead of easing there.
+func (g *Game) resized() {
+	g.resizeSettle = g.cfg.Perf.ResizeSettle
+	g.updateMinZoom()
+	g.updateMinimapRect()
+	resizeFont(g.cfg.Font, g.screenH)
+	if g.cfg.Camera.CenterOnResize && !g.freeCam && !g.overview && g.second == nil {
+		g.follow(true)
+		g.clampView()
+	}
+}
+
+// follow centers the camera on the player.
+func (g *Game) follow(instant bool) {
+	g.moveCameraTo(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2, instant)
+}
+
+// loopMusic restarts the background music once it has finished.
+func (g *Game) loopMusic() {
+	if g.audioPlayer != nil && !g.musicHeld && !g.audioPlayer.IsPlaying() {
 		g.audioPlayer.Rewind()
 		g.audioPlayer.Play()
 	}
+}
 
-	// allow basic arrow-key panning between tiles
-	// move tile indices when arrow keys are pressed
-	const moveDelta = 1
-	if ebiten.IsKeyPressed(ebiten.KeyRight) {
-		g.vx += moveDelta * g.tileW
+func (g *Game) Update() error {
+	if g.bench.finished() {
+		return ebiten.Termination
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
-		g.vx -= moveDelta * g.tileW
+	g.startTick()
+	if g.minimized {
+		// nothing to see, so don't advance movement or timers
+		return nil
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		g.vy += moveDelta * g.tileH
+	if g.scene == sceneSplash {
+		g.updateSplash()
+		return nil
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyUp) {
-		g.vy -= moveDelta * g.tileH
+	g.updateCursor()
+	g.updateResize()
+	g.updateHUDFade()
+	g.updateEffects()
+	g.loopMusic()
+	g.updateMusic()
+	g.updateDuck()
+	g.updateAmbient()
+	g.updateTravel()
+	if g.scene == sceneTravel {
+		return nil
 	}
-	// player movement with WASD keys
-	const playerSpeed = 3.0
-	if ebiten.IsKeyPressed(ebiten.KeyW) {
-		g.py -= playerSpeed
+	if g.updateNoteEditor() {
+		// typing into a note, so no key reaches the map or the menu
+		return nil
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyS) {
-		g.py += playerSpeed
+	if g.updateMenu() {
+		// the map is paused while a menu is open
+		if g.quit {
+			return ebiten.Termination
+		}
+		return nil
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyA) {
-		g.px -= playerSpeed
+
+	// arrow-key panning while the camera is detached from the player; in
+	// two-player mode the arrows belong to player two and the camera
+	// frames both players
+	if g.justPressed(ebiten.KeyM) {
+		g.toggleOverview()
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyD) {
-		g.px += playerSpeed
+	if g.justPressed(ebiten.KeyL) {
+		g.locate()
 	}
-	// clamp player to image bounds
-	if g.bg != nil {
-		bw, bh := g.bg.Bounds().Dx(), g.bg.Bounds().Dy()
-		// clamp player position so it doesn't go outside background
-		// player bounds: (px, py) to (px + playerW, py + playerH)
-		if g.px < 0 {
-			g.px = 0
-		}
-		if g.py < 0 {
-			g.py = 0
-		}
-		// ensure player's right edge doesn't exceed background's right edge
-		maxPx := float64(bw - g.playerW)
-		if maxPx < 0 {
-			maxPx = 0
-		}
-		if g.px > maxPx {
-			g.px = maxPx
-		}
-		// ensure player's bottom edge doesn't exceed background's bottom edge
-		maxPy := float64(bh - g.playerH)
-		if maxPy < 0 {
-			maxPy = 0
-		}
-		if g.py > maxPy {
-			g.py = maxPy
-		}
-		// compute scale for current viewport
-		vw := g.tileW
-		vh := g.tileH
-		// desired viewport center to match player center on screen
-		desiredVx := int(g.px + float64(g.playerW)/2 - float64(vw)/2)
-		desiredVy := int(g.py + float64(g.playerH)/2 - float64(vh)/2)
-		// clamp viewport to image bounds
-		if desiredVx < 0 {
-			desiredVx = 0
+	g.updateLocate()
+	g.updatePings()
+	if g.second == nil && !g.overview {
+		if g.justPressed(ebiten.KeyF) {
+			g.freeCam = !g.freeCam
+			g.panHeld = [4]float64{}
+			if !g.freeCam {
+				g.locate()
+			}
 		}
-		if desiredVy < 0 {
-			desiredVy = 0
+		if g.freeCam {
+			g.updatePan()
+			g.updateEdgeScroll()
 		}
-		if desiredVx > bw-g.tileW {
-			desiredVx = bw - g.tileW
+		g.updateZoom()
+		g.updateZoomSelect()
+	}
+
+	// player movement with the bound keys, WASD by default
+	var mx, my float64
+	step := playerStep()
+	if ebiten.IsKeyPressed(g.bindings.Up) {
+		my -= step
+	}
+	if ebiten.IsKeyPressed(g.bindings.Down) {
+		my += step
+	}
+	if ebiten.IsKeyPressed(g.bindings.Left) {
+		mx -= step
+	}
+	if ebiten.IsKeyPressed(g.bindings.Right) {
+		mx += step
+	}
+	g.horizontalLast = g.lastAxis(g.horizontalLast, g.bindings.Up, g.bindings.Down, g.bindings.Left, g.bindings.Right)
+	mx, my = lockDirection(mx, my, g.cfg.Movement.Directions, g.horizontalLast)
+	// walking by hand cancels a route
+	routing := false
+	if mx != 0 || my != 0 {
+		g.route = nil
+	} else if len(g.route) > 0 {
+		mx, my = g.routeStep()
+		routing = true
+	}
+	nx, ny := g.stepPlayer(g.px, g.py, mx, my)
+	if routing && nx == g.px && ny == g.py && len(g.route) > 0 {
+		g.route = nil
+		g.toast("Route blocked")
+	}
+	g.walkTime = walkTimer(g.walkTime, nx != g.px || ny != g.py)
+	g.facing = facingOf(g.facing, mx)
+	g.angle = g.turnSprite(g.angle, mx, my)
+	if g.cfg.Map.Wrap && g.bg != nil {
+		g.followWrap(g.px, g.py, nx, ny)
+	}
+	if !g.freeCam && (nx != g.px || ny != g.py) {
+		g.driveCamera((nx-g.px)/deltaTime(), (ny-g.py)/deltaTime())
+	}
+	moved := nx != g.px || ny != g.py
+	g.px, g.py = nx, ny
+	g.updateSpeedrun(moved)
+	if g.second != nil {
+		mx2, my2 := arrowInput()
+		g.second.horizontalLast = g.lastAxis(g.second.horizontalLast, ebiten.KeyUp, ebiten.KeyDown, ebiten.KeyLeft, ebiten.KeyRight)
+		mx2, my2 = lockDirection(mx2, my2, g.cfg.Movement.Directions, g.second.horizontalLast)
+		nx, ny := g.stepPlayer(g.second.x, g.second.y, mx2, my2)
+		g.second.walkTime = walkTimer(g.second.walkTime, nx != g.second.x || ny != g.second.y)
+		g.second.facing = facingOf(g.second.facing, mx2)
+		g.second.angle = g.turnSprite(g.second.angle, mx2, my2)
+		g.second.x, g.second.y = nx, ny
+	}
+	if g.bg != nil {
+		if g.overview {
+			// refit every update so window resizes are followed; the
+			// overview does its own centering instead of clampView
+			g.fitOverview()
+		} else {
+			if g.second != nil {
+				g.frameBoth()
+			} else if !g.peekCamera() && !g.freeCam {
+				g.follow(false)
+			}
+			g.glideCamera()
+			g.updateCamera()
+			g.clampGlide()
 		}
-		if desiredVy > bh-g.tileH {
-			desiredVy = bh - g.tileH
+	}
+
+	// after the camera has moved for this update
+	g.updateReticle()
+	g.updateHeatmap()
+	g.updateDayNight()
+	g.updateAutosave()
+	g.updateTriggers()
+	g.updateChests()
+	g.updateRoom()
+	g.updateShake()
+	// reveal the area around the player
+	if g.fog != nil {
+		g.fog.reveal(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2, fogRevealRadius)
+		if g.second != nil {
+			g.fog.reveal(g.second.x+float64(g.playerW)/2, g.second.y+float64(g.playerH)/2, fogRevealRadius)
 		}
-		g.vx = desiredVx
-		g.vy = desiredVy
 	}
+	g.updateExplore()
+	if g.justPressed(ebiten.KeyV) {
+		g.showFog = !g.showFog
+	}
+	if g.justPressed(ebiten.KeyX) {
+		g.exportExplored()
+	}
+	g.updatePhoto()
+	if g.justPressed(ebiten.KeyI) {
+		g.showMagnifier = !g.showMagnifier
+	}
+	if g.justPressed(ebiten.KeyF1) {
+		g.showLegend = !g.showLegend
+	}
+	g.updateControls()
+	g.updateFilter()
+	g.updateUnits()
+	if g.justPressed(ebiten.KeyF3) && g.cfg.Debug && g.collision != nil {
+		g.showCollision = !g.showCollision
+	}
+	if g.justPressed(ebiten.KeyF10) && g.cfg.Debug {
+		g.showPixel = !g.showPixel
+	}
+	if g.justPressed(ebiten.KeyF12) && g.cfg.Debug {
+		g.showViewRect = !g.showViewRect
+	}
+	if g.justPressed(ebiten.KeyF4) && g.cfg.Debug {
+		g.writeStateDump()
+	}
+	if g.justPressed(ebiten.KeyF5) {
+		g.soloPart = 0
+		g.reloadBackground()
+	}
+	if g.justPressed(ebiten.KeyF9) && g.cfg.Debug && g.bg != nil {
+		g.cycleMapPart()
+	}
+	g.updateSwap()
+	g.updateLazy()
+	g.updateLazyStats()
+	g.updateLOD()
+	if g.justPressed(ebiten.KeyF2) {
+		g.showFrameGraph = !g.showFrameGraph
+	}
+	if g.justPressed(ebiten.KeyH) {
+		g.showHeatmap = !g.showHeatmap
+	}
+	g.updateOverlay()
+	if g.justPressed(ebiten.KeyG) {
+		g.showGrid = !g.showGrid
+	}
+	if g.justPressed(ebiten.KeyTab) {
+		g.showMinimap = !g.showMinimap
+	}
+	g.updateMinimap()
+	if g.justPressed(ebiten.KeyT) && g.cfg.DayNight.Enabled {
+		g.showClock = !g.showClock
+	}
+	if g.justPressed(ebiten.KeyC) {
+		g.copyCoordinates(ebiten.IsKeyPressed(ebiten.KeyShift))
+	}
+	g.updateNotes()
+	g.updateMarkers()
+	g.updateTrail()
+	g.updateMarkerCycle()
+	g.returnToMarker()
+	if g.justPressed(ebiten.KeyN) {
+		g.showNearest = !g.showNearest
+	}
+	g.updateNearest()
+	g.updateToasts()
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	if g.minimized {
+		return
+	}
+	start := time.Now()
+	g.frames.record(start)
+	switch g.scene {
+	case sceneSplash:
+		g.drawSplash(screen)
+	case sceneTravel:
+		g.drawTravel(screen)
+	default:
+		g.drawInterpolated(func() { g.drawWorld(screen) })
+	}
+	g.bench.frameDrawn(start)
+}
+
+func (g *Game) drawWorld(screen *ebiten.Image) {
 	if g.bg == nil {
 		return
 	}
 
 	// screen size
 	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	scale, tx, ty := g.worldTransform(sw, sh)
+	// with an insetting frame the map stays inside the frame's opening
+	world := screen.SubImage(g.mapArea(sw, sh)).(*ebiten.Image)
+	g.drawBackdrop(world)
 
-	// desired viewport in background image coordinates (use tileW/tileH)
-	vw := g.tileW
-	vh := g.tileH
-
-	// compute scale to cover the screen while preserving aspect ratio
-	sx := float64(sw) / float64(vw)
-	sy := float64(sh) / float64(vh)
-	// use the larger scale so the viewport covers the whole screen (no empty bars)
-	scale := math.Max(sx, sy)
-
-	op := &ebiten.DrawImageOptions{}
-	// Scale first, then translate so that the viewport's (vx,vy) maps to
-	// the screen origin, and then center the scaled viewport on the screen.
-	// After scaling, add an offset to center if the scaled viewport is larger
-	// than the screen in one dimension.
-	dx := (float64(sw) - float64(vw)*scale) / 2
-	dy := (float64(sh) - float64(vh)*scale) / 2
-	op.GeoM.Scale(scale, scale)
-	op.GeoM.Translate(-float64(g.vx)*scale+dx, -float64(g.vy)*scale+dy)
-
-	screen.DrawImage(g.bg, op)
-
-	// draw shadow (ellipse beneath the player)
-	shadowWidth := int(float64(g.playerW) * 0.8)
-	shadowHeight := int(float64(g.playerH) * 0.3)
-	shadowOffsetY := float64(g.playerH) * 2.1 // offset below player
-
-	// create shadow image with rounded corners (ellipse effect)
-	shadowImg := ebiten.NewImage(shadowWidth, shadowHeight)
-	// fill with semi-transparent black
-	shadowImg.Fill(color.RGBA{R: 0, G: 0, B: 0, A: 100})
-
-	// draw rounded corners by clearing corner regions
-	cornerRadius := int(float64(shadowHeight) / 2)
-	for x := 0; x < cornerRadius; x++ {
-		for y := 0; y < cornerRadius; y++ {
-			dx := x - cornerRadius
-			dy := y - cornerRadius
-			if dx*dx+dy*dy > cornerRadius*cornerRadius {
-				// clear top-left corner
-				shadowImg.Set(x, y, color.RGBA{0, 0, 0, 0})
-				// clear top-right corner
-				shadowImg.Set(shadowWidth-1-x, y, color.RGBA{0, 0, 0, 0})
-				// clear bottom-left corner
-				shadowImg.Set(x, shadowHeight-1-y, color.RGBA{0, 0, 0, 0})
-				// clear bottom-right corner
-				shadowImg.Set(shadowWidth-1-x, shadowHeight-1-y, color.RGBA{0, 0, 0, 0})
-			}
-		}
+	if g.lodFade > 0 {
+		// fade the new LOD level in over the previous one
+		g.bg.draw(world, g.lodPrev, scale, tx, ty, g.cfg.Map.SeamOverlap, 1, g.filter)
+		g.bg.draw(world, g.lod, scale, tx, ty, g.cfg.Map.SeamOverlap, g.lodAlpha(), g.filter)
+	} else {
+		g.bg.draw(world, g.lod, scale, tx, ty, g.cfg.Map.SeamOverlap, 1, g.filter)
+	}
+	g.bg.countVisible(world.Bounds(), scale, tx, ty)
+	// fade out the previous background over the new one
+	if g.swap != nil {
+		g.swap.old.draw(world, g.lod, scale, tx, ty, g.cfg.Map.SeamOverlap, g.swapAlpha(), g.filter)
 	}
 
-	// draw shadow
-	playerScreenX := (g.px-float64(g.vx))*scale + dx
-	playerScreenY := (g.py-float64(g.vy))*scale + dy
+	g.drawOverlay(world, scale, tx, ty)
 
-	shadowOp := &ebiten.DrawImageOptions{}
-	shadowOp.GeoM.Scale(scale, scale)
-	shadowOp.GeoM.Translate(
-		playerScreenX+float64(g.playerW-shadowWidth)/2*scale,
-		playerScreenY+shadowOffsetY*scale,
-	)
-	shadowOp.ColorScale.ScaleAlpha(0.9)
-	screen.DrawImage(shadowImg, shadowOp)
+	// darken unexplored cells with the same world transform
+	if g.showFog && g.fog != nil {
+		fogOp := &ebiten.DrawImageOptions{}
+		fogOp.GeoM.Scale(fogCellSize*scale, fogCellSize*scale)
+		fogOp.GeoM.Translate(tx, ty)
+		fogOp.Filter = ebiten.FilterLinear
+		world.DrawImage(g.fog.image(), fogOp)
+	}
 
-	// draw player sprite
-	// convert player world position to screen position
-	playerOp := &ebiten.DrawImageOptions{}
-	playerOp.GeoM.Scale(scale, scale)
-	playerOp.GeoM.Translate(playerScreenX, playerScreenY)
-	if g.playerSprite != nil {
-		screen.DrawImage(g.playerSprite, playerOp)
+	// color tiles by how long the player spent in them
+	if g.showHeatmap && g.heatmap != nil {
+		heatOp := &ebiten.DrawImageOptions{}
+		heatOp.GeoM.Scale(float64(g.tileW)*scale, float64(g.tileH)*scale)
+		heatOp.GeoM.Translate(tx, ty)
+		world.DrawImage(g.heatmap.image(), heatOp)
+	}
+
+	// tint blocked pixels when debugging the collision mask
+	if g.showCollision && g.collision != nil {
+		maskOp := &ebiten.DrawImageOptions{}
+		maskOp.GeoM.Scale(g.collision.scaleX*scale, g.collision.scaleY*scale)
+		maskOp.GeoM.Translate(tx, ty)
+		world.DrawImage(g.collision.overlay(), maskOp)
+	}
+
+	g.drawTrail(world)
+	g.drawChests(world)
+	g.drawMarkers(world)
+	g.drawNotes(world)
+	g.drawSnap(world)
+	g.drawReticle(world)
+	g.drawZoomSelect(world)
+	g.drawNearest(world)
+	g.drawRoute(world)
+	g.drawPlayer(world, g.playerSprite, g.px, g.py, g.walkTime, g.facing, g.angle, scale, tx, ty)
+	if g.second != nil {
+		g.drawPlayer(world, g.second.sprite, g.second.x, g.second.y, g.second.walkTime, g.second.facing, g.second.angle, scale, tx, ty)
+	}
+
+	g.drawRoomTint(world)
+	g.drawNight(world, scale, tx, ty)
+	g.drawCrosshair(world)
+	g.drawLocate(world)
+	g.drawPings(world)
+
+	if g.frame != nil {
+		g.frame.draw(screen)
+	}
+
+	if g.showMinimap {
+		g.drawMinimap(screen)
+	}
+	g.drawMagnifier(screen)
+	g.drawLegend(screen)
+	hud := g.hudLayer(screen)
+	g.drawHUD(hud)
+	if g.showClock && g.cfg.DayNight.Enabled {
+		g.drawClock(hud)
+	}
+	g.drawToasts(hud)
+	g.drawHUDLayer(screen, hud)
+	if g.showFrameGraph {
+		g.drawFrameGraph(screen)
+	}
+	g.drawLazyStats(screen)
+	g.drawNoteTooltip(screen)
+	if g.noteEdit != nil {
+		g.drawNoteEditor(screen)
+	}
+	if g.menu != nil {
+		g.drawMenu(screen)
+	}
+}
+
+// updateResize counts down to the end of a resize and then gives up the
+// slack screen-sized images were allocated with; they are rebuilt at the
+// exact size on their next use.
+func (g *Game) updateResize() {
+	if g.resizeSettle <= 0 {
+		return
+	}
+	g.resizeSettle -= deltaTime()
+	if g.resizeSettle > 0 {
+		return
+	}
+	if g.night != nil && (g.night.Bounds().Dx() != g.screenW || g.night.Bounds().Dy() != g.screenH) {
+		g.night.Deallocate()
+		g.night = nil
 	}
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	// a minimized window can report a zero or tiny size; keep the last
+	// real size so nothing size-dependent gets rebuilt around it
+	g.minimized = ebiten.IsWindowMinimized() || outsideWidth <= minimizedSize || outsideHeight <= minimizedSize
+	if g.minimized {
+		return max(g.screenW, 1), max(g.screenH, 1)
+	}
+	if outsideWidth != g.screenW || outsideHeight != g.screenH {
+		g.screenW, g.screenH = outsideWidth, outsideHeight
+		g.resized()
+	}
 	return outsideWidth, outsideHeight
 }
 
 func main() {
-	// load background from assets folder
-	imgPath := "assets/map-part1.jpg"
-	bg, err := loadImage(imgPath)
+	launched := time.Now()
+	configPath := flag.String("config", "config.json", "path to the JSON config file")
+	debug := flag.Bool("debug", false, "enable debug overlays")
+	spawn := flag.String("spawn", "", "start at a label name or x,y world coordinate")
+	twoPlayer := flag.Bool("two-player", false, "add a second player on the arrow keys")
+	at := flag.String("at", "", "start centered on x,y[,zoom], overriding -spawn")
+	logLevel := flag.String("loglevel", "info", "log level: debug, info, warn or error")
+	logFile := flag.Bool("logfile", false, "also write the log to a file in the user config directory")
+	controls := flag.String("controls", "", "control preset: wasd, esdf, arrows or vim")
+	validate := flag.Bool("validate", false, "check every configured asset and exit without opening a window")
+	benchFlag := flag.Bool("bench", false, "log how long each startup phase and the first frame take")
+	benchExit := flag.Bool("bench-exit", false, "with -bench, exit once the first frame is drawn")
+	importCSV := flag.String("import", "", "add markers and labels from a CSV file of x,y,label,type rows")
+	exportCSV := flag.String("export-csv", "", "write all labels and saved markers to a CSV file and exit")
+	flag.Parse()
+	level, err := parseLogLevel(*logLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	closeLog, err := setupLogging(level, *logFile)
 	if err != nil {
-		log.Fatalf("failed to load background image %s: %v", imgPath, err)
+		slog.Warn("failed to open log file", "err", err)
 	}
+	defer closeLog()
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fatal("failed to load config", "path", *configPath, "err", err)
+	}
+	cfg.Debug = cfg.Debug || *debug
+	cfg.TwoPlayer.Enabled = cfg.TwoPlayer.Enabled || *twoPlayer
+	if *spawn != "" {
+		cfg.Spawn = *spawn
+	}
+	if *controls != "" {
+		cfg.Controls.Preset = *controls
+	}
+	if *importCSV != "" {
+		cfg.Import = *importCSV
+	}
+	// imported labels join the config's before spawns are resolved;
+	// imported markers join the saved ones once the save is loaded
+	var importedMarkers []Label
+	if cfg.Import != "" {
+		labels, markers, err := loadPlacesCSV(cfg.Import)
+		if err != nil {
+			slog.Warn("failed to import CSV", "path", cfg.Import, "err", err)
+		} else {
+			cfg.Labels = appendNew(cfg.Labels, labels)
+			importedMarkers = markers
+			slog.Info("imported CSV", "path", cfg.Import, "labels", len(labels), "markers", len(markers))
+		}
+	}
+	if *exportCSV != "" {
+		var markers []Label
+		if st, err := loadSave(); err != nil {
+			slog.Warn("failed to load save", "err", err)
+		} else {
+			markers = st.Markers
+		}
+		markers = appendNew(markers, importedMarkers)
+		if err := writePlacesCSV(*exportCSV, cfg.Labels, markers); err != nil {
+			fatal("failed to export CSV", "path", *exportCSV, "err", err)
+		}
+		slog.Info("exported CSV", "path", *exportCSV, "labels", len(cfg.Labels), "markers", len(markers))
+		return
+	}
+	var atX, atY, atZoom float64
+	atOK := false
+	if *at != "" {
+		atX, atY, atZoom, err = parseAt(*at)
+		if err != nil {
+			slog.Warn("ignoring -at", "err", err)
+		} else {
+			atOK = true
+		}
+	}
+	// an explicitly requested spawn wins over the saved position
+	restorePosition := cfg.Spawn == "" && !atOK
 
-	// derive tile size from the image by splitting it into a grid based on
-	// a target tile size (in pixels). This calculates how many columns and
-	// rows are needed so each tile is about `targetTile` pixels wide/tall.
-	bw, bh := bg.Bounds().Dx(), bg.Bounds().Dy()
-	const targetTile = 512
-	cols := (bw + targetTile - 1) / targetTile // ceil(bw/targetTile)
-	rows := (bh + targetTile - 1) / targetTile // ceil(bh/targetTile)
-	if cols < 1 {
-		cols = 1
+	// optional sidecar describing the map; absent means auto-derive
+	meta, err := loadMapMeta(cfg.Map.Meta)
+	if err != nil {
+		slog.Warn("failed to load map metadata", "path", cfg.Map.Meta, "err", err)
 	}
-	if rows < 1 {
-		rows = 1
+	if *validate {
+		errs := validateAssets(cfg, meta)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("map metadata %s: %w", cfg.Map.Meta, err))
+		}
+		for _, e := range errs {
+			slog.Error("asset check failed", "err", e)
+		}
+		if len(errs) > 0 {
+			fatal("asset validation failed", "problems", len(errs))
+		}
+		slog.Info("all assets are valid")
+		return
 	}
-	tileW := bw / cols
-	tileH := bh / rows
-	if tileW <= 0 {
-		tileW = bw
+	var bench *startupBench
+	if *benchFlag {
+		bench = newStartupBench(launched, *benchExit)
+		bench.mark("config")
 	}
-	if tileH <= 0 {
-		tileH = bh
+	if meta != nil {
+		cfg.Labels = append(cfg.Labels, meta.Labels...)
+		if cfg.Spawn == "" && len(meta.Spawns) > 0 {
+			cfg.Spawn = meta.Spawns[0].Name
+		}
+	}
+
+	// load background parts from assets folder
+	bg, err := loadMapBackground(cfg.Map, meta, bench)
+	if err != nil {
+		fatal("failed to load background image", "path", cfg.Map.Parts, "err", err)
 	}
+
+	bw, bh := bg.size()
+	tileW, tileH := deriveTileSize(bw, bh, targetTile)
 	// set a larger default window size and allow resizing
+	if cfg.Perf.TPS > 0 {
+		ebiten.SetTPS(cfg.Perf.TPS)
+	}
 	ebiten.SetWindowSize(1024, 768)
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowTitle("Hyrule Map Explorer")
@@ -261,51 +911,164 @@ func main() {
 	if playerSize < 1 {
 		playerSize = 1
 	}
-	playerW, playerH := playerSize, playerSize
 
 	// load player sprite
-	playerSpritePath := "assets/chest.png"
 	playerSpriteOrig, err := loadImage(playerSpritePath)
 	if err != nil {
-		log.Fatalf("failed to load player sprite %s: %v", playerSpritePath, err)
+		fatal("failed to load player sprite", "path", playerSpritePath, "err", err)
 	}
 
-	// resize sprite to player size
-	playerSprite := ebiten.NewImage(playerW, playerH)
-	op := &ebiten.DrawImageOptions{}
-	// calculate scale to fit sprite into playerW x playerH
-	spriteW, spriteH := playerSpriteOrig.Bounds().Dx(), playerSpriteOrig.Bounds().Dy()
-	scaleX := float64(playerW) / float64(spriteW)
-	scaleY := float64(playerH) / float64(spriteH)
-	op.GeoM.Scale(scaleX, scaleY)
-	playerSprite.DrawImage(playerSpriteOrig, op)
+	// resize sprite to fit the player box without stretching; the player
+	// size follows the fitted sprite so collision and shadow match it
+	playerSprite := fitSprite(playerSpriteOrig, playerSize, playerSize)
+	playerW, playerH := playerSprite.Bounds().Dx(), playerSprite.Bounds().Dy()
+	bench.mark("scale sprite")
 
 	playerX := float64((tileW / 2) - (playerW / 2))
 	playerY := float64((tileH / 2) - (playerH / 2))
-	g := &Game{bg: bg, vx: 0, vy: 0, tileW: tileW, tileH: tileH, px: playerX, py: playerY, playerSprite: playerSprite, playerW: playerW, playerH: playerH}
+	g := &Game{cfg: cfg, meta: meta, scene: sceneMap, bg: bg, vx: 0, vy: 0, zoom: 1, minZoom: 1, keys: ebitenKeys{}, duckGain: 1, zoomGain: 1, pauseGain: 1, markerIdx: -1, autosaveDone: make(chan error, 1), photoDone: make(chan photoResult, 1), tileW: tileW, tileH: tileH, px: playerX, py: playerY, playerSprite: playerSprite, playerW: playerW, playerH: playerH, facing: 1, hudAlpha: 1}
+	if cfg.Spawn != "" {
+		places := cfg.Labels
+		if meta != nil {
+			places = append(places, meta.Spawns...)
+		}
+		sx, sy, err := resolveSpawn(cfg.Spawn, places)
+		if err != nil {
+			slog.Warn("using default spawn", "err", err)
+		} else {
+			g.px = sx - float64(playerW)/2
+			g.py = sy - float64(playerH)/2
+			g.clampPlayer()
+		}
+	}
+	g.spawnX, g.spawnY = g.px+float64(playerW)/2, g.py+float64(playerH)/2
+	if atOK {
+		g.px = atX - float64(playerW)/2
+		g.py = atY - float64(playerH)/2
+		g.clampPlayer()
+		if atZoom > 0 {
+			// clamped to the zoom limits once the window size is known
+			g.zoom = atZoom
+		}
+	}
+	if cfg.TwoPlayer.Enabled {
+		second, err := newPlayer(cfg.TwoPlayer.Sprite, playerSize, g.px+float64(playerW)*2, g.py)
+		if err != nil {
+			slog.Warn("failed to load player two sprite", "path", cfg.TwoPlayer.Sprite, "err", err)
+		} else {
+			g.second = second
+			g.second.x, g.second.y = g.clampToMap(g.second.x, g.second.y)
+		}
+	}
+	preset, ok := presetIndex(cfg.Controls.Preset)
+	if !ok {
+		slog.Warn("unknown control preset, using wasd", "preset", cfg.Controls.Preset)
+	}
+	g.setPreset(preset)
+	if goal := cfg.Speedrun.Goal; goal != "" && !hasTrigger(cfg.Triggers, goal) {
+		slog.Warn("speedrun goal is not a trigger zone", "goal", goal)
+	}
+	g.filter = parseFilter(cfg.Map.Filter)
+	g.fog = newFogLayer(bw, bh)
+	g.frame = newScreenFrame(cfg.Frame)
+	g.showGrid = cfg.Grid.Show
+	g.showReticle = cfg.Reticle.Shown
+	g.showMinimap = cfg.Minimap.Show
+	g.showClock = cfg.DayNight.Clock.Show
+	g.minimapAlpha = cfg.Minimap.Opacity
+	g.heatmap = newHeatmap(bw, bh, tileW, tileH)
+	if st, err := loadSave(); err != nil {
+		slog.Warn("failed to load save", "err", err)
+	} else {
+		g.applySave(st, restorePosition)
+	}
+	if len(importedMarkers) > 0 {
+		g.markers = appendNew(g.markers, importedMarkers)
+		g.nextMarker = lastMarkerNumber(g.markers)
+	}
+	g.rebuildPlaces()
+	// start on the player rather than easing in from the corner
+	g.follow(true)
+	if cfg.Collision.Mask != "" {
+		mask, err := loadCollisionMask(cfg.Collision.Mask, bw, bh)
+		if err != nil {
+			slog.Warn("failed to load collision mask", "path", cfg.Collision.Mask, "err", err)
+		} else {
+			g.collision = mask
+			g.unstick()
+			g.follow(true)
+		}
+	}
+	loadFont(cfg.Font)
+	// milestones the restored fog already passed were announced before
+	g.exploreReached = exploreMilestones(cfg.Explore.Milestones, exploredPercent(g.fog))
+	g.overlay = loadOverlay(cfg.Map.Overlay, bg)
+	g.showOverlay = cfg.Map.Overlay.Shown
+	// after the collision mask so random chests land on open ground
+	g.placeChests(g.savedChests)
+	g.savedChests = nil
+	if sp := newSplash(cfg.Splash); sp != nil {
+		g.splash = sp
+		g.scene = sceneSplash
+	}
+	bench.mark("setup")
 
 	// load and play background music
-	audioContext := audio.NewContext(48000)
-	musicPath := "assets/kakariko-village.mp3"
+	musicPath := defaultMusicPath
+	var decoded *mp3.Stream
 	musicFile, err := os.Open(musicPath)
 	if err != nil {
-		log.Printf("warning: failed to load music %s: %v", musicPath, err)
+		slog.Info("failed to load music", "path", musicPath, "err", err)
 	} else {
 		defer musicFile.Close()
-		decoded, err := mp3.DecodeWithSampleRate(audioContext.SampleRate(), musicFile)
+		decoded, err = decodeMusic(musicFile, cfg.Audio.SampleRate)
+		if err != nil {
+			slog.Warn("failed to decode music", "path", musicPath, "err", err)
+		}
+	}
+	// the context must match the stream, so create it after decoding
+	sampleRate := cfg.Audio.SampleRate
+	if decoded != nil {
+		sampleRate = decoded.SampleRate()
+	}
+	if sampleRate <= 0 {
+		sampleRate = defaultSampleRate
+	}
+	audioContext := audio.NewContext(sampleRate)
+	slog.Debug("audio context created", "sampleRate", sampleRate)
+	if decoded != nil {
+		player, err := audioContext.NewPlayer(decoded)
 		if err != nil {
-			log.Printf("warning: failed to decode music %s: %v", musicPath, err)
+			slog.Warn("failed to create audio player", "err", err)
 		} else {
-			player, err := audioContext.NewPlayer(decoded)
-			if err != nil {
-				log.Printf("warning: failed to create audio player: %v", err)
-			} else {
+			player.SetVolume(cfg.Audio.Volume)
+			// otherwise loopMusic starts it once the splash is over
+			if g.scene != sceneSplash || cfg.Splash.MusicDuringSplash {
 				player.Play()
-				g.audioContext = audioContext
-				g.audioPlayer = player
 			}
+			g.audioPlayer = player
+			g.musicPath = musicPath
 		}
 	}
+	g.audioContext = audioContext
+	g.loadPingSound(cfg.Ping.Sound)
+	g.chestSound = g.loadSound(cfg.Chests.Sound, "chest")
+	g.loadAmbient()
+	bench.mark("decode audio")
+	if meta != nil && meta.Roads != nil {
+		g.roads = newRoadGraph(meta.Roads)
+	}
+	if meta != nil && meta.Rooms != nil {
+		g.rooms = loadRooms(meta.Rooms, meta.dir)
+	}
+
+	if err := clipboard.Init(); err != nil {
+		slog.Warn("clipboard unavailable", "err", err)
+	} else {
+		g.clipboardOK = true
+	}
+	g.bench = bench
+	bench.mark("rest")
 
 	// start in fullscreen mode
 	// ebiten.SetFullscreen(true)
@@ -313,4 +1076,12 @@ func main() {
 	if err := ebiten.RunGame(g); err != nil {
 		panic(err)
 	}
+	if bench.finished() {
+		// nothing was played, so leave the save as it was
+		return
+	}
+	g.waitAutosave()
+	if err := writeSave(g.saveState()); err != nil {
+		slog.Warn("failed to write save", "err", err)
+	}
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// playerOnScreen is where the center of the player is drawn.
+func playerOnScreen(g *Game) (float64, float64) {
+	return g.worldToScreen(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2)
+}
+
+func TestResizedRecentersOnPlayer(t *testing.T) {
+	sizes := [][2]int{{1280, 720}, {800, 1200}, {320, 240}}
+	for _, s := range sizes {
+		g := newTestGame(4000, 4000)
+		g.follow(true)
+		g.screenW, g.screenH = s[0], s[1]
+		g.resized()
+		x, y := playerOnScreen(g)
+		if math.Abs(x-float64(s[0])/2) > 1e-6 || math.Abs(y-float64(s[1])/2) > 1e-6 {
+			t.Errorf("after resizing to %dx%d the player is at %v, %v, want %v, %v",
+				s[0], s[1], x, y, s[0]/2, s[1]/2)
+		}
+	}
+}
+
+// Near the map edge the camera is clamped rather than centred on the
+// player.
+func TestResizedClampsNearEdge(t *testing.T) {
+	g := newTestGame(4000, 4000)
+	g.px, g.py = 10, 10
+	g.follow(true)
+	g.clampView()
+	g.screenW, g.screenH = 1280, 720
+	g.resized()
+	want := g.vx
+	g.clampView()
+	if g.vx != want {
+		t.Errorf("resized left the view at x %v, clamping moves it to %v", want, g.vx)
+	}
+	if x, _ := playerOnScreen(g); x >= 640 {
+		t.Errorf("player by the left edge drawn at x %v, want left of centre", x)
+	}
+}
+
+func TestResizedLeavesDetachedCamera(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(g *Game)
+	}{
+		{"free camera", func(g *Game) { g.freeCam = true }},
+		{"centerOnResize off", func(g *Game) { g.cfg.Camera.CenterOnResize = false }},
+	}
+	for _, tt := range tests {
+		g := newTestGame(4000, 4000)
+		tt.setup(g)
+		g.vx, g.vy = 500, 700
+		g.screenW, g.screenH = 1280, 720
+		g.resized()
+		if g.vx != 500 || g.vy != 700 {
+			t.Errorf("%s: resize moved the view to %v, %v", tt.name, g.vx, g.vy)
+		}
+	}
+}
+
+func TestRoundUp(t *testing.T) {
+	tests := []struct{ n, step, want int }{
+		{0, 256, 0},
+		{1, 256, 256},
+		{256, 256, 256},
+		{257, 256, 512},
+		{1920, 256, 2048},
+		{7, 1, 7},
+	}
+	for _, tt := range tests {
+		if got := roundUp(tt.n, tt.step); got != tt.want {
+			t.Errorf("roundUp(%d, %d) = %d, want %d", tt.n, tt.step, got, tt.want)
+		}
+	}
+}
+
+// Dragging the window edge a few pixels per update reallocates a
+// screen-sized image only when the slack runs out, and once more at the
+// exact size after the drag stops for perf.resizeSettle seconds.
+func TestResizeSettle(t *testing.T) {
+	g := newTestGame(4000, 4000)
+	settle := g.cfg.Perf.ResizeSettle
+	if settle <= 0 {
+		t.Fatalf("default perf.resizeSettle = %v, want > 0", settle)
+	}
+	// stands in for the night overlay: rebuilt when too small, and given
+	// up by updateResize once settled at a different size
+	imgW, imgH, allocs := 0, 0, 0
+	draw := func() {
+		if imgW < g.screenW || imgH < g.screenH {
+			imgW, imgH = g.screenImageSize(g.screenW, g.screenH)
+			allocs++
+		}
+	}
+	update := func() {
+		g.updateResize()
+		if g.resizeSettle <= 0 && (imgW != g.screenW || imgH != g.screenH) {
+			imgW, imgH = 0, 0
+		}
+		draw()
+	}
+
+	draw()
+	if allocs != 1 || imgW != 640 || imgH != 480 {
+		t.Fatalf("before resizing allocated %d images at %dx%d", allocs, imgW, imgH)
+	}
+	for w := 644; w <= 900; w += 4 {
+		g.screenW, g.screenH = w, 500
+		g.resized()
+		update()
+	}
+	// 644x500 rounds up to 768x512, and 772 needs 1024 wide
+	if allocs != 3 {
+		t.Errorf("dragging from 640 to 900 made %d allocations in all, want 3", allocs)
+	}
+	if imgW != 1024 || imgH != 512 {
+		t.Errorf("while resizing the image is %dx%d, want 1024x512", imgW, imgH)
+	}
+
+	quiet := 0
+	for g.resizeSettle > 0 {
+		update()
+		quiet++
+		if quiet > 1000 {
+			t.Fatal("the resize never settled")
+		}
+	}
+	if want := int(math.Ceil(settle / deltaTime())); quiet < want-1 || quiet > want+1 {
+		t.Errorf("settled after %d quiet updates, want about %d", quiet, want)
+	}
+	if allocs != 4 || imgW != 900 || imgH != 500 {
+		t.Errorf("after settling: %d allocations, image %dx%d, want 4 at 900x500", allocs, imgW, imgH)
+	}
+	for range 60 {
+		update()
+	}
+	if allocs != 4 {
+		t.Errorf("a settled window kept reallocating: %d", allocs)
+	}
+}
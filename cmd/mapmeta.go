@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// MapMeta is the optional sidecar file describing a map: how its parts
+// are laid out and the named places in it.
+type MapMeta struct {
+	// explicit part layout; when empty the parts glob is used
+	Parts []MetaPart `json:"parts"`
+	// world size; 0 derives it from the parts
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Labels []Label `json:"labels"`
+	// named spawn points; the first one is the default spawn
+	Spawns  []Label  `json:"spawns"`
+	Regions []Region `json:"regions"`
+	// optional grid of rooms with their own names, music and tint
+	Rooms *RoomGrid `json:"rooms"`
+	// optional road network fast travel can route along
+	Roads *MetaRoads `json:"roads"`
+	// directory of the sidecar, for resolving relative paths
+	dir string
+}
+
+// MetaPart places one map image at a world offset.
+type MetaPart struct {
+	File string `json:"file"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+// Region is a named world rectangle.
+type Region struct {
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	W    float64 `json:"w"`
+	H    float64 `json:"h"`
+}
+
+func (r Region) contains(x, y float64) bool {
+	return x >= r.X && y >= r.Y && x < r.X+r.W && y < r.Y+r.H
+}
+
+// loadMapMeta reads the sidecar file. It returns nil without an error when
+// the file doesn't exist. Relative part paths are resolved against the
+// sidecar's directory.
+func loadMapMeta(path string) (*MapMeta, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var meta MapMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	meta.dir = dir
+	for i, p := range meta.Parts {
+		if !filepath.IsAbs(p.File) {
+			meta.Parts[i].File = filepath.Join(dir, p.File)
+		}
+	}
+	return &meta, nil
+}
+
+// regionAt names the first region containing the world point, if any.
+func (m *MapMeta) regionAt(x, y float64) string {
+	if m == nil {
+		return ""
+	}
+	for _, r := range m.Regions {
+		if r.contains(x, y) {
+			return r.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// markerRemoveRadius is how close, in screen pixels, Shift+right-click has
+// to be to a marker to remove it.
+const markerRemoveRadius = 12.0
+
+var markerColor = color.RGBA{0xff, 0xd0, 0x20, 0xff}
+
+// updateMarkers places a marker under the cursor on right-click, snapped
+// into line with the markers around it with Alt held, or removes the one
+// under it with Shift held.
+func (g *Game) updateMarkers() {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		return
+	}
+	// Ctrl+right click adds a note instead
+	if g.cfg.Notes.Enabled && ebiten.IsKeyPressed(ebiten.KeyControl) {
+		return
+	}
+	cx, cy := ebiten.CursorPosition()
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		g.removeMarkerAt(float64(cx), float64(cy))
+		return
+	}
+	x, y := g.cursorWorld()
+	if g.snapping() {
+		x, y, _, _ = g.snapPoint(x, y)
+	}
+	g.addMarker(x, y)
+}
+
+// updateMarkerCycle steps through the markers with [ and ].
+func (g *Game) updateMarkerCycle() {
+	if g.justPressed(ebiten.KeyBracketRight) {
+		g.cycleMarker(1)
+	}
+	if g.justPressed(ebiten.KeyBracketLeft) {
+		g.cycleMarker(-1)
+	}
+	if g.justPressed(ebiten.KeyZ) {
+		g.frameMarkers()
+	}
+}
+
+// frameMarkers switches to the free camera and zooms it so every marker,
+// plus markerFrame.padding, is in view. A single marker is just centered.
+func (g *Game) frameMarkers() {
+	if g.second != nil || g.overview {
+		return
+	}
+	if len(g.markers) == 0 {
+		g.toast("No markers placed")
+		return
+	}
+	g.freeCam = true
+	g.panHeld = [4]float64{}
+	if len(g.markers) == 1 {
+		g.moveCameraTo(g.markers[0].X, g.markers[0].Y, false)
+		return
+	}
+	pad := g.cfg.MarkerFrame.Padding
+	b := bounds{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, m := range g.markers {
+		b.minX, b.minY = min(b.minX, m.X-pad), min(b.minY, m.Y-pad)
+		b.maxX, b.maxY = max(b.maxX, m.X+pad), max(b.maxY, m.Y+pad)
+	}
+	g.frameBox(b)
+	g.toast(fmt.Sprintf("Framed %d markers", len(g.markers)))
+}
+
+func (g *Game) addMarker(x, y float64) {
+	g.nextMarker++
+	m := Label{Name: fmt.Sprintf("Marker %d", g.nextMarker), X: math.Round(x), Y: math.Round(y)}
+	g.markers = append(g.markers, m)
+	g.rememberMarker(m)
+	g.rebuildPlaces()
+	g.toast(fmt.Sprintf("Placed %s at %.0f,%.0f", m.Name, m.X, m.Y))
+}
+
+// lastMarkerNumber is the highest n among markers named "Marker n", so new
+// markers don't repeat a name after others were removed.
+func lastMarkerNumber(markers []Label) int {
+	last := 0
+	for _, m := range markers {
+		var n int
+		if _, err := fmt.Sscanf(m.Name, "Marker %d", &n); err == nil {
+			last = max(last, n)
+		}
+	}
+	return last
+}
+
+// removeMarkerAt removes the marker closest to the screen position, if any
+// is within markerRemoveRadius.
+func (g *Game) removeMarkerAt(sx, sy float64) {
+	best, bestDist := -1, markerRemoveRadius
+	for i, m := range g.markers {
+		mx, my := g.worldToScreen(m.X, m.Y)
+		if d := math.Hypot(mx-sx, my-sy); d <= bestDist {
+			best, bestDist = i, d
+		}
+	}
+	if best < 0 {
+		return
+	}
+	name := g.markers[best].Name
+	g.markers = append(g.markers[:best], g.markers[best+1:]...)
+	if best < g.markerIdx {
+		g.markerIdx--
+	} else if best == g.markerIdx {
+		g.markerIdx = -1
+	}
+	g.rebuildPlaces()
+	g.toast("Removed " + name)
+}
+
+// cycleMarker focuses the next (dir 1) or previous (dir -1) marker,
+// wrapping around at the ends.
+func (g *Game) cycleMarker(dir int) {
+	n := len(g.markers)
+	if n == 0 {
+		g.toast("No markers placed")
+		return
+	}
+	if g.markerIdx < 0 || g.markerIdx >= n {
+		// start at the first marker going forward, the last going back
+		g.markerIdx = 0
+		if dir < 0 {
+			g.markerIdx = n - 1
+		}
+	} else {
+		g.markerIdx = ((g.markerIdx+dir)%n + n) % n
+	}
+	m := g.markers[g.markerIdx]
+	g.rememberMarker(m)
+	g.focusOn(m.X, m.Y)
+}
+
+// markerHistorySize is how many recently placed or visited markers
+// returnToMarker can step back through.
+const markerHistorySize = 16
+
+// rememberMarker records a marker as the most recently placed or visited.
+func (g *Game) rememberMarker(m Label) {
+	g.markerHistory = append(g.markerHistory, m)
+	if len(g.markerHistory) > markerHistorySize {
+		g.markerHistory = g.markerHistory[len(g.markerHistory)-markerHistorySize:]
+	}
+}
+
+// hasMarker reports whether m is still placed.
+func (g *Game) hasMarker(m Label) bool {
+	for _, o := range g.markers {
+		if o == m {
+			return true
+		}
+	}
+	return false
+}
+
+// returnToMarker goes back to the most recently placed or visited marker
+// on R, one further back with each press, and to the spawn once there are
+// none left.
+func (g *Game) returnToMarker() {
+	if !g.justPressed(ebiten.KeyR) {
+		return
+	}
+	for len(g.markerHistory) > 0 {
+		m := g.markerHistory[len(g.markerHistory)-1]
+		g.markerHistory = g.markerHistory[:len(g.markerHistory)-1]
+		// skip markers removed since, and the one the player is on
+		if !g.hasMarker(m) || math.Hypot(m.X-(g.px+float64(g.playerW)/2), m.Y-(g.py+float64(g.playerH)/2)) < 1 {
+			continue
+		}
+		g.toast("Back to " + m.Name)
+		g.focusOn(m.X, m.Y)
+		return
+	}
+	g.toast("Back to spawn")
+	g.focusOn(g.spawnX, g.spawnY)
+}
+
+// focusOn centers the view on a world point: the free camera moves there,
+// otherwise the player does and the camera follows.
+func (g *Game) focusOn(x, y float64) {
+	if g.cfg.Movement.FollowRoads && !g.freeCam {
+		g.walkTo(x, y)
+		return
+	}
+	g.teleport(x, y)
+}
+
+// markerText is the HUD line for the marker last cycled to.
+func (g *Game) markerText() string {
+	if g.markerIdx < 0 || g.markerIdx >= len(g.markers) {
+		return ""
+	}
+	return fmt.Sprintf("Marker %d/%d: %s", g.markerIdx+1, len(g.markers), g.markers[g.markerIdx].Name)
+}
+
+// drawMarkers draws a pin for every marker.
+func (g *Game) drawMarkers(screen *ebiten.Image) {
+	scale, tx, ty := g.worldTransform(g.screenW, g.screenH)
+	g.drawMarkersAt(screen, scale, tx, ty)
+}
+
+// drawMarkersAt draws the marker pins with the world transform
+// screen = world*scale + (tx, ty).
+func (g *Game) drawMarkersAt(screen *ebiten.Image, scale, tx, ty float64) {
+	for _, m := range g.markers {
+		x, y := m.X*scale+tx, m.Y*scale+ty
+		vector.FillCircle(screen, float32(x), float32(y), 5, markerColor, true)
+		vector.StrokeCircle(screen, float32(x), float32(y), 5, 1, color.Black, true)
+	}
+}
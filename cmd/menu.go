@@ -0,0 +1,95 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type menuItem struct {
+	label  string
+	action func(g *Game)
+}
+
+// menu is a vertical list of choices shown over the paused map. Cancelling
+// returns to parent, or closes the menu at the top level.
+type menu struct {
+	title  string
+	items  []menuItem
+	sel    int
+	parent *menu
+}
+
+var menuBackColor = color.RGBA{0, 0, 0, 0xa0}
+
+// pauseMenu is the menu opened with Escape or Start.
+func (g *Game) pauseMenu() *menu {
+	return &menu{
+		title: "Paused",
+		items: []menuItem{
+			{label: "Resume", action: func(g *Game) { g.menu = nil }},
+			{label: "Fast travel", action: func(g *Game) { g.menu = g.fastTravelMenu(g.menu) }},
+			{label: "Quit", action: func(g *Game) { g.quit = true }},
+		},
+	}
+}
+
+// fastTravelMenu lists every label and marker to jump to.
+func (g *Game) fastTravelMenu(parent *menu) *menu {
+	m := &menu{title: "Fast travel", parent: parent}
+	places := append(append([]Label(nil), g.cfg.Labels...), g.markers...)
+	for _, p := range places {
+		m.items = append(m.items, menuItem{label: p.Name, action: func(g *Game) { g.fastTravel(p) }})
+	}
+	if len(m.items) == 0 {
+		m.items = append(m.items, menuItem{label: "(no places)", action: func(g *Game) { g.menu = parent }})
+	}
+	return m
+}
+
+// updateMenu handles input while a menu is open. It reports whether the
+// menu consumed this update, in which case the game stays paused.
+func (g *Game) updateMenu() bool {
+	action := g.menuInput.next(g.keys)
+	if g.menu == nil {
+		if action == menuPause {
+			g.menu = g.pauseMenu()
+			return true
+		}
+		return false
+	}
+	m := g.menu
+	switch action {
+	case menuUp:
+		m.sel = (m.sel - 1 + len(m.items)) % len(m.items)
+	case menuDown:
+		m.sel = (m.sel + 1) % len(m.items)
+	case menuConfirm:
+		m.items[m.sel].action(g)
+	case menuCancel:
+		g.menu = m.parent
+	case menuPause:
+		g.menu = nil
+	}
+	return true
+}
+
+func (g *Game) drawMenu(screen *ebiten.Image) {
+	lineHeight := textLineHeight()
+	m := g.menu
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	vector.FillRect(screen, 0, 0, float32(sw), float32(sh), menuBackColor, false)
+	x := sw/2 - 80
+	y := sh/2 - lineHeight*(len(m.items)+2)/2
+	drawText(screen, m.title, x, y)
+	y += lineHeight * 2
+	for i, it := range m.items {
+		prefix := "  "
+		if i == m.sel {
+			prefix = "> "
+		}
+		drawText(screen, prefix+it.label, x, y)
+		y += lineHeight
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// longest side of the minimap's downscaled copy of the map
+	minimapThumbSize = 512
+	// gap between the minimap and the screen edges
+	minimapMargin = 8
+	// opacity change per second when fading in or out
+	minimapFadeSpeed = 3.0
+)
+
+var (
+	minimapBorderColor = color.RGBA{0xff, 0xff, 0xff, 0xc0}
+	minimapViewColor   = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	minimapPlayerColor = color.RGBA{0xff, 0x30, 0x30, 0xff}
+)
+
+// minimapRect places a box of size times the smaller screen dimension in
+// the configured corner, shaped like a world of ww x wh.
+func minimapRect(sw, sh, ww, wh int, cfg MinimapConfig) image.Rectangle {
+	if sw <= 0 || sh <= 0 || ww <= 0 || wh <= 0 {
+		return image.Rectangle{}
+	}
+	box := cfg.Size * float64(min(sw, sh))
+	w, h := fitSize(ww, wh, int(box), int(box))
+	x, y := minimapMargin, minimapMargin
+	switch cfg.Corner {
+	case "top-right":
+		x = sw - w - minimapMargin
+	case "bottom-left":
+		y = sh - h - minimapMargin
+	case "bottom-right":
+		x, y = sw-w-minimapMargin, sh-h-minimapMargin
+	}
+	return image.Rect(x, y, x+w, y+h)
+}
+
+// updateMinimapRect recomputes where the minimap goes, after a resize or
+// a map size change.
+func (g *Game) updateMinimapRect() {
+	if g.bg == nil {
+		return
+	}
+	bw, bh := g.bg.size()
+	g.minimapRect = minimapRect(g.screenW, g.screenH, bw, bh, g.cfg.Minimap)
+}
+
+// minimapThumb returns a downscaled copy of the current background,
+// rebuilding it when the background was swapped.
+func (g *Game) minimapThumb() *ebiten.Image {
+	if g.minimapImg != nil && g.minimapOf == g.bg {
+		return g.minimapImg
+	}
+	if g.minimapImg != nil {
+		g.minimapImg.Deallocate()
+	}
+	bw, bh := g.bg.size()
+	tw, th := fitSize(bw, bh, minimapThumbSize, minimapThumbSize)
+	g.minimapImg = ebiten.NewImage(max(tw, 1), max(th, 1))
+	g.bg.draw(g.minimapImg, len(g.cfg.Map.LOD.Thresholds), float64(tw)/float64(bw), 0, 0, 0, 1, ebiten.FilterLinear)
+	g.minimapOf = g.bg
+	return g.minimapImg
+}
+
+// updateMinimap fades the minimap out while the cursor is away from it.
+func (g *Game) updateMinimap() {
+	cfg := g.cfg.Minimap
+	target := cfg.Opacity
+	if cfg.FadeDistance > 0 {
+		cx, cy := ebiten.CursorPosition()
+		if rectDistance(g.minimapRect, cx, cy) > cfg.FadeDistance {
+			target = cfg.FadedOpacity
+		}
+	}
+	step := minimapFadeSpeed * deltaTime()
+	if g.minimapAlpha < target {
+		g.minimapAlpha = min(g.minimapAlpha+step, target)
+	} else {
+		g.minimapAlpha = max(g.minimapAlpha-step, target)
+	}
+}
+
+// rectDistance is how far (x, y) is from r, 0 inside it.
+func rectDistance(r image.Rectangle, x, y int) float64 {
+	dx := max(r.Min.X-x, 0, x-r.Max.X)
+	dy := max(r.Min.Y-y, 0, y-r.Max.Y)
+	return math.Hypot(float64(dx), float64(dy))
+}
+
+// drawMinimap draws the map thumbnail with the visible area and players.
+func (g *Game) drawMinimap(screen *ebiten.Image) {
+	r := g.minimapRect
+	if r.Empty() || g.minimapAlpha <= 0 {
+		return
+	}
+	thumb := g.minimapThumb()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(r.Dx())/float64(thumb.Bounds().Dx()), float64(r.Dy())/float64(thumb.Bounds().Dy()))
+	op.GeoM.Translate(float64(r.Min.X), float64(r.Min.Y))
+	op.ColorScale.ScaleAlpha(float32(g.minimapAlpha))
+	op.Filter = g.filter
+	screen.DrawImage(thumb, op)
+
+	bw, bh := g.bg.size()
+	sx := float64(r.Dx()) / float64(bw)
+	sy := float64(r.Dy()) / float64(bh)
+	alpha := func(c color.RGBA) color.RGBA {
+		a := g.minimapAlpha
+		return color.RGBA{uint8(float64(c.R) * a), uint8(float64(c.G) * a), uint8(float64(c.B) * a), uint8(float64(c.A) * a)}
+	}
+
+	// visible area
+	area := g.mapArea(g.screenW, g.screenH)
+	x0, y0 := g.screenToWorld(float64(area.Min.X), float64(area.Min.Y))
+	x1, y1 := g.screenToWorld(float64(area.Max.X), float64(area.Max.Y))
+	view := []bounds{{max(x0, 0), max(y0, 0), min(x1, float64(bw)), min(y1, float64(bh))}}
+	if g.cfg.Map.Wrap {
+		// the view can run off an edge and continue on the far side
+		view = wrappedRects(bounds{x0, y0, x1, y1}, float64(bw), float64(bh))
+	}
+	for _, v := range view {
+		vector.StrokeRect(screen, float32(float64(r.Min.X)+v.minX*sx), float32(float64(r.Min.Y)+v.minY*sy),
+			float32((v.maxX-v.minX)*sx), float32((v.maxY-v.minY)*sy), 1, alpha(minimapViewColor), false)
+	}
+
+	dot := func(x, y float64) {
+		cx := float64(r.Min.X) + (x+float64(g.playerW)/2)*sx
+		cy := float64(r.Min.Y) + (y+float64(g.playerH)/2)*sy
+		vector.FillCircle(screen, float32(cx), float32(cy), 3, alpha(minimapPlayerColor), true)
+	}
+	dot(g.px, g.py)
+	if g.second != nil {
+		dot(g.second.x, g.second.y)
+	}
+
+	if g.cfg.Minimap.Border {
+		vector.StrokeRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), 1, alpha(minimapBorderColor), false)
+	}
+}
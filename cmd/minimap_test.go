@@ -0,0 +1,57 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestMinimapRect(t *testing.T) {
+	cfg := MinimapConfig{Size: 0.25}
+	m := minimapMargin
+	tests := []struct {
+		corner         string
+		sw, sh, ww, wh int
+		want           image.Rectangle
+	}{
+		// a 2:1 world in a 200 pixel box on an 800x800 screen
+		{"top-left", 800, 800, 8192, 4096, image.Rect(m, m, m+200, m+100)},
+		{"", 800, 800, 8192, 4096, image.Rect(m, m, m+200, m+100)},
+		{"top-right", 800, 800, 8192, 4096, image.Rect(800-m-200, m, 800-m, m+100)},
+		{"bottom-left", 800, 800, 8192, 4096, image.Rect(m, 800-m-100, m+200, 800-m)},
+		{"bottom-right", 800, 800, 8192, 4096, image.Rect(800-m-200, 800-m-100, 800-m, 800-m)},
+		// the box follows the smaller screen side, the shape the world
+		{"top-left", 1600, 800, 4096, 8192, image.Rect(m, m, m+100, m+200)},
+		{"top-left", 0, 800, 4096, 4096, image.Rectangle{}},
+		{"top-left", 800, 800, 0, 4096, image.Rectangle{}},
+	}
+	for _, tt := range tests {
+		cfg.Corner = tt.corner
+		if got := minimapRect(tt.sw, tt.sh, tt.ww, tt.wh, cfg); got != tt.want {
+			t.Errorf("minimapRect(%d, %d, %d, %d, %q) = %v, want %v", tt.sw, tt.sh, tt.ww, tt.wh, tt.corner, got, tt.want)
+		}
+	}
+}
+
+func TestRectDistance(t *testing.T) {
+	r := image.Rect(100, 100, 200, 150)
+	tests := []struct {
+		x, y int
+		want float64
+	}{
+		{150, 120, 0},
+		{100, 100, 0},
+		{200, 150, 0},
+		{90, 120, 10},
+		{220, 120, 20},
+		{150, 80, 20},
+		{150, 160, 10},
+		// past a corner it is the distance to the corner
+		{97, 96, 5},
+		{203, 154, 5},
+	}
+	for _, tt := range tests {
+		if got := rectDistance(r, tt.x, tt.y); got != tt.want {
+			t.Errorf("rectDistance(%v, %d, %d) = %v, want %v", r, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+)
+
+// mipKernel weighs the four source pixels around each output pixel of a
+// smooth halving, in eighths; applied on both axes it approximates a
+// gaussian.
+var mipKernel = [4]uint32{1, 3, 3, 1}
+
+// toRGBA returns img as an *image.RGBA with its origin at (0, 0).
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Src)
+	return out
+}
+
+// halveSmooth downsamples img to half size with a separable 4-tap
+// kernel, clamping at the edges so parts don't darken along their seams.
+// It returns nil once the image can't be halved any further.
+func halveSmooth(img *image.RGBA) *image.RGBA {
+	sw, sh := img.Bounds().Dx(), img.Bounds().Dy()
+	w, h := sw/2, sh/2
+	if w < 1 || h < 1 {
+		return nil
+	}
+	// horizontal pass into a w x sh buffer, kept at 8x scale
+	tmp := make([]uint32, 4*w*sh)
+	for y := range sh {
+		row := img.Pix[y*img.Stride:]
+		for x := range w {
+			var acc [4]uint32
+			for k, wt := range mipKernel {
+				sx := min(max(2*x-1+k, 0), sw-1)
+				for c := range 4 {
+					acc[c] += wt * uint32(row[4*sx+c])
+				}
+			}
+			copy(tmp[4*(y*w+x):], acc[:])
+		}
+	}
+	// vertical pass, dividing out both passes' 8x
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			var acc [4]uint32
+			for k, wt := range mipKernel {
+				sy := min(max(2*y-1+k, 0), sh-1)
+				for c := range 4 {
+					acc[c] += wt * tmp[4*(sy*w+x)+c]
+				}
+			}
+			for c := range 4 {
+				out.Pix[y*out.Stride+4*x+c] = uint8((acc[c] + 32) / 64)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"testing"
+)
+
+func filledRGBA(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+// A flat image stays flat right up to its edges: clamping means the
+// border isn't blended with anything outside the part.
+func TestHalveSmoothFlat(t *testing.T) {
+	c := color.RGBA{200, 100, 50, 255}
+	out := halveSmooth(filledRGBA(9, 6, c))
+	if got := out.Bounds(); got != image.Rect(0, 0, 4, 3) {
+		t.Fatalf("halving 9x6 gave %v, want 4x3", got)
+	}
+	for y := range 3 {
+		for x := range 4 {
+			if got := out.RGBAAt(x, y); got != c {
+				t.Fatalf("pixel %d,%d = %v, want %v", x, y, got, c)
+			}
+		}
+	}
+}
+
+func TestHalveSmoothAverages(t *testing.T) {
+	// one-pixel stripes average out to grey
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := range 16 {
+		for x := range 16 {
+			v := uint8(0)
+			if x%2 == 0 {
+				v = 255
+			}
+			img.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+	out := halveSmooth(img)
+	// away from the clamped edges the kernel's weights split evenly
+	for y := range 8 {
+		for x := 1; x < 7; x++ {
+			if got := out.RGBAAt(x, y); got.R < 127 || got.R > 128 || got.A != 255 {
+				t.Fatalf("stripes halved to %v at %d,%d, want grey", got, x, y)
+			}
+		}
+	}
+}
+
+// A single bright pixel is spread over its neighbours by the 1-3-3-1
+// kernel rather than landing in just one output pixel.
+func TestHalveSmoothSpreads(t *testing.T) {
+	img := filledRGBA(8, 8, color.RGBA{0, 0, 0, 255})
+	img.SetRGBA(4, 4, color.RGBA{255, 255, 255, 255})
+	out := halveSmooth(img)
+	// pixel 4 feeds output 2 with weight 3 and output 1 with weight 1 on
+	// each axis: 9/64 and 3/64 of 255
+	tests := []struct {
+		x, y int
+		want uint8
+	}{
+		{2, 2, 36},
+		{1, 2, 12},
+		{2, 1, 12},
+		{1, 1, 4},
+		{3, 3, 0},
+	}
+	for _, tt := range tests {
+		if got := out.RGBAAt(tt.x, tt.y).R; got != tt.want {
+			t.Errorf("pixel %d,%d = %d, want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestHalveSmoothStops(t *testing.T) {
+	for _, s := range [][2]int{{1, 1}, {1, 8}, {8, 1}} {
+		if out := halveSmooth(filledRGBA(s[0], s[1], color.RGBA{})); out != nil {
+			t.Errorf("halving %dx%d gave %v, want nil", s[0], s[1], out.Bounds())
+		}
+	}
+}
+
+func TestToRGBA(t *testing.T) {
+	img := filledRGBA(4, 4, color.RGBA{1, 2, 3, 255})
+	if toRGBA(img) != img {
+		t.Error("toRGBA copied an RGBA image already at the origin")
+	}
+	sub := img.SubImage(image.Rect(1, 1, 3, 4)).(*image.RGBA)
+	out := toRGBA(sub)
+	if out.Bounds() != image.Rect(0, 0, 2, 3) {
+		t.Errorf("sub-image converted to %v, want 2x3 at the origin", out.Bounds())
+	}
+	n := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	n.SetNRGBA(0, 0, color.NRGBA{255, 0, 0, 128})
+	if got := toRGBA(n).RGBAAt(0, 0); got != (color.RGBA{128, 0, 0, 128}) {
+		t.Errorf("NRGBA pixel converted to %v, want premultiplied", got)
+	}
+}
+
+// noiseRGBA is a w x h image of random opaque pixels, like a photographic
+// map part.
+func noiseRGBA(w, h int) *image.RGBA {
+	r := rand.New(rand.NewPCG(1, 2))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(r.Uint32())
+	}
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+func BenchmarkHalveSmooth2048(b *testing.B) {
+	img := noiseRGBA(2048, 2048)
+	for b.Loop() {
+		halveSmooth(img)
+	}
+}
+
+// BenchmarkGaussianMipChain is the CPU side of building the gaussian LOD
+// levels for one decoded 2048x2048 part, converting it and halving it
+// four times. Uploading the levels needs a GPU and is left out.
+func BenchmarkGaussianMipChain(b *testing.B) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2048, 2048))
+	copy(src.Pix, noiseRGBA(2048, 2048).Pix)
+	for b.Loop() {
+		cur := toRGBA(src)
+		for range 4 {
+			cur = halveSmooth(cur)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+// edgeRamp scales a movement delta along one axis so it shrinks to zero as
+// pos approaches lo or hi. Only movement toward the nearby edge is slowed;
+// moving away from it is left untouched.
+func edgeRamp(pos, delta, lo, hi, margin float64) float64 {
+	if margin <= 0 || delta == 0 {
+		return delta
+	}
+	var dist float64
+	if delta < 0 {
+		dist = pos - lo
+	} else {
+		dist = hi - pos
+	}
+	if dist >= margin {
+		return delta
+	}
+	if dist <= 0 {
+		return 0
+	}
+	f := dist / margin
+	scaled := delta * f
+	// never step past the edge
+	if delta < 0 {
+		return max(scaled, -dist)
+	}
+	return min(scaled, dist)
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestEdgeRamp(t *testing.T) {
+	const lo, hi, margin = 0, 1000, 100
+	tests := []struct {
+		name       string
+		pos, delta float64
+		margin     float64
+		want       float64
+	}{
+		{"centre", 500, 4, margin, 4},
+		{"at margin", 900, 4, margin, 4},
+		{"halfway into margin", 950, 4, margin, 2},
+		{"quarter of margin left", 975, 4, margin, 1},
+		{"halfway into low margin", 50, -4, margin, -2},
+		{"at edge", 1000, 4, margin, 0},
+		{"past edge", 1010, 4, margin, 0},
+		{"away from edge", 990, -4, margin, -4},
+		{"away from low edge", 10, 4, margin, 4},
+		{"clamped to edge", 999, 200, margin, 1},
+		{"zero margin", 999, 4, 0, 4},
+		{"negative margin", 999, 4, -10, 4},
+		{"no movement", 999, 0, margin, 0},
+	}
+	for _, tt := range tests {
+		if got := edgeRamp(tt.pos, tt.delta, lo, hi, tt.margin); got != tt.want {
+			t.Errorf("%s: edgeRamp(%v, %v) = %v, want %v", tt.name, tt.pos, tt.delta, got, tt.want)
+		}
+	}
+}
+
+// The ramp falls off linearly: every step closer to the edge slows movement
+// by the same amount.
+func TestEdgeRampLinear(t *testing.T) {
+	const margin = 100
+	prev := edgeRamp(0, 1, -1000, margin, margin)
+	for pos := 10.0; pos <= margin; pos += 10 {
+		got := edgeRamp(pos, 1, -1000, margin, margin)
+		if d := prev - got; d < 0.0999 || d > 0.1001 {
+			t.Fatalf("edgeRamp at %v = %v, %v below the step before; want 0.1", pos, got, d)
+		}
+		prev = got
+	}
+}
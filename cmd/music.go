@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// defaultMusicPath is the track played when nothing asks for another.
+const defaultMusicPath = "assets/kakariko-village.mp3"
+
+// playMusic switches the background music to the track at path,
+// crossfading from the current one over audio.crossfade seconds. It does
+// nothing if that track is already playing.
+func (g *Game) playMusic(path string) {
+	if path == g.musicPath || g.audioContext == nil {
+		return
+	}
+	player, err := g.newMusicPlayer(path)
+	if err != nil {
+		slog.Warn("failed to switch music", "path", path, "err", err)
+		return
+	}
+	if g.oldMusic != nil {
+		g.oldMusic.Close()
+	}
+	g.oldMusic = g.audioPlayer
+	g.audioPlayer = player
+	g.musicPath = path
+	g.musicFadeLeft = g.cfg.Audio.Crossfade
+	player.SetVolume(g.cfg.Audio.Volume * g.musicGain() * g.musicFadeIn())
+	player.Play()
+}
+
+func (g *Game) newMusicPlayer(path string) (*audio.Player, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := decodeMusic(bytes.NewReader(data), g.audioContext.SampleRate())
+	if err != nil {
+		return nil, err
+	}
+	// the context rate is fixed, so a native-rate fallback can't be played
+	if stream.SampleRate() != g.audioContext.SampleRate() {
+		return nil, fmt.Errorf("sample rate %d Hz does not match %d Hz", stream.SampleRate(), g.audioContext.SampleRate())
+	}
+	return g.audioContext.NewPlayer(stream)
+}
+
+// musicFadeIn is the volume factor of the current track during a
+// crossfade, 1 once it is over.
+func (g *Game) musicFadeIn() float64 {
+	if g.musicFadeLeft <= 0 || g.cfg.Audio.Crossfade <= 0 {
+		return 1
+	}
+	return 1 - g.musicFadeLeft/g.cfg.Audio.Crossfade
+}
+
+// updateMusic advances a crossfade, fading the previous track out and
+// closing it at the end.
+func (g *Game) updateMusic() {
+	if g.musicFadeLeft > 0 {
+		g.musicFadeLeft -= deltaTime()
+	}
+	if g.oldMusic == nil {
+		return
+	}
+	if g.musicFadeLeft <= 0 {
+		g.oldMusic.Close()
+		g.oldMusic = nil
+		return
+	}
+	g.oldMusic.SetVolume(g.cfg.Audio.Volume * g.musicGain() * (1 - g.musicFadeIn()))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// placeIndex answers nearest-place queries over labels and markers.
+type placeIndex interface {
+	// nearest returns the place closest to (x, y) and its distance, or
+	// false when there are no places.
+	nearest(x, y float64) (Label, float64, bool)
+}
+
+// linearIndex scans every place; fine for the handful a map has.
+type linearIndex []Label
+
+func (l linearIndex) nearest(x, y float64) (Label, float64, bool) {
+	best, bestDist, ok := Label{}, math.Inf(1), false
+	for _, p := range l {
+		if d := math.Hypot(p.X-x, p.Y-y); d < bestDist {
+			best, bestDist, ok = p, d, true
+		}
+	}
+	return best, bestDist, ok
+}
+
+// placeHit is the result of the last nearest query.
+type placeHit struct {
+	place Label
+	dist  float64
+}
+
+var nearestColor = color.RGBA{0x40, 0xe0, 0xff, 0xff}
+
+// spatialIndexMin is the number of places from which a spatial grid is
+// used instead of a linear scan.
+const spatialIndexMin = 64
+
+// rebuildPlaces refreshes the index after labels or markers change.
+func (g *Game) rebuildPlaces() {
+	places := make([]Label, 0, len(g.cfg.Labels)+len(g.markers))
+	places = append(places, g.cfg.Labels...)
+	places = append(places, g.markers...)
+	g.rebuildMarkerGrid()
+	if len(places) >= spatialIndexMin {
+		g.places = newGridIndex(places)
+	} else {
+		g.places = linearIndex(places)
+	}
+}
+
+// updateNearest finds the place closest to the player while the readout is
+// shown.
+func (g *Game) updateNearest() {
+	g.nearest = nil
+	if !g.showNearest || g.places == nil {
+		return
+	}
+	p, d, ok := g.places.nearest(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2)
+	if ok {
+		g.nearest = &placeHit{place: p, dist: d}
+	}
+}
+
+// nearestText is the HUD line for the nearest place.
+func (g *Game) nearestText() string {
+	if g.nearest == nil {
+		return "Nearest: none"
+	}
+	return fmt.Sprintf("Nearest: %s (%s %s)", g.nearest.place.Name, g.formatUnits(g.nearest.dist), g.unitName())
+}
+
+// drawNearest rings the nearest place on the map.
+func (g *Game) drawNearest(screen *ebiten.Image) {
+	if g.nearest == nil {
+		return
+	}
+	x, y := g.worldToScreen(g.nearest.place.X, g.nearest.place.Y)
+	vector.StrokeCircle(screen, float32(x), float32(y), 10, 2, nearestColor, true)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// screen pixels from a note icon within which the cursor is over it
+	noteHitRadius = 10.0
+	// runes per line before note text wraps in the editor and tooltip
+	noteWrap = 48
+)
+
+var (
+	noteColor     = color.RGBA{0xf4, 0xe8, 0xb0, 0xff}
+	noteLineColor = color.RGBA{0x90, 0x80, 0x50, 0xff}
+)
+
+// Note is freeform text attached to a world point.
+type Note struct {
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Text string  `json:"text"`
+}
+
+// noteEditor is the text being edited for notes[idx]. The map is paused
+// while it is open.
+type noteEditor struct {
+	idx  int
+	text []rune
+}
+
+// noteAt is the index of the note whose icon is closest to the screen
+// position, or -1 when none is within noteHitRadius.
+func (g *Game) noteAt(sx, sy float64) int {
+	best, bestDist := -1, noteHitRadius
+	for i, n := range g.notes {
+		nx, ny := g.worldToScreen(n.X, n.Y)
+		if d := math.Hypot(nx-sx, ny-sy); d <= bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// updateNotes opens the note under the cursor on a left click, and adds a
+// note under the cursor with Ctrl and the right button.
+func (g *Game) updateNotes() {
+	if !g.cfg.Notes.Enabled {
+		return
+	}
+	cx, cy := ebiten.CursorPosition()
+	right := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) && ebiten.IsKeyPressed(ebiten.KeyControl)
+	left := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) &&
+		!ebiten.IsKeyPressed(ebiten.KeyControl) && !ebiten.IsKeyPressed(ebiten.KeyShift)
+	if !right && !left {
+		return
+	}
+	if i := g.noteAt(float64(cx), float64(cy)); i >= 0 {
+		g.openNote(i)
+		return
+	}
+	if right {
+		x, y := g.cursorWorld()
+		g.notes = append(g.notes, Note{X: math.Round(x), Y: math.Round(y)})
+		g.openNote(len(g.notes) - 1)
+	}
+}
+
+func (g *Game) openNote(i int) {
+	g.noteEdit = &noteEditor{idx: i, text: []rune(g.notes[i].Text)}
+}
+
+// closeNote stores the edited text, removing the note when it was left
+// empty.
+func (g *Game) closeNote() {
+	e := g.noteEdit
+	g.noteEdit = nil
+	text := strings.TrimSpace(string(e.text))
+	if text == "" {
+		g.notes = append(g.notes[:e.idx], g.notes[e.idx+1:]...)
+		g.toast("Note removed")
+		return
+	}
+	g.notes[e.idx].Text = text
+}
+
+// keyRepeats reports whether a held key should act this update: when it
+// goes down, and then repeatedly after the same delay menus use.
+func keyRepeats(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	delay := int(menuRepeatDelay * float64(ebiten.TPS()))
+	interval := max(int(menuRepeatInterval*float64(ebiten.TPS())), 1)
+	return d == 1 || d >= delay && (d-delay)%interval == 0
+}
+
+// updateNoteEditor handles typing while a note is open. It reports
+// whether the editor consumed this update, in which case the game stays
+// paused. Enter starts a new line; Escape or Ctrl+Enter closes the note.
+func (g *Game) updateNoteEditor() bool {
+	e := g.noteEdit
+	if e == nil {
+		return false
+	}
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl)
+	if g.justPressed(ebiten.KeyEscape) || ctrl && g.justPressed(ebiten.KeyEnter) {
+		g.closeNote()
+		return true
+	}
+	e.text = ebiten.AppendInputChars(e.text)
+	if !ctrl && keyRepeats(ebiten.KeyEnter) {
+		e.text = append(e.text, '\n')
+	}
+	if keyRepeats(ebiten.KeyBackspace) && len(e.text) > 0 {
+		e.text = e.text[:len(e.text)-1]
+	}
+	return true
+}
+
+// noteLines splits note text into display lines, wrapping long ones at
+// noteWrap runes.
+func noteLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		r := []rune(line)
+		for len(r) > noteWrap {
+			lines = append(lines, string(r[:noteWrap]))
+			r = r[noteWrap:]
+		}
+		lines = append(lines, string(r))
+	}
+	return lines
+}
+
+func drawNoteIcon(dst *ebiten.Image, x, y float32) {
+	vector.FillRect(dst, x-5, y-6, 10, 12, noteColor, false)
+	for i := range 3 {
+		vector.FillRect(dst, x-3, y-3+float32(i)*3, 6, 1, noteLineColor, false)
+	}
+	vector.StrokeRect(dst, x-5, y-6, 10, 12, 1, color.Black, false)
+}
+
+// drawNotes draws an icon for every note.
+func (g *Game) drawNotes(screen *ebiten.Image) {
+	scale, tx, ty := g.worldTransform(g.screenW, g.screenH)
+	for _, n := range g.notes {
+		drawNoteIcon(screen, float32(n.X*scale+tx), float32(n.Y*scale+ty))
+	}
+}
+
+// drawNoteBox draws lines of text on a dark box with its top left corner
+// at (x, y).
+func drawNoteBox(screen *ebiten.Image, lines []string, x, y int) {
+	const pad = 6
+	lineH := textLineHeight()
+	w := 0
+	for _, l := range lines {
+		w = max(w, textWidth(l))
+	}
+	vector.FillRect(screen, float32(x), float32(y), float32(w+2*pad), float32(lineH*len(lines)+2*pad), menuBackColor, false)
+	for i, l := range lines {
+		drawText(screen, l, x+pad, y+pad+i*lineH)
+	}
+}
+
+// drawNoteTooltip previews the note under the cursor, up to
+// notes.previewLines lines of it.
+func (g *Game) drawNoteTooltip(screen *ebiten.Image) {
+	if !g.cfg.Notes.Enabled || g.noteEdit != nil || g.menu != nil {
+		return
+	}
+	cx, cy := ebiten.CursorPosition()
+	i := g.noteAt(float64(cx), float64(cy))
+	if i < 0 {
+		return
+	}
+	lines := noteLines(g.notes[i].Text)
+	if n := max(g.cfg.Notes.PreviewLines, 1); len(lines) > n {
+		lines = append(lines[:n:n], "...")
+	}
+	drawNoteBox(screen, lines, cx+16, cy+16)
+}
+
+// drawNoteEditor draws the open note over the dimmed map, with a caret at
+// the end of the text.
+func (g *Game) drawNoteEditor(screen *ebiten.Image) {
+	e := g.noteEdit
+	n := g.notes[e.idx]
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	vector.FillRect(screen, 0, 0, float32(sw), float32(sh), menuBackColor, false)
+	lines := []string{fmt.Sprintf("Note at %s,%s", g.formatUnits(n.X), g.formatUnits(n.Y)), ""}
+	lines = append(lines, noteLines(string(e.text)+"_")...)
+	lines = append(lines, "", "Enter: new line  Esc: done (empty removes the note)")
+	lineH := textLineHeight()
+	drawNoteBox(screen, lines, sw/2-textWidth(lines[len(lines)-1])/2, sh/2-lineH*len(lines)/2)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"image"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// loadOverlay loads the annotation layer drawn over the map, a one-part
+// background aligned with the map's top-left corner. It returns nil when
+// none is configured or it can't be loaded.
+func loadOverlay(cfg OverlayConfig, bg *background) *background {
+	if cfg.Image == "" {
+		return nil
+	}
+	src, err := decodeImage(cfg.Image)
+	if err != nil {
+		slog.Warn("failed to load map overlay", "path", cfg.Image, "err", err)
+		return nil
+	}
+	ov := newBackground([]string{cfg.Image}, []image.Image{src}, 0)
+	if bw, bh := bg.size(); ov.w != bw || ov.h != bh {
+		slog.Warn("map overlay size does not match the map, aligning top-left", "path", cfg.Image, "overlay", image.Pt(ov.w, ov.h), "map", image.Pt(bw, bh))
+	}
+	ov.wrap = bg.wrap
+	return ov
+}
+
+// updateOverlay toggles the overlay with O.
+func (g *Game) updateOverlay() {
+	if g.overlay != nil && g.justPressed(ebiten.KeyO) {
+		g.showOverlay = !g.showOverlay
+	}
+}
+
+// drawOverlay draws the overlay with the map's world transform, right
+// over the map itself.
+func (g *Game) drawOverlay(world *ebiten.Image, scale, tx, ty float64) {
+	if g.overlay == nil || !g.showOverlay {
+		return
+	}
+	a := float32(max(min(g.cfg.Map.Overlay.Opacity, 1), 0))
+	g.overlay.draw(world, 0, scale, tx, ty, g.cfg.Map.SeamOverlap, a, g.filter)
+}
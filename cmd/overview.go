@@ -0,0 +1,91 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"log/slog"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// toggleOverview switches between the normal camera and a view of the
+// whole map. The camera is restored exactly on the way back.
+func (g *Game) toggleOverview() {
+	if g.bg == nil {
+		return
+	}
+	if !g.overview {
+		g.overview = true
+		g.preOverview = [3]float64{g.vx, g.vy, g.zoom}
+		g.stopCamera()
+		g.fitOverview()
+		return
+	}
+	g.overview = false
+	g.vx, g.vy, g.zoom = g.preOverview[0], g.preOverview[1], g.preOverview[2]
+	// the window may have been resized meanwhile
+	g.updateMinZoom()
+	if !g.freeCam {
+		g.follow(false)
+	}
+	g.clampView()
+	g.locate()
+}
+
+// fitOverview zooms to fit the whole map and centers it. The normal clamp
+// assumes a view smaller than the map, so it is skipped here; the map is
+// letterboxed along whichever axis has room to spare.
+func (g *Game) fitOverview() {
+	g.zoom = g.fitZoom(g.screenW, g.screenH)
+	bw, bh := g.bg.size()
+	vw, vh := g.viewSize()
+	g.vx = float64(bw)/2 - vw/2
+	g.vy = float64(bh)/2 - vh/2
+}
+
+// crosshairLines are the horizontal and vertical lines through the screen
+// point (x, y) spanning b, each as x0, y0, x1, y1. b need not start at the
+// origin when the map is drawn into a sub-image.
+func crosshairLines(b image.Rectangle, x, y float64) [2][4]float64 {
+	x0, y0, x1, y1 := float64(b.Min.X), float64(b.Min.Y), float64(b.Max.X), float64(b.Max.Y)
+	return [2][4]float64{{x0, y, x1, y}, {x, y0, x, y1}}
+}
+
+// drawCrosshair draws lines across the whole screen meeting at the player
+// while in the overview, so their place on the map stands out.
+func (g *Game) drawCrosshair(screen *ebiten.Image) {
+	cfg := g.cfg.Overview.Crosshair
+	if !g.overview || !cfg.Enabled {
+		return
+	}
+	if g.crosshairColor == nil {
+		c, err := parseHexColor(cfg.Color)
+		if err != nil {
+			slog.Warn("invalid crosshair color", "err", err)
+			c = color.RGBA{0xff, 0x40, 0x40, 0xc0}
+		}
+		g.crosshairColor = &c
+	}
+	x, y := g.worldToScreen(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2)
+	width := float32(max(cfg.Thickness, 1))
+	for _, l := range crosshairLines(screen.Bounds(), x, y) {
+		dashedLine(screen, l[0], l[1], l[2], l[3], cfg.Dash, width, *g.crosshairColor)
+	}
+}
+
+// dashedLine strokes a line in dash long pieces with gaps of the same
+// length between them, or solid when dash is 0.
+func dashedLine(screen *ebiten.Image, x0, y0, x1, y1, dash float64, width float32, c color.Color) {
+	length := math.Hypot(x1-x0, y1-y0)
+	if dash <= 0 || length == 0 {
+		vector.StrokeLine(screen, float32(x0), float32(y0), float32(x1), float32(y1), width, c, true)
+		return
+	}
+	ux, uy := (x1-x0)/length, (y1-y0)/length
+	for d := 0.0; d < length; d += 2 * dash {
+		e := min(d+dash, length)
+		vector.StrokeLine(screen, float32(x0+ux*d), float32(y0+uy*d), float32(x0+ux*e), float32(y0+uy*e), width, c, true)
+	}
+}
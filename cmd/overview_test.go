@@ -0,0 +1,67 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// Entering and leaving the overview puts the camera back exactly where it
+// was, at a position the view clamp accepts, and keeps following the
+// player from there.
+func TestOverviewRestoresView(t *testing.T) {
+	for _, corner := range [][2]float64{{4096, 2048}, {0, 0}, {8192 - 32, 4096 - 32}} {
+		g := newTestGame(8192, 4096)
+		g.px, g.py = corner[0], corner[1]
+		g.follow(true)
+		g.clampView()
+		vx, vy, zoom := g.vx, g.vy, g.zoom
+
+		g.toggleOverview()
+		if !g.overview {
+			t.Fatal("toggleOverview didn't enter the overview")
+		}
+		// the whole map is in view, centered
+		if vw, _ := g.viewSize(); vw < 8192-1e-6 {
+			t.Errorf("overview shows %v world pixels across, want the whole 8192", vw)
+		}
+
+		g.toggleOverview()
+		if g.overview {
+			t.Fatal("toggleOverview didn't leave the overview")
+		}
+		if g.vx != vx || g.vy != vy || g.zoom != zoom {
+			t.Errorf("player at %v: view after the overview is %v,%v zoom %v, want %v,%v zoom %v", corner, g.vx, g.vy, g.zoom, vx, vy, zoom)
+		}
+		if math.IsNaN(g.vx) || math.IsNaN(g.vy) || math.IsInf(g.vx, 0) || math.IsInf(g.vy, 0) {
+			t.Fatalf("player at %v: view %v,%v is not finite", corner, g.vx, g.vy)
+		}
+		if cx, cy := g.clampedView(g.vx, g.vy); cx != g.vx || cy != g.vy {
+			t.Errorf("player at %v: view %v,%v is outside the clamp, which puts it at %v,%v", corner, g.vx, g.vy, cx, cy)
+		}
+
+		// following again from the restored view stays put
+		g.follow(true)
+		g.clampView()
+		if g.vx != vx || g.vy != vy {
+			t.Errorf("player at %v: following after the overview moved the view to %v,%v, want %v,%v", corner, g.vx, g.vy, vx, vy)
+		}
+	}
+}
+
+func TestCrosshairLines(t *testing.T) {
+	tests := []struct {
+		name string
+		b    image.Rectangle
+		want [2][4]float64
+	}{
+		{"whole screen", image.Rect(0, 0, 640, 480), [2][4]float64{{0, 200, 640, 200}, {300, 0, 300, 480}}},
+		// a frame insets the map into a sub-image of the screen
+		{"framed map", image.Rect(40, 30, 600, 450), [2][4]float64{{40, 200, 600, 200}, {300, 30, 300, 450}}},
+	}
+	for _, tt := range tests {
+		if got := crosshairLines(tt.b, 300, 200); got != tt.want {
+			t.Errorf("%s: crosshairLines(%v) = %v, want %v", tt.name, tt.b, got, tt.want)
+		}
+	}
+}
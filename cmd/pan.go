@@ -0,0 +1,102 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// panSpeedFactor is the pan speed multiplier after holding a pan key for
+// held seconds. The linear curve gains accel per second up to maxFactor;
+// "ease-in" reaches maxFactor at the same time but starts slower, so taps
+// stay precise.
+func panSpeedFactor(held, accel, maxFactor float64, curve string) float64 {
+	if curve == "ease-in" && accel > 0 && maxFactor > 1 {
+		ramp := (maxFactor - 1) / accel
+		u := min(held/ramp, 1)
+		return 1 + (maxFactor-1)*u*u
+	}
+	return max(min(1+accel*held, maxFactor), 1)
+}
+
+// panKeys are the free-cam pan keys in panHeld order, with the direction
+// each moves in.
+var panKeys = [4]struct {
+	key    ebiten.Key
+	dx, dy float64
+}{
+	{ebiten.KeyRight, 1, 0},
+	{ebiten.KeyLeft, -1, 0},
+	{ebiten.KeyDown, 0, 1},
+	{ebiten.KeyUp, 0, -1},
+}
+
+// updatePan moves the free camera with the arrow keys at a speed given in
+// screen pixels per second, so it feels the same at any zoom or tile size.
+// Each direction accelerates on its own hold timer, reset on release.
+func (g *Game) updatePan() {
+	dt := deltaTime()
+	cam := g.cfg.Camera
+	var mx, my float64
+	for i, k := range panKeys {
+		if !g.keys.pressed(k.key) {
+			g.panHeld[i] = 0
+			continue
+		}
+		g.panHeld[i] += dt
+		f := panSpeedFactor(g.panHeld[i], cam.PanAccel, cam.PanMaxFactor, cam.PanCurve)
+		mx += k.dx * f
+		my += k.dy * f
+	}
+	if mx == 0 && my == 0 {
+		return
+	}
+	g.stopCamera()
+	speed := cam.PanSpeed
+	if g.keys.pressed(ebiten.KeyShift) {
+		speed *= cam.PanFastFactor
+	}
+	scale, _, _ := g.viewTransform(g.screenW, g.screenH)
+	if scale <= 0 {
+		return
+	}
+	g.vx += mx * speed * dt / scale
+	g.vy += my * speed * dt / scale
+	g.driveCamera(mx*speed/scale, my*speed/scale)
+}
+
+// edgeDirection is -1, 0 or 1 depending on whether pos is within margin of
+// the low or high end of [0, size).
+func edgeDirection(pos, size int, margin float64) float64 {
+	switch {
+	case float64(pos) < margin:
+		return -1
+	case float64(pos) >= float64(size)-margin:
+		return 1
+	}
+	return 0
+}
+
+// updateEdgeScroll pans the free camera while the cursor rests near a
+// screen edge. The caller clamps the view afterwards.
+func (g *Game) updateEdgeScroll() {
+	es := g.cfg.Camera.EdgeScroll
+	if !es.Enabled || !ebiten.IsFocused() || g.screenW <= 0 || g.screenH <= 0 {
+		return
+	}
+	cx, cy := ebiten.CursorPosition()
+	// the cursor is reported at its last position after leaving the window
+	if cx < 0 || cy < 0 || cx >= g.screenW || cy >= g.screenH {
+		return
+	}
+	ux := edgeDirection(cx, g.screenW, es.Margin)
+	uy := edgeDirection(cy, g.screenH, es.Margin)
+	if ux == 0 && uy == 0 {
+		return
+	}
+	scale, _, _ := g.viewTransform(g.screenW, g.screenH)
+	if scale <= 0 {
+		return
+	}
+	g.stopCamera()
+	dt := deltaTime()
+	g.vx += ux * es.Speed * dt / scale
+	g.vy += uy * es.Speed * dt / scale
+	g.driveCamera(ux*es.Speed/scale, uy*es.Speed/scale)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestPanSpeedFactor(t *testing.T) {
+	tests := []struct {
+		name              string
+		held, accel, maxF float64
+		curve             string
+		want              float64
+	}{
+		{"linear at start", 0, 1.5, 4, "", 1},
+		{"linear after a second", 1, 1.5, 4, "linear", 2.5},
+		{"linear at max", 2, 1.5, 4, "", 4},
+		{"linear past max", 10, 1.5, 4, "", 4},
+		{"no accel", 5, 0, 4, "", 1},
+		{"max below 1", 5, 1.5, 0.5, "", 1},
+		{"ease-in at start", 0, 1.5, 4, "ease-in", 1},
+		{"ease-in halfway", 1, 1.5, 4, "ease-in", 1.75},
+		{"ease-in at max", 2, 1.5, 4, "ease-in", 4},
+		{"ease-in past max", 10, 1.5, 4, "ease-in", 4},
+		{"ease-in without accel is linear", 5, 0, 4, "ease-in", 1},
+		{"unknown curve is linear", 1, 1.5, 4, "cubic", 2.5},
+	}
+	for _, tt := range tests {
+		got := panSpeedFactor(tt.held, tt.accel, tt.maxF, tt.curve)
+		if math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: panSpeedFactor(%v, %v, %v, %q) = %v, want %v",
+				tt.name, tt.held, tt.accel, tt.maxF, tt.curve, got, tt.want)
+		}
+	}
+}
+
+// Ease-in starts slower than linear and catches up at the same time.
+func TestPanSpeedFactorEaseInBelowLinear(t *testing.T) {
+	for held := 0.05; held < 2; held += 0.05 {
+		lin := panSpeedFactor(held, 1.5, 4, "linear")
+		ease := panSpeedFactor(held, 1.5, 4, "ease-in")
+		if ease > lin {
+			t.Errorf("at %.2fs ease-in %v is faster than linear %v", held, ease, lin)
+		}
+	}
+}
+
+func TestEdgeDirection(t *testing.T) {
+	tests := []struct {
+		pos, size int
+		margin    float64
+		want      float64
+	}{
+		{0, 640, 24, -1},
+		{23, 640, 24, -1},
+		{24, 640, 24, 0},
+		{320, 640, 24, 0},
+		{615, 640, 24, 0},
+		{616, 640, 24, 1},
+		{639, 640, 24, 1},
+		{0, 640, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := edgeDirection(tt.pos, tt.size, tt.margin); got != tt.want {
+			t.Errorf("edgeDirection(%d, %d, %v) = %v, want %v", tt.pos, tt.size, tt.margin, got, tt.want)
+		}
+	}
+}
+
+// Holding a pan key speeds the free camera up; letting go resets it.
+func TestUpdatePanAccelerates(t *testing.T) {
+	g := newTestGame(100000, 1000)
+	keys := &fakeKeys{}
+	g.keys = keys
+	g.freeCam = true
+	step := func() float64 {
+		x := g.vx
+		g.updatePan()
+		return g.vx - x
+	}
+	keys.hold(ebiten.KeyRight)
+	first := step()
+	if first <= 0 {
+		t.Fatalf("holding right moved the camera by %v", first)
+	}
+	var last float64
+	for range 120 {
+		keys.hold(ebiten.KeyRight)
+		last = step()
+	}
+	if r := last / first; math.Abs(r-g.cfg.Camera.PanMaxFactor) > 0.1 {
+		t.Errorf("after two seconds the pan is %v times the first step, want about %v", r, g.cfg.Camera.PanMaxFactor)
+	}
+	keys.hold()
+	step()
+	keys.hold(ebiten.KeyRight)
+	if again := step(); math.Abs(again-first) > 1e-9 {
+		t.Errorf("after release the first step is %v, want %v", again, first)
+	}
+	keys.hold(ebiten.KeyRight, ebiten.KeyShift)
+	fast := step()
+	keys.hold(ebiten.KeyRight)
+	slow := step()
+	// the hold timer advanced a step between them, so compare the ratio loosely
+	if r := fast / slow; r < g.cfg.Camera.PanFastFactor*0.9 {
+		t.Errorf("Shift panned %v times faster, want about %v", r, g.cfg.Camera.PanFastFactor)
+	}
+}
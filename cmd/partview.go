@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"log/slog"
+	"path/filepath"
+)
+
+// stitchedView is what belongs to the stitched map while a part is shown
+// on its own: the exploration, the heat and where the player was, none of
+// which fit a lone part. finishSwap puts it aside when a part view begins
+// and back when the stitched map returns.
+type stitchedView struct {
+	fog            *fogLayer
+	heatmap        *heatmap
+	exploreReached int
+	px, py         float64
+}
+
+// cycleMapPart shows the next map part on its own, undoing the stitching,
+// or the stitched map again after the last one, so each part can be
+// checked by itself. It is bound to F9 in debug mode and does nothing on
+// a map of one part.
+func (g *Game) cycleMapPart() {
+	if g.soloPart == 0 {
+		if len(g.bg.parts) <= 1 {
+			return
+		}
+		g.partPaths = g.partPaths[:0]
+		for _, p := range g.bg.parts {
+			g.partPaths = append(g.partPaths, p.path)
+		}
+	}
+	g.soloPart = (g.soloPart + 1) % (len(g.partPaths) + 1)
+	if g.soloPart == 0 {
+		g.reloadBackground()
+		g.toast("Map parts: stitched")
+		return
+	}
+	path := g.partPaths[g.soloPart-1]
+	src, err := decodeImage(path)
+	if err != nil {
+		slog.Warn("failed to decode map part", "path", path, "err", err)
+		g.toast("Failed to decode " + filepath.Base(path))
+		return
+	}
+	g.swapBackground(newBackgroundAt([]string{path}, []image.Image{src}, []image.Point{{}}))
+	g.toast(fmt.Sprintf("Map part %d/%d: %s", g.soloPart, len(g.partPaths), filepath.Base(path)))
+}
+
+// swapPartView sets the stitched map's state aside when a part view
+// starts and puts it back when the stitched map returns. It reports
+// whether it restored it.
+func (g *Game) swapPartView() bool {
+	if g.soloPart != 0 {
+		if g.stitched == nil {
+			g.stitched = &stitchedView{g.fog, g.heatmap, g.exploreReached, g.px, g.py}
+		}
+		return false
+	}
+	s := g.stitched
+	if s == nil {
+		return false
+	}
+	g.stitched = nil
+	g.fog, g.heatmap, g.exploreReached = s.fog, s.heatmap, s.exploreReached
+	g.px, g.py = s.px, s.py
+	return true
+}
+
+// centerOnMap puts the player, and the camera, in the middle of the map.
+func (g *Game) centerOnMap() {
+	bw, bh := g.bg.size()
+	g.teleport(float64(bw)/2, float64(bh)/2)
+}
@@ -0,0 +1,95 @@
+package main
+
+import "testing"
+
+// stitchedTestGame is a test game on a 4000x2000 map of two parts, with
+// some of it explored and time spent in it.
+func stitchedTestGame() *Game {
+	g := newTestGame(4000, 2000)
+	g.bg.parts = []mapPart{{path: "west.png", w: 2000, h: 2000}, {path: "east.png", x: 2000, w: 2000, h: 2000}}
+	g.cfg.Map.SwapFade = 0
+	g.fog = newFogLayer(4000, 2000)
+	g.heatmap = newHeatmap(4000, 2000, g.tileW, g.tileH)
+	g.px, g.py = 3100, 700
+	g.fog.reveal(3100, 700, fogRevealRadius)
+	g.heatmap.add(1, 1, 5)
+	g.exploreReached = 10
+	return g
+}
+
+// Showing a part on its own and coming back leaves the stitched map's
+// exploration, heat and player position as they were, and saves made
+// meanwhile keep them too.
+func TestPartViewKeepsStitchedState(t *testing.T) {
+	g := stitchedTestGame()
+	fog, heat := g.fog, g.heatmap
+	seen := fog.seen
+
+	g.soloPart = 1
+	g.swapBackground(&background{w: 2000, h: 2000})
+	if g.fog == fog || g.heatmap == heat {
+		t.Fatal("the part view drew on the stitched map's layers")
+	}
+	if g.exploreReached != 0 {
+		t.Errorf("part view starts at milestone %d, want 0", g.exploreReached)
+	}
+	if cx, cy := g.px+16, g.py+16; cx != 1000 || cy != 1000 {
+		t.Errorf("player at %v, %v in the part view, want its middle 1000, 1000", cx, cy)
+	}
+	st := g.saveState()
+	if st.Position.X != 3100 || st.Position.Y != 700 {
+		t.Errorf("saved during the part view at %v, %v, want the stitched 3100, 700", st.Position.X, st.Position.Y)
+	}
+	if st.Fog == nil || st.Fog.Cols != fog.cols {
+		t.Errorf("saved fog during the part view is not the stitched one: %+v", st.Fog)
+	}
+
+	// on to the next part, then back to the stitched map
+	g.soloPart = 2
+	g.px, g.py = 10, 10
+	g.swapBackground(&background{w: 2000, h: 2000})
+	g.soloPart = 0
+	g.swapBackground(&background{w: 4000, h: 2000})
+	if g.fog != fog || g.heatmap != heat || g.fog.seen != seen {
+		t.Error("the stitched map's fog or heatmap was not restored")
+	}
+	if g.exploreReached != 10 {
+		t.Errorf("exploration milestone %d after the part view, want 10", g.exploreReached)
+	}
+	if g.px != 3100 || g.py != 700 {
+		t.Errorf("player at %v, %v after the part view, want 3100, 700", g.px, g.py)
+	}
+	if g.stitched != nil {
+		t.Error("stitched state still set aside on the stitched map")
+	}
+}
+
+// With swap fading the player is centered once the part has faded in, on
+// the part rather than on the map it replaced.
+func TestPartViewCentersAfterFade(t *testing.T) {
+	g := stitchedTestGame()
+	g.cfg.Map.SwapFade = 0.5
+	g.soloPart = 1
+	g.swapBackground(&background{w: 1000, h: 800})
+	if g.px != 3100 || g.py != 700 {
+		t.Fatalf("player moved to %v, %v before the fade finished", g.px, g.py)
+	}
+	for range 60 {
+		g.updateSwap()
+	}
+	if g.swap != nil {
+		t.Fatal("swap still fading after a second")
+	}
+	if cx, cy := g.px+16, g.py+16; cx != 500 || cy != 400 {
+		t.Errorf("player at %v, %v after the fade, want 500, 400", cx, cy)
+	}
+}
+
+func TestCycleMapPartSinglePart(t *testing.T) {
+	g := newTestGame(1000, 1000)
+	g.bg.parts = []mapPart{{path: "only.png", w: 1000, h: 1000}}
+	g.cycleMapPart()
+	if g.soloPart != 0 || g.stitched != nil || g.swap != nil {
+		t.Errorf("cycling a one-part map changed the view: part %d", g.soloPart)
+	}
+}
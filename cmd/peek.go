@@ -0,0 +1,81 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// peekHeld reports whether the configured drag button is held.
+func (g *Game) peekHeld() bool {
+	if g.cfg.Camera.Peek.Button == "ctrl-left" {
+		return ebiten.IsKeyPressed(ebiten.KeyControl) && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	}
+	return ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle)
+}
+
+// peekCamera pans the camera by dragging with the peek button, in free
+// camera mode or while following. A following camera is let go for the
+// drag and eases back onto the player once the button is released, or
+// after camera.peek.timeout seconds. It reports whether it is moving the
+// camera this update, so the caller leaves following alone.
+func (g *Game) peekCamera() bool {
+	pc := g.cfg.Camera.Peek
+	if !pc.Enabled {
+		return false
+	}
+	held := g.peekHeld()
+	if !held {
+		g.peekSpent = false
+	}
+	cx, cy := ebiten.CursorPosition()
+	if held && !g.peeking && !g.peekSpent {
+		g.peeking = true
+		g.peekReturning = false
+		g.peekTime = 0
+		g.peekStartX, g.peekStartY = cx, cy
+		g.peekViewX, g.peekViewY = g.vx, g.vy
+		g.stopCamera()
+	}
+	if g.peeking {
+		g.peekTime += deltaTime()
+		timedOut := !g.freeCam && pc.Timeout > 0 && g.peekTime >= pc.Timeout
+		if !held || timedOut {
+			g.peeking = false
+			g.peekSpent = held
+			g.peekReturning = !g.freeCam
+		} else {
+			// measured from where the drag started so the map stays
+			// under the cursor even as the view gets clamped
+			scale, _, _ := g.viewTransform(g.screenW, g.screenH)
+			if scale > 0 {
+				g.vx = g.peekViewX - float64(cx-g.peekStartX)/scale
+				g.vy = g.peekViewY - float64(cy-g.peekStartY)/scale
+			}
+			return true
+		}
+	}
+	if g.peekReturning {
+		if g.freeCam {
+			g.peekReturning = false
+			return false
+		}
+		return g.peekReturn()
+	}
+	return false
+}
+
+// peekReturn eases the camera back onto the player after a peek, reporting
+// whether it is still on its way.
+func (g *Game) peekReturn() bool {
+	vw, vh := g.viewSize()
+	tx, ty := g.clampedView(g.px+float64(g.playerW)/2-vw/2, g.py+float64(g.playerH)/2-vh/2)
+	dt := deltaTime()
+	g.vx = easeToward(g.vx, tx, dt, g.cfg.Camera.Peek.ReturnTime)
+	g.vy = easeToward(g.vy, ty, dt, g.cfg.Camera.Peek.ReturnTime)
+	scale, _, _ := g.viewTransform(g.screenW, g.screenH)
+	if scale <= 0 || math.Hypot(tx-g.vx, ty-g.vy)*scale < 0.5 {
+		g.peekReturning = false
+	}
+	return g.peekReturning
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// frameSamples is how many recent frame times the graph shows.
+const frameSamples = 120
+
+// frameGraph is a fixed-size ring buffer of frame times.
+type frameGraph struct {
+	samples [frameSamples]float64 // milliseconds
+	next    int
+	count   int
+	last    time.Time
+}
+
+// record stores the time since the previous call.
+func (f *frameGraph) record(now time.Time) {
+	if !f.last.IsZero() {
+		f.samples[f.next] = float64(now.Sub(f.last).Microseconds()) / 1000
+		f.next = (f.next + 1) % frameSamples
+		f.count = min(f.count+1, frameSamples)
+	}
+	f.last = now
+}
+
+// at returns the i-th sample, oldest first.
+func (f *frameGraph) at(i int) float64 {
+	start := (f.next - f.count + frameSamples) % frameSamples
+	return f.samples[(start+i)%frameSamples]
+}
+
+var (
+	frameBarColor  = color.RGBA{0x40, 0xc0, 0x40, 0xff}
+	frameSlowColor = color.RGBA{0xe0, 0x30, 0x30, 0xff}
+	frameBackColor = color.RGBA{0, 0, 0, 0x90}
+)
+
+// drawFrameGraph draws the frame times as a sparkline in the bottom-right
+// corner, with frames slower than the threshold in red.
+func (g *Game) drawFrameGraph(screen *ebiten.Image) {
+	const (
+		width  = frameSamples * 2
+		height = 60
+		// frame time at the top of the graph
+		maxMs = 50.0
+	)
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	x0 := float32(sw - width - 8)
+	y0 := float32(sh - height - 8)
+	vector.FillRect(screen, x0, y0, width, height, frameBackColor, false)
+
+	threshold := g.cfg.Perf.FrameThresholdMs
+	for i := 0; i < g.frames.count; i++ {
+		ms := g.frames.at(i)
+		h := float32(min(ms/maxMs, 1) * height)
+		c := frameBarColor
+		if threshold > 0 && ms > threshold {
+			c = frameSlowColor
+		}
+		vector.FillRect(screen, x0+float32(i*2), y0+height-h, 2, h, c, false)
+	}
+	drawText(screen, fmt.Sprintf("%.0f FPS", ebiten.ActualFPS()), int(x0)+4, int(y0)+2)
+}
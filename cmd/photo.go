@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// maxPhotoSize caps each side of a photo export, in pixels, to stay within
+// what the GPU can allocate.
+const maxPhotoSize = 8192
+
+// photoResult is what the photo writer reports back to the game loop.
+type photoResult struct {
+	path string
+	err  error
+}
+
+// photoRect is the world area a photo covers: the whole map, or what is
+// visible on screen right now.
+func (g *Game) photoRect(wholeMap bool) (x, y, w, h float64) {
+	if wholeMap {
+		bw, bh := g.bg.size()
+		return 0, 0, float64(bw), float64(bh)
+	}
+	area := g.mapArea(g.screenW, g.screenH)
+	x0, y0 := g.screenToWorld(float64(area.Min.X), float64(area.Min.Y))
+	x1, y1 := g.screenToWorld(float64(area.Max.X), float64(area.Max.Y))
+	return x0, y0, x1 - x0, y1 - y0
+}
+
+// photoSize is the output size for a w x h world area at the configured
+// width, shrunk to fit maxPhotoSize while keeping the aspect ratio.
+func photoSize(w, h float64, width int) (int, int) {
+	ow := float64(min(max(width, 1), maxPhotoSize))
+	oh := ow * h / w
+	if oh > maxPhotoSize {
+		ow, oh = ow*maxPhotoSize/oh, maxPhotoSize
+	}
+	return max(int(math.Round(ow)), 1), max(int(math.Round(oh)), 1)
+}
+
+// exportPhoto renders the current view, or the whole map, to an offscreen
+// image at photo.width pixels wide with markers, labels and optionally the
+// players baked in, then writes it to a timestamped PNG in the background.
+func (g *Game) exportPhoto(wholeMap bool) {
+	if g.bg == nil {
+		return
+	}
+	if !g.exporting.CompareAndSwap(false, true) {
+		slog.Info("export already in progress")
+		return
+	}
+	x, y, w, h := g.photoRect(wholeMap)
+	ow, oh := photoSize(w, h, g.cfg.Photo.Width)
+	scale := float64(ow) / w
+	tx, ty := -x*scale, -y*scale
+
+	img := ebiten.NewImage(ow, oh)
+	g.bg.draw(img, 0, scale, tx, ty, g.cfg.Map.SeamOverlap, 1, g.filter)
+	g.drawMarkersAt(img, scale, tx, ty)
+	if g.cfg.Photo.Labels {
+		g.drawLabelsAt(img, scale, tx, ty)
+	}
+	if g.cfg.Photo.Player {
+		g.drawPlayer(img, g.playerSprite, g.px, g.py, 0, g.facing, g.angle, scale, tx, ty)
+		if g.second != nil {
+			g.drawPlayer(img, g.second.sprite, g.second.x, g.second.y, 0, g.second.facing, g.second.angle, scale, tx, ty)
+		}
+	}
+	out := image.NewRGBA(image.Rect(0, 0, ow, oh))
+	img.ReadPixels(out.Pix)
+	img.Deallocate()
+
+	path := fmt.Sprintf("photo-%s.png", time.Now().Format("20060102-150405"))
+	go func() {
+		err := writePNG(path, out)
+		g.exporting.Store(false)
+		g.photoDone <- photoResult{path: path, err: err}
+	}()
+}
+
+// updatePhoto takes photos on P (Shift: the whole map) and reports
+// finished ones.
+func (g *Game) updatePhoto() {
+	select {
+	case r := <-g.photoDone:
+		if r.err != nil {
+			slog.Warn("failed to export photo", "path", r.path, "err", r.err)
+		} else {
+			slog.Info("exported photo", "path", r.path)
+			g.toast("Saved " + r.path)
+		}
+	default:
+	}
+	if g.justPressed(ebiten.KeyP) {
+		g.exportPhoto(ebiten.IsKeyPressed(ebiten.KeyShift))
+	}
+}
+
+// labelColor is the dot drawn under a label's name in photos.
+var labelColor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+// drawLabelsAt draws every named location with the world transform
+// screen = world*scale + (tx, ty).
+func (g *Game) drawLabelsAt(dst *ebiten.Image, scale, tx, ty float64) {
+	for _, l := range g.cfg.Labels {
+		x, y := l.X*scale+tx, l.Y*scale+ty
+		vector.FillCircle(dst, float32(x), float32(y), 3, labelColor, true)
+		drawText(dst, l.Name, int(x)+6, int(y)-8)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// pingRings is how many rings a ping draws, one after the other.
+const pingRings = 3
+
+// ping is a world point the player's attention is drawn to for a while.
+type ping struct {
+	x, y float64
+	left float64
+}
+
+// ping highlights a world point with expanding rings for ping.duration
+// seconds, playing the ping sound if one is configured.
+func (g *Game) ping(x, y float64) {
+	if g.cfg.Ping.Duration <= 0 {
+		return
+	}
+	g.pings = append(g.pings, ping{x: x, y: y, left: g.cfg.Ping.Duration})
+	if g.pingSound != nil && g.audioContext != nil {
+		g.audioContext.NewPlayerFromBytes(g.pingSound).Play()
+	}
+}
+
+// loadPingSound decodes the ping sound effect.
+func (g *Game) loadPingSound(path string) {
+	g.pingSound = g.loadSound(path, "ping")
+}
+
+// loadSound decodes a sound effect into memory at the audio context's rate
+// so it can be played any number of times. It is nil when there is none
+// or it can't be used; what names it in warnings.
+func (g *Game) loadSound(path, what string) []byte {
+	if path == "" || g.audioContext == nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("failed to load "+what+" sound", "path", path, "err", err)
+		return nil
+	}
+	stream, err := decodeMusic(bytes.NewReader(data), g.audioContext.SampleRate())
+	if err == nil && stream.SampleRate() != g.audioContext.SampleRate() {
+		slog.Warn(what+" sound sample rate does not match, skipping it", "path", path, "rate", stream.SampleRate())
+		return nil
+	}
+	var pcm []byte
+	if err == nil {
+		pcm, err = io.ReadAll(stream)
+	}
+	if err != nil {
+		slog.Warn("failed to decode "+what+" sound", "path", path, "err", err)
+		return nil
+	}
+	return pcm
+}
+
+// updatePings expires old pings and pings the cursor on Q.
+func (g *Game) updatePings() {
+	dt := deltaTime()
+	kept := g.pings[:0]
+	for _, p := range g.pings {
+		p.left -= dt
+		if p.left > 0 {
+			kept = append(kept, p)
+		}
+	}
+	g.pings = kept
+	if g.justPressed(ebiten.KeyQ) {
+		g.ping(g.cursorWorld())
+	}
+}
+
+// drawPings draws each ping as rings repeatedly expanding from its point.
+func (g *Game) drawPings(screen *ebiten.Image) {
+	cfg := g.cfg.Ping
+	if len(g.pings) == 0 || cfg.Duration <= 0 || !g.effectOn("pings") {
+		return
+	}
+	if g.pingColor == nil {
+		c, err := parseHexColor(cfg.Color)
+		if err != nil {
+			slog.Warn("invalid ping color", "err", err)
+			c = color.RGBA{0xff, 0x40, 0x40, 0xff}
+		}
+		g.pingColor = &c
+	}
+	scale, tx, ty := g.worldTransform(g.screenW, g.screenH)
+	for _, p := range g.pings {
+		x, y := p.x*scale+tx, p.y*scale+ty
+		t := 1 - p.left/cfg.Duration
+		vector.FillCircle(screen, float32(x), float32(y), 3, *g.pingColor, true)
+		for i := range pingRings {
+			// each ring restarts every third of the ping, staggered
+			r := t*pingRings - float64(i)
+			if r <= 0 || r >= 1 {
+				continue
+			}
+			a := 1 - r
+			c := *g.pingColor
+			c = color.RGBA{uint8(float64(c.R) * a), uint8(float64(c.G) * a), uint8(float64(c.B) * a), uint8(float64(c.A) * a)}
+			vector.StrokeCircle(screen, float32(x), float32(y), float32(r*cfg.Radius), 2, c, true)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// player is an additional player sharing player one's box size.
+type player struct {
+	x, y   float64
+	sprite *ebiten.Image
+	// seconds spent walking since last standing still
+	walkTime float64
+	// last horizontal direction moved in, 1 right or -1 left
+	facing float64
+	// sprite direction with movement.rotate, see Game.angle
+	angle float64
+	// a left/right key was pressed more recently than up/down
+	horizontalLast bool
+}
+
+func newPlayer(spritePath string, size int, x, y float64) (*player, error) {
+	orig, err := loadImage(spritePath)
+	if err != nil {
+		return nil, err
+	}
+	return &player{x: x, y: y, sprite: fitSprite(orig, size, size), facing: 1}, nil
+}
+
+// arrowInput is player two's movement for this update.
+func arrowInput() (mx, my float64) {
+	step := playerStep()
+	if ebiten.IsKeyPressed(ebiten.KeyUp) {
+		my -= step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyDown) {
+		my += step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+		mx -= step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyRight) {
+		mx += step
+	}
+	return mx, my
+}
+
+// lastAxis tracks whether horizontal keys were pressed more recently than
+// vertical ones, keeping prev when neither was just pressed.
+func (g *Game) lastAxis(prev bool, up, down, left, right ebiten.Key) bool {
+	if g.justPressed(left) || g.justPressed(right) {
+		return true
+	}
+	if g.justPressed(up) || g.justPressed(down) {
+		return false
+	}
+	return prev
+}
+
+// lockDirection applies the configured movement directions to a move of
+// (mx, my). With 4 directions only the axis pressed last is kept when both
+// are held; otherwise diagonals are scaled down so they aren't faster.
+func lockDirection(mx, my float64, directions int, horizontalLast bool) (float64, float64) {
+	if mx == 0 || my == 0 {
+		return mx, my
+	}
+	if directions == 4 {
+		if horizontalLast {
+			return mx, 0
+		}
+		return 0, my
+	}
+	return mx / math.Sqrt2, my / math.Sqrt2
+}
+
+// stepPlayer moves a player box at (x, y) by (mx, my), honoring soft
+// edges, the collision mask and the map bounds.
+func (g *Game) stepPlayer(x, y, mx, my float64) (float64, float64) {
+	// optionally slow down when closing in on the map edges
+	if g.cfg.Movement.SoftEdges && g.bg != nil {
+		wb := g.worldBounds()
+		margin := g.cfg.Movement.EdgeMargin
+		mx = edgeRamp(x, mx, wb.minX, wb.maxX-float64(g.playerW), margin)
+		my = edgeRamp(y, my, wb.minY, wb.maxY-float64(g.playerH), margin)
+	}
+	// move each axis separately so the player slides along blocked areas
+	if !g.blocked(x+mx, y) {
+		x += mx
+	}
+	if !g.blocked(x, y+my) {
+		y += my
+	}
+	if g.cfg.Map.Wrap {
+		return g.wrapToMap(x, y)
+	}
+	return g.clampToMap(x, y)
+}
+
+// walkTimer advances a walk timer while the player moved this update and
+// resets it once they stop.
+func walkTimer(t float64, moved bool) float64 {
+	if !moved {
+		return 0
+	}
+	return t + deltaTime()
+}
+
+// facingOf is the facing after moving mx horizontally: the sign of mx,
+// or the previous facing when there was no horizontal movement.
+func facingOf(prev, mx float64) float64 {
+	switch {
+	case mx < 0:
+		return -1
+	case mx > 0:
+		return 1
+	}
+	return prev
+}
+
+// turnToward turns angle toward target by at most step radians, the short
+// way round, so 350 degrees heads to 10 through 0 rather than back through
+// 180.
+func turnToward(angle, target, step float64) float64 {
+	diff := math.Remainder(target-angle, 2*math.Pi)
+	if math.Abs(diff) <= step {
+		return target
+	}
+	return angle + math.Copysign(step, diff)
+}
+
+// turnSprite turns a sprite's angle toward the direction of movement
+// (mx, my) at movement.turnSpeed degrees per second, 0 turning at once.
+// It is left alone while standing still.
+func (g *Game) turnSprite(angle, mx, my float64) float64 {
+	if !g.cfg.Movement.Rotate || (mx == 0 && my == 0) {
+		return angle
+	}
+	target := math.Atan2(my, mx)
+	if g.cfg.Movement.TurnSpeed <= 0 {
+		return target
+	}
+	return turnToward(angle, target, g.cfg.Movement.TurnSpeed*math.Pi/180*deltaTime())
+}
+
+// spriteGeoM places a w x h sprite at screen position (x, y) scaled by
+// scale, mirrored about its own center when facing left so it covers the
+// same screen area either way, and rotated about its center by angle.
+func spriteGeoM(w, h, x, y, scale, facing, angle float64) ebiten.GeoM {
+	var m ebiten.GeoM
+	if facing < 0 {
+		m.Scale(-1, 1)
+		m.Translate(w, 0)
+	}
+	if angle != 0 {
+		m.Translate(-w/2, -h/2)
+		m.Rotate(angle)
+		m.Translate(w/2, h/2)
+	}
+	m.Scale(scale, scale)
+	m.Translate(x, y)
+	return m
+}
+
+// worldBounds is the part of the map the player and camera are kept in:
+// map.bounds when set, cut to the map, otherwise the whole map. The image
+// around it is scenery that can be seen but not walked on.
+func (g *Game) worldBounds() bounds {
+	bw, bh := g.bg.size()
+	whole := bounds{0, 0, float64(bw), float64(bh)}
+	r := g.cfg.Map.Bounds
+	if r.Width <= 0 || r.Height <= 0 || g.cfg.Map.Wrap {
+		return whole
+	}
+	b := bounds{
+		max(r.X, 0), max(r.Y, 0),
+		min(r.X+r.Width, whole.maxX), min(r.Y+r.Height, whole.maxY),
+	}
+	if b.minX >= b.maxX || b.minY >= b.maxY {
+		return whole
+	}
+	return b
+}
+
+// clampToMap keeps a player box inside the world bounds.
+func (g *Game) clampToMap(x, y float64) (float64, float64) {
+	if g.bg == nil {
+		return x, y
+	}
+	wb := g.worldBounds()
+	// clamp player position so it doesn't go outside the world
+	// player bounds: (x, y) to (x + playerW, y + playerH)
+	x = max(x, wb.minX)
+	y = max(y, wb.minY)
+	// ensure player's right and bottom edges don't exceed the world's
+	x = min(x, max(wb.maxX-float64(g.playerW), wb.minX))
+	y = min(y, max(wb.maxY-float64(g.playerH), wb.minY))
+	return x, y
+}
+
+// frameBoth centers the camera on both players and zooms out as far as
+// needed, down to the whole-map fit, to keep them and some padding on
+// screen.
+func (g *Game) frameBoth() {
+	pad := g.cfg.TwoPlayer.Padding
+	g.frameBox(bounds{
+		minX: min(g.px, g.second.x) - pad,
+		minY: min(g.py, g.second.y) - pad,
+		maxX: max(g.px, g.second.x) + float64(g.playerW) + pad,
+		maxY: max(g.py, g.second.y) + float64(g.playerH) + pad,
+	})
+}
+
+// frameBox zooms so a world box fills the visible area, at most to zoom
+// 1 and at least to the whole-map fit, and moves the camera onto its
+// center.
+func (g *Game) frameBox(b bounds) {
+	g.zoom = g.boxZoom(b, 1)
+	g.moveCameraTo((b.minX+b.maxX)/2, (b.minY+b.maxY)/2, false)
+}
+
+// boxZoom is the zoom at which a world box fills the visible area, no
+// further in than maxZoom and no further out than the whole-map fit.
+func (g *Game) boxZoom(b bounds, maxZoom float64) float64 {
+	base, _, _ := g.viewTransform(g.screenW, g.screenH)
+	base /= g.zoom
+	area := g.mapArea(g.screenW, g.screenH)
+	z := min(float64(area.Dx())/((b.maxX-b.minX)*base), float64(area.Dy())/((b.maxY-b.minY)*base), maxZoom)
+	return max(z, g.minZoom)
+}
+
+// shadowImage is the player's shadow, an ellipse-ish rounded rectangle
+// or, with shadow.feather, a soft ellipse, built once since the player size
+// doesn't change.
+func (g *Game) shadowImage() *ebiten.Image {
+	if g.shadow != nil {
+		return g.shadow
+	}
+	shadowWidth := max(int(float64(g.playerW)*0.8), 1)
+	shadowHeight := max(int(float64(g.playerH)*0.3), 1)
+	if g.cfg.Shadow.Feather > 0 {
+		g.shadow = featheredShadow(shadowWidth, shadowHeight, g.cfg.Shadow.Feather)
+		return g.shadow
+	}
+
+	// create shadow image with rounded corners (ellipse effect)
+	shadowImg := ebiten.NewImage(shadowWidth, shadowHeight)
+	// fill with semi-transparent black
+	shadowImg.Fill(color.RGBA{R: 0, G: 0, B: 0, A: 100})
+
+	// draw rounded corners by clearing corner regions
+	cornerRadius := int(float64(shadowHeight) / 2)
+	for x := 0; x < cornerRadius; x++ {
+		for y := 0; y < cornerRadius; y++ {
+			dx := x - cornerRadius
+			dy := y - cornerRadius
+			if dx*dx+dy*dy > cornerRadius*cornerRadius {
+				// clear top-left corner
+				shadowImg.Set(x, y, color.RGBA{0, 0, 0, 0})
+				// clear top-right corner
+				shadowImg.Set(shadowWidth-1-x, y, color.RGBA{0, 0, 0, 0})
+				// clear bottom-left corner
+				shadowImg.Set(x, shadowHeight-1-y, color.RGBA{0, 0, 0, 0})
+				// clear bottom-right corner
+				shadowImg.Set(shadowWidth-1-x, shadowHeight-1-y, color.RGBA{0, 0, 0, 0})
+			}
+		}
+	}
+	g.shadow = shadowImg
+	return g.shadow
+}
+
+// featheredShadow is a w x h ellipse whose alpha falls off smoothly over
+// the outer feather fraction of its radius.
+func featheredShadow(w, h int, feather float64) *ebiten.Image {
+	img := ebiten.NewImage(w, h)
+	img.WritePixels(featheredShadowPixels(w, h, feather))
+	return img
+}
+
+// featheredShadowPixels are the RGBA pixels of featheredShadow.
+func featheredShadowPixels(w, h int, feather float64) []byte {
+	const shadowAlpha = 100
+	feather = min(feather, 1)
+	rx, ry := float64(w)/2, float64(h)/2
+	pixels := make([]byte, 4*w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx := (float64(x) + 0.5 - rx) / rx
+			dy := (float64(y) + 0.5 - ry) / ry
+			// 1 inside the solid core, 0 at the rim
+			t := math.Min((1-math.Hypot(dx, dy))/feather, 1)
+			if t <= 0 {
+				continue
+			}
+			// premultiplied black, so only alpha matters
+			pixels[4*(y*w+x)+3] = byte(shadowAlpha * t * t * (3 - 2*t))
+		}
+	}
+	return pixels
+}
+
+// shadowBob is the shadow scale for a player that has been walking for
+// walkTime seconds: it pulses by up to amplitude while walking, as if the
+// player bobs up and down, and is 1 at rest.
+func shadowBob(walkTime float64, cfg ShadowConfig) float64 {
+	if walkTime <= 0 || cfg.BobAmplitude <= 0 {
+		return 1
+	}
+	return 1 - cfg.BobAmplitude*math.Abs(math.Sin(math.Pi*cfg.BobSpeed*walkTime))
+}
+
+// drawPlayer draws a player's shadow and sprite at world position (x, y)
+// with the world transform screen = world*scale + (tx, ty). walkTime is
+// how long the player has been moving, 0 when standing still, and the
+// sprite is mirrored when facing is negative and, with movement.rotate,
+// turned by angle; the shadow never is.
+func (g *Game) drawPlayer(screen, sprite *ebiten.Image, x, y, walkTime, facing, angle, scale, tx, ty float64) {
+	playerScreenX := x*scale + tx
+	playerScreenY := y*scale + ty
+
+	// draw shadow (ellipse beneath the player)
+	if g.effectOn("shadow") {
+		shadowImg := g.shadowImage()
+		shadowWidth := float64(shadowImg.Bounds().Dx())
+		shadowHeight := float64(shadowImg.Bounds().Dy())
+		shadowOffsetY := float64(g.playerH) * 2.1 // offset below player
+		bob := shadowBob(walkTime, g.cfg.Shadow)
+
+		shadowOp := &ebiten.DrawImageOptions{}
+		// shrink around the shadow's center while bobbing
+		shadowOp.GeoM.Translate(-shadowWidth/2, -shadowHeight/2)
+		shadowOp.GeoM.Scale(bob*scale, bob*scale)
+		shadowOp.GeoM.Translate(
+			playerScreenX+float64(g.playerW)/2*scale,
+			playerScreenY+(shadowOffsetY+shadowHeight/2)*scale,
+		)
+		shadowOp.ColorScale.ScaleAlpha(0.9)
+		screen.DrawImage(shadowImg, shadowOp)
+	}
+
+	// draw player sprite
+	// convert player world position to screen position
+	if sprite == nil {
+		return
+	}
+	// a rotated sprite already faces its way, so it isn't mirrored too
+	if !g.cfg.Movement.FlipSprite || g.cfg.Movement.Rotate {
+		facing = 1
+	}
+	if !g.cfg.Movement.Rotate {
+		angle = 0
+	}
+	playerOp := &ebiten.DrawImageOptions{}
+	playerOp.GeoM = spriteGeoM(float64(sprite.Bounds().Dx()), float64(sprite.Bounds().Dy()), playerScreenX, playerScreenY, scale, facing, angle)
+	playerOp.Filter = g.filter
+	screen.DrawImage(sprite, playerOp)
+}
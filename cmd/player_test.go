@@ -0,0 +1,337 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestFacingOf(t *testing.T) {
+	tests := []struct {
+		prev, mx, want float64
+	}{
+		{1, -2, -1},
+		{-1, 3, 1},
+		{-1, 0, -1},
+		{1, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := facingOf(tt.prev, tt.mx); got != tt.want {
+			t.Errorf("facingOf(%v, %v) = %v, want %v", tt.prev, tt.mx, got, tt.want)
+		}
+	}
+}
+
+// geoMBox is the screen rectangle a w x h image covers under m.
+func geoMBox(m ebiten.GeoM, w, h float64) bounds {
+	b := bounds{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, c := range [][2]float64{{0, 0}, {w, 0}, {0, h}, {w, h}} {
+		x, y := m.Apply(c[0], c[1])
+		b.minX, b.maxX = min(b.minX, x), max(b.maxX, x)
+		b.minY, b.maxY = min(b.minY, y), max(b.maxY, y)
+	}
+	return b
+}
+
+func sameBox(a, b bounds) bool {
+	const eps = 1e-9
+	return math.Abs(a.minX-b.minX) < eps && math.Abs(a.minY-b.minY) < eps &&
+		math.Abs(a.maxX-b.maxX) < eps && math.Abs(a.maxY-b.maxY) < eps
+}
+
+// Facing left mirrors the sprite in place: it covers the same screen box
+// as facing right, with its left and right edges swapped.
+func TestSpriteGeoMFlipKeepsPosition(t *testing.T) {
+	const w, h, x, y, scale = 32, 48, 100, 200, 2
+	right := spriteGeoM(w, h, x, y, scale, 1, 0)
+	left := spriteGeoM(w, h, x, y, scale, -1, 0)
+	want := bounds{x, y, x + w*scale, y + h*scale}
+	if got := geoMBox(right, w, h); !sameBox(got, want) {
+		t.Errorf("facing right covers %v, want %v", got, want)
+	}
+	if got := geoMBox(left, w, h); !sameBox(got, want) {
+		t.Errorf("facing left covers %v, want %v", got, want)
+	}
+	if lx, _ := left.Apply(0, 0); lx != x+w*scale {
+		t.Errorf("facing left puts the sprite's left edge at %v, want %v", lx, x+w*scale)
+	}
+}
+
+func TestLockDirection(t *testing.T) {
+	d := 3 / math.Sqrt2
+	tests := []struct {
+		name           string
+		mx, my         float64
+		directions     int
+		horizontalLast bool
+		wantX, wantY   float64
+	}{
+		{"4: horizontal only", 3, 0, 4, false, 3, 0},
+		{"4: vertical only", 0, -3, 4, true, 0, -3},
+		{"4: diagonal, horizontal last", 3, -3, 4, true, 3, 0},
+		{"4: diagonal, vertical last", 3, -3, 4, false, 0, -3},
+		{"8: straight", -3, 0, 8, false, -3, 0},
+		{"8: diagonal", 3, -3, 8, false, d, -d},
+		{"8: diagonal ignores last axis", -3, 3, 8, true, -d, d},
+		{"unset means 8", 3, 3, 0, true, d, d},
+	}
+	for _, tt := range tests {
+		x, y := lockDirection(tt.mx, tt.my, tt.directions, tt.horizontalLast)
+		if x != tt.wantX || y != tt.wantY {
+			t.Errorf("%s: lockDirection(%v, %v) = %v, %v, want %v, %v", tt.name, tt.mx, tt.my, x, y, tt.wantX, tt.wantY)
+		}
+	}
+}
+
+// Diagonal movement with 8 directions is no faster than straight.
+func TestLockDirectionDiagonalSpeed(t *testing.T) {
+	x, y := lockDirection(4, 4, 8, false)
+	if s := math.Hypot(x, y); math.Abs(s-4) > 1e-12 {
+		t.Errorf("diagonal speed %v, want 4", s)
+	}
+}
+
+// With 4 directions the axis whose key went down last wins, and holding
+// both keeps it until another key is pressed.
+func TestLastAxis(t *testing.T) {
+	g := newTestGame(1000, 1000)
+	keys := &fakeKeys{}
+	g.keys = keys
+	b := g.bindings
+	steps := []struct {
+		held []ebiten.Key
+		want bool
+	}{
+		{[]ebiten.Key{b.Up}, false},
+		{[]ebiten.Key{b.Up, b.Right}, true},
+		{[]ebiten.Key{b.Up, b.Right}, true},
+		{[]ebiten.Key{b.Right}, true},
+		{[]ebiten.Key{b.Right, b.Down}, false},
+		{[]ebiten.Key{b.Right, b.Down}, false},
+		{nil, false},
+		{[]ebiten.Key{b.Left}, true},
+	}
+	last := false
+	for i, s := range steps {
+		keys.hold(s.held...)
+		last = g.lastAxis(last, b.Up, b.Down, b.Left, b.Right)
+		if last != s.want {
+			t.Errorf("update %d: horizontal last = %v, want %v", i, last, s.want)
+		}
+	}
+}
+
+// alphaAt is the alpha of pixel (x, y) in w-wide RGBA pixels.
+func alphaAt(pixels []byte, w, x, y int) byte {
+	return pixels[4*(y*w+x)+3]
+}
+
+func TestFeatheredShadowFallsOff(t *testing.T) {
+	const w, h = 40, 20
+	px := featheredShadowPixels(w, h, 0.5)
+	if a := alphaAt(px, w, w/2, h/2); a != 100 {
+		t.Errorf("center alpha %d, want 100", a)
+	}
+	// from the center out to the rim along each axis the alpha never rises
+	// and ends at nothing
+	prev := byte(255)
+	for x := w / 2; x < w; x++ {
+		a := alphaAt(px, w, x, h/2)
+		if a > prev {
+			t.Errorf("alpha rises from %d to %d at x %d", prev, a, x)
+		}
+		prev = a
+	}
+	if prev > 10 {
+		t.Errorf("alpha at the right rim %d, want close to 0", prev)
+	}
+	prev = 255
+	for y := h / 2; y < h; y++ {
+		a := alphaAt(px, w, w/2, y)
+		if a > prev {
+			t.Errorf("alpha rises from %d to %d at y %d", prev, a, y)
+		}
+		prev = a
+	}
+	for _, c := range [][2]int{{0, 0}, {w - 1, 0}, {0, h - 1}, {w - 1, h - 1}} {
+		if a := alphaAt(px, w, c[0], c[1]); a != 0 {
+			t.Errorf("corner %v alpha %d, want 0", c, a)
+		}
+	}
+	// only alpha is set, as premultiplied black
+	for i := 0; i < len(px); i += 4 {
+		if px[i] != 0 || px[i+1] != 0 || px[i+2] != 0 {
+			t.Fatalf("pixel %d has color %v", i/4, px[i:i+3])
+		}
+	}
+}
+
+// A bigger feather starts fading closer to the center.
+func TestFeatheredShadowWidth(t *testing.T) {
+	const w, h = 40, 20
+	soft := featheredShadowPixels(w, h, 1)
+	hard := featheredShadowPixels(w, h, 0.2)
+	x := w * 3 / 4
+	if a, b := alphaAt(soft, w, x, h/2), alphaAt(hard, w, x, h/2); a >= b {
+		t.Errorf("halfway out feather 1 has alpha %d, feather 0.2 %d; want the soft one lower", a, b)
+	}
+	if a := alphaAt(hard, w, x, h/2); a != 100 {
+		t.Errorf("feather 0.2 fades already halfway out: alpha %d", a)
+	}
+	// feathers above 1 act as 1
+	if over := featheredShadowPixels(w, h, 3); string(over) != string(soft) {
+		t.Error("feather 3 differs from feather 1")
+	}
+}
+
+// Rotation turns the sprite about its center, which stays put.
+func TestSpriteGeoMRotatesAboutCenter(t *testing.T) {
+	const w, h, x, y, scale = 32, 48, 100, 200, 2
+	cx, cy := x+w*scale/2.0, y+h*scale/2.0
+	for _, facing := range []float64{1, -1} {
+		for _, angle := range []float64{math.Pi / 2, math.Pi, -math.Pi / 4} {
+			m := spriteGeoM(w, h, x, y, scale, facing, angle)
+			gx, gy := m.Apply(w/2, h/2)
+			if math.Abs(gx-cx) > 1e-9 || math.Abs(gy-cy) > 1e-9 {
+				t.Errorf("facing %v angle %v: center drawn at %v, %v, want %v, %v", facing, angle, gx, gy, cx, cy)
+			}
+		}
+	}
+	m := spriteGeoM(w, h, x, y, scale, 1, math.Pi/2)
+	want := bounds{cx - h*scale/2, cy - w*scale/2, cx + h*scale/2, cy + w*scale/2}
+	if got := geoMBox(m, w, h); !sameBox(got, want) {
+		t.Errorf("a quarter turn covers %v, want %v", got, want)
+	}
+}
+
+func deg(d float64) float64 { return d * math.Pi / 180 }
+
+func TestTurnToward(t *testing.T) {
+	tests := []struct {
+		name                string
+		angle, target, step float64
+		want                float64
+	}{
+		{"within a step", deg(10), deg(12), deg(5), deg(12)},
+		{"counterclockwise", deg(10), deg(90), deg(5), deg(15)},
+		{"clockwise", deg(90), deg(10), deg(5), deg(85)},
+		// the short way from 350 to 10 goes up through 360
+		{"across 0, up", deg(350), deg(10), deg(5), deg(355)},
+		{"across 0, down", deg(10), deg(350), deg(5), deg(5)},
+		{"across 0, one step", deg(350), deg(10), deg(30), deg(10)},
+		{"atan2 range", deg(170), deg(-170), deg(5), deg(175)},
+	}
+	for _, tt := range tests {
+		got := turnToward(tt.angle, tt.target, tt.step)
+		if math.Abs(math.Remainder(got-tt.want, 2*math.Pi)) > 1e-9 {
+			t.Errorf("%s: turnToward(%.0f°, %.0f°, %.0f°) = %.1f°, want %.1f°",
+				tt.name, tt.angle*180/math.Pi, tt.target*180/math.Pi, tt.step*180/math.Pi, got*180/math.Pi, tt.want*180/math.Pi)
+		}
+	}
+}
+
+// From 350° to 10° the sprite turns +20° in total, never the long way
+// round.
+func TestTurnTowardShortArc(t *testing.T) {
+	angle, target := deg(350), deg(10)
+	turned := 0.0
+	for range 100 {
+		next := turnToward(angle, target, deg(1))
+		// the final step lands on the target itself, a full turn lower
+		d := math.Remainder(next-angle, 2*math.Pi)
+		if d < 0 {
+			t.Fatalf("turned backwards from %.1f° to %.1f°", angle*180/math.Pi, next*180/math.Pi)
+		}
+		turned += d
+		angle = next
+	}
+	if math.Abs(turned-deg(20)) > 1e-9 {
+		t.Errorf("turned %.2f°, want 20°", turned*180/math.Pi)
+	}
+}
+
+func TestTurnSprite(t *testing.T) {
+	g := newTestGame(100, 100)
+	if got := g.turnSprite(1, 0, 1); got != 1 {
+		t.Errorf("turned to %v with movement.rotate off", got)
+	}
+	g.cfg.Movement.Rotate = true
+	if got := g.turnSprite(1, 0, 0); got != 1 {
+		t.Errorf("turned to %v while standing still", got)
+	}
+	g.cfg.Movement.TurnSpeed = 0
+	if got := g.turnSprite(0, 0, 1); got != math.Pi/2 {
+		t.Errorf("turn speed 0 turned to %v, want straight down", got)
+	}
+	// 360° a second covers 6° in one update at 60 TPS
+	g.cfg.Movement.TurnSpeed = 360
+	if got, want := g.turnSprite(0, 0, 1), 2*math.Pi*deltaTime(); math.Abs(got-want) > 1e-12 {
+		t.Errorf("one update at 360°/s turned to %v, want %v", got, want)
+	}
+}
+
+func TestWorldBounds(t *testing.T) {
+	whole := bounds{0, 0, 2000, 1000}
+	tests := []struct {
+		name string
+		r    WorldRect
+		wrap bool
+		want bounds
+	}{
+		{"unset", WorldRect{}, false, whole},
+		{"inset", WorldRect{X: 100, Y: 50, Width: 1600, Height: 800}, false, bounds{100, 50, 1700, 850}},
+		{"past the map", WorldRect{X: -100, Y: 900, Width: 600, Height: 400}, false, bounds{0, 900, 500, 1000}},
+		{"outside the map", WorldRect{X: 3000, Y: 0, Width: 100, Height: 100}, false, whole},
+		{"zero width", WorldRect{X: 100, Y: 100, Width: 0, Height: 100}, false, whole},
+		{"wrapping ignores it", WorldRect{X: 100, Y: 50, Width: 1600, Height: 800}, true, whole},
+	}
+	for _, tt := range tests {
+		g := newTestGame(2000, 1000)
+		g.cfg.Map.Bounds = tt.r
+		g.cfg.Map.Wrap = tt.wrap
+		if got := g.worldBounds(); got != tt.want {
+			t.Errorf("%s: worldBounds() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// The player is kept inside a custom inset with its whole box, and the
+// camera stops at its edges.
+func TestWorldBoundsClamp(t *testing.T) {
+	g := newTestGame(4000, 3000)
+	g.cfg.Map.Bounds = WorldRect{X: 500, Y: 400, Width: 2000, Height: 1500}
+	tests := []struct {
+		x, y, wantX, wantY float64
+	}{
+		{1000, 1000, 1000, 1000},
+		{0, 0, 500, 400},
+		{3900, 2900, 2500 - 32, 1900 - 32},
+		{2490, 1000, 2500 - 32, 1000},
+	}
+	for _, tt := range tests {
+		if x, y := g.clampToMap(tt.x, tt.y); x != tt.wantX || y != tt.wantY {
+			t.Errorf("clampToMap(%v, %v) = %v, %v, want %v, %v", tt.x, tt.y, x, y, tt.wantX, tt.wantY)
+		}
+	}
+	if vx, vy := g.clampedView(0, 0); vx != 500 || vy != 400 {
+		t.Errorf("view clamped to %v, %v at the top left, want 500, 400", vx, vy)
+	}
+	if vx, vy := g.clampedView(5000, 5000); vx != 2500-640 || vy != 1900-480 {
+		t.Errorf("view clamped to %v, %v at the bottom right, want %v, %v", vx, vy, 2500-640, 1900-480)
+	}
+}
+
+// Soft edges slow the player down toward the inset's edges, not the
+// image's.
+func TestStepPlayerSoftEdgesUseBounds(t *testing.T) {
+	g := newTestGame(4000, 3000)
+	g.cfg.Map.Bounds = WorldRect{X: 500, Y: 400, Width: 2000, Height: 1500}
+	g.cfg.Movement.SoftEdges = true
+	g.cfg.Movement.EdgeMargin = 100
+	// halfway into the margin by the inset's left edge
+	x, _ := g.stepPlayer(550, 1000, -4, 0)
+	if x != 548 {
+		t.Errorf("stepped to x %v halfway into the margin, want 548", x)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"image/color"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// cursorWorld is the world point under the cursor, where cursor actions
+// such as placing a marker or pinging take effect.
+func (g *Game) cursorWorld() (float64, float64) {
+	cx, cy := ebiten.CursorPosition()
+	return g.screenToWorld(float64(cx), float64(cy))
+}
+
+// updateReticle toggles the reticle with B and moves it to the world point
+// under the cursor. It is drawn at that world point, so until the next
+// update it stays on the spot an action would hit even as the camera
+// moves.
+func (g *Game) updateReticle() {
+	if !g.cfg.Reticle.Enabled {
+		return
+	}
+	if g.justPressed(ebiten.KeyB) {
+		g.showReticle = !g.showReticle
+	}
+	g.reticleX, g.reticleY = g.cursorWorld()
+}
+
+// drawReticle draws the reticle as a ring or a cross, in screen pixels.
+func (g *Game) drawReticle(screen *ebiten.Image) {
+	cfg := g.cfg.Reticle
+	if !cfg.Enabled || !g.showReticle || g.menu != nil {
+		return
+	}
+	if g.reticleColor == nil {
+		c, err := parseHexColor(cfg.Color)
+		if err != nil {
+			slog.Warn("invalid reticle color", "err", err)
+			c = color.RGBA{0xff, 0xff, 0xff, 0xc0}
+		}
+		g.reticleColor = &c
+	}
+	sx, sy := g.worldToScreen(g.reticleX, g.reticleY)
+	x, y := float32(sx), float32(sy)
+	r := float32(max(cfg.Size, 2)) / 2
+	width := float32(max(cfg.Thickness, 1))
+	if cfg.Style == "cross" {
+		vector.StrokeLine(screen, x-r, y, x+r, y, width, *g.reticleColor, true)
+		vector.StrokeLine(screen, x, y-r, x, y+r, width, *g.reticleColor, true)
+		return
+	}
+	vector.StrokeCircle(screen, x, y, r, width, *g.reticleColor, true)
+	vector.FillCircle(screen, x, y, width, *g.reticleColor, true)
+}
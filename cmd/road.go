@@ -0,0 +1,168 @@
+package main
+
+import (
+	"image/color"
+	"log/slog"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// MetaRoads is a graph of road waypoints the player can be routed along.
+type MetaRoads struct {
+	Nodes []MetaNode `json:"nodes"`
+	// pairs of node indices joined by a road, usable both ways
+	Edges [][2]int `json:"edges"`
+}
+
+// MetaNode is one road waypoint in world coordinates.
+type MetaNode struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type roadEdge struct {
+	to   int
+	cost float64
+}
+
+// roadGraph is the road network with edges weighted by their length.
+type roadGraph struct {
+	nodes []MetaNode
+	adj   [][]roadEdge
+}
+
+// newRoadGraph builds the graph, skipping edges that name a missing node.
+func newRoadGraph(roads *MetaRoads) *roadGraph {
+	g := &roadGraph{nodes: roads.Nodes, adj: make([][]roadEdge, len(roads.Nodes))}
+	for _, e := range roads.Edges {
+		a, b := e[0], e[1]
+		if a < 0 || b < 0 || a >= len(g.nodes) || b >= len(g.nodes) || a == b {
+			slog.Warn("ignoring invalid road edge", "from", a, "to", b)
+			continue
+		}
+		cost := math.Hypot(g.nodes[a].X-g.nodes[b].X, g.nodes[a].Y-g.nodes[b].Y)
+		g.adj[a] = append(g.adj[a], roadEdge{b, cost})
+		g.adj[b] = append(g.adj[b], roadEdge{a, cost})
+	}
+	return g
+}
+
+// nearestNode is the index of the node closest to (x, y), or -1 if there
+// are none.
+func (g *roadGraph) nearestNode(x, y float64) int {
+	best, bestD := -1, math.Inf(1)
+	for i, n := range g.nodes {
+		if d := math.Hypot(n.X-x, n.Y-y); d < bestD {
+			best, bestD = i, d
+		}
+	}
+	return best
+}
+
+// shortestPath finds the cheapest chain of nodes from one node to
+// another with Dijkstra's algorithm, or nil if they aren't connected.
+// Road graphs are small, so the closest open node is found by a scan
+// rather than a heap.
+func (g *roadGraph) shortestPath(from, to int) []int {
+	n := len(g.nodes)
+	if from < 0 || to < 0 || from >= n || to >= n {
+		return nil
+	}
+	dist := make([]float64, n)
+	prev := make([]int, n)
+	done := make([]bool, n)
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		prev[i] = -1
+	}
+	dist[from] = 0
+	for {
+		u := -1
+		for i := range n {
+			if !done[i] && !math.IsInf(dist[i], 1) && (u < 0 || dist[i] < dist[u]) {
+				u = i
+			}
+		}
+		if u < 0 {
+			return nil
+		}
+		if u == to {
+			break
+		}
+		done[u] = true
+		for _, e := range g.adj[u] {
+			if d := dist[u] + e.cost; d < dist[e.to] {
+				dist[e.to] = d
+				prev[e.to] = u
+			}
+		}
+	}
+	var path []int
+	for v := to; v >= 0; v = prev[v] {
+		path = append(path, v)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// route is the list of world points to walk through from (x0, y0) to
+// (x1, y1): onto the nearest road, along it and off at the road node
+// closest to the destination. It is the destination alone when the roads
+// don't connect the two.
+func (g *roadGraph) route(x0, y0, x1, y1 float64) [][2]float64 {
+	path := g.shortestPath(g.nearestNode(x0, y0), g.nearestNode(x1, y1))
+	pts := make([][2]float64, 0, len(path)+1)
+	for _, i := range path {
+		pts = append(pts, [2]float64{g.nodes[i].X, g.nodes[i].Y})
+	}
+	return append(pts, [2]float64{x1, y1})
+}
+
+// walkTo sends player one to the world point (x, y), along the roads when
+// movement.followRoads is set and the map has them, otherwise straight.
+func (g *Game) walkTo(x, y float64) {
+	cx, cy := g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2
+	if g.roads != nil {
+		g.route = g.roads.route(cx, cy, x, y)
+		return
+	}
+	g.route = [][2]float64{{x, y}}
+}
+
+// routeStep is this update's move toward the next route point, dropping
+// points as they are reached.
+func (g *Game) routeStep() (float64, float64) {
+	for len(g.route) > 0 {
+		dx := g.route[0][0] - (g.px + float64(g.playerW)/2)
+		dy := g.route[0][1] - (g.py + float64(g.playerH)/2)
+		d := math.Hypot(dx, dy)
+		if d > playerSpeed {
+			return dx / d * playerSpeed, dy / d * playerSpeed
+		}
+		g.route = g.route[1:]
+		if d > 0 {
+			return dx, dy
+		}
+	}
+	return 0, 0
+}
+
+var routeColor = color.RGBA{0xff, 0xff, 0xff, 0xa0}
+
+// drawRoute draws the rest of the route from the player onward.
+func (g *Game) drawRoute(screen *ebiten.Image) {
+	if len(g.route) == 0 {
+		return
+	}
+	x, y := g.worldToScreen(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2)
+	for _, p := range g.route {
+		nx, ny := g.worldToScreen(p[0], p[1])
+		vector.StrokeLine(screen, float32(x), float32(y), float32(nx), float32(ny), 2, routeColor, true)
+		x, y = nx, ny
+	}
+	vector.FillCircle(screen, float32(x), float32(y), 4, routeColor, true)
+}
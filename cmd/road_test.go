@@ -0,0 +1,118 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// testRoads is a square 0-1-2-3 with a long diagonal 0-2, and a node 4
+// joined to nothing:
+//
+//	0 --- 1
+//	|  \  |
+//	3 --- 2    4
+func testRoads() *roadGraph {
+	return newRoadGraph(&MetaRoads{
+		Nodes: []MetaNode{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {500, 100}},
+		Edges: [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {0, 2}},
+	})
+}
+
+func TestShortestPath(t *testing.T) {
+	g := testRoads()
+	tests := []struct {
+		name     string
+		from, to int
+		want     []int
+	}{
+		{"same node", 1, 1, []int{1}},
+		{"neighbours", 0, 1, []int{0, 1}},
+		{"diagonal beats going round", 0, 2, []int{0, 2}},
+		{"two hops", 1, 3, []int{1, 0, 3}},
+		{"disconnected", 0, 4, nil},
+		{"from a disconnected node", 4, 0, nil},
+		{"out of range", 0, 9, nil},
+		{"negative", -1, 2, nil},
+	}
+	for _, tt := range tests {
+		got := g.shortestPath(tt.from, tt.to)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: shortestPath(%d, %d) = %v, want %v", tt.name, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// A shorter path with more hops beats a direct but longer road.
+func TestShortestPathPrefersCost(t *testing.T) {
+	g := newRoadGraph(&MetaRoads{
+		Nodes: []MetaNode{{0, 0}, {50, 1}, {100, 0}, {50, 400}},
+		Edges: [][2]int{{0, 3}, {3, 2}, {0, 1}, {1, 2}},
+	})
+	if got, want := g.shortestPath(0, 2), []int{0, 1, 2}; !slices.Equal(got, want) {
+		t.Errorf("shortestPath = %v, want %v", got, want)
+	}
+}
+
+func TestNewRoadGraphSkipsBadEdges(t *testing.T) {
+	g := newRoadGraph(&MetaRoads{
+		Nodes: []MetaNode{{0, 0}, {30, 40}},
+		Edges: [][2]int{{0, 1}, {0, 5}, {-1, 0}, {1, 1}},
+	})
+	if len(g.adj[0]) != 1 || len(g.adj[1]) != 1 {
+		t.Fatalf("adjacency = %v, want one edge each way", g.adj)
+	}
+	if e := g.adj[0][0]; e.to != 1 || e.cost != 50 {
+		t.Errorf("edge 0-1 = %+v, want to 1 at cost 50", e)
+	}
+	if e := g.adj[1][0]; e.to != 0 || e.cost != 50 {
+		t.Errorf("edge 1-0 = %+v, want to 0 at cost 50", e)
+	}
+}
+
+func TestNearestNode(t *testing.T) {
+	g := testRoads()
+	if got := g.nearestNode(90, 10); got != 1 {
+		t.Errorf("nearestNode(90, 10) = %d, want 1", got)
+	}
+	if got := g.nearestNode(400, 400); got != 4 {
+		t.Errorf("nearestNode(400, 400) = %d, want 4", got)
+	}
+	empty := newRoadGraph(&MetaRoads{})
+	if got := empty.nearestNode(0, 0); got != -1 {
+		t.Errorf("nearestNode on no roads = %d, want -1", got)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	g := testRoads()
+	got := g.route(95, -5, 5, 110)
+	want := [][2]float64{{100, 0}, {0, 0}, {0, 100}, {5, 110}}
+	if !slices.Equal(got, want) {
+		t.Errorf("route along the roads = %v, want %v", got, want)
+	}
+	// no road joins the ends, so it walks straight there
+	got = g.route(5, 5, 490, 100)
+	if want := [][2]float64{{490, 100}}; !slices.Equal(got, want) {
+		t.Errorf("route to a disconnected node = %v, want %v", got, want)
+	}
+}
+
+// The player walks the route point by point and stops at the end.
+func TestRouteStep(t *testing.T) {
+	g := newTestGame(1000, 1000)
+	g.px, g.py = -16, -16
+	g.route = [][2]float64{{10, 0}, {10, 10}}
+	x, y := 0.0, 0.0
+	for range 100 {
+		mx, my := g.routeStep()
+		if mx == 0 && my == 0 {
+			break
+		}
+		g.px += mx
+		g.py += my
+		x, y = g.px+16, g.py+16
+	}
+	if len(g.route) != 0 || x != 10 || y != 10 {
+		t.Errorf("walked to %v, %v with %d points left, want 10, 10 and none", x, y, len(g.route))
+	}
+}
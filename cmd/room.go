@@ -0,0 +1,107 @@
+package main
+
+import (
+	"image/color"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// RoomGrid divides the world into Rows x Cols equal rooms.
+type RoomGrid struct {
+	Rows  int        `json:"rows"`
+	Cols  int        `json:"cols"`
+	Rooms []MetaRoom `json:"rooms"`
+}
+
+// MetaRoom describes one room of the grid; rooms not listed have no name,
+// play the default music and are untinted.
+type MetaRoom struct {
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+	Name string `json:"name"`
+	// music file, relative to the sidecar
+	Music string `json:"music"`
+	// ambient tint as #rrggbbaa drawn over the room
+	Tint string `json:"tint"`
+}
+
+// room is a MetaRoom ready for use.
+type room struct {
+	name  string
+	music string
+	tint  color.RGBA
+}
+
+// roomIndex is the grid cell of world point (x, y) on a w x h world split
+// into rows x cols rooms. Points on the far edges belong to the last
+// room; points outside the world have none.
+func roomIndex(x, y, w, h float64, rows, cols int) (row, col int, ok bool) {
+	if rows <= 0 || cols <= 0 || w <= 0 || h <= 0 || x < 0 || y < 0 || x > w || y > h {
+		return 0, 0, false
+	}
+	col = min(int(x*float64(cols)/w), cols-1)
+	row = min(int(y*float64(rows)/h), rows-1)
+	return row, col, true
+}
+
+// loadRooms indexes the metadata rooms by cell, resolving music paths
+// against dir.
+func loadRooms(grid *RoomGrid, dir string) map[[2]int]room {
+	rooms := map[[2]int]room{}
+	for _, r := range grid.Rooms {
+		rm := room{name: r.Name, music: r.Music}
+		if rm.music != "" && !filepath.IsAbs(rm.music) {
+			rm.music = filepath.Join(dir, rm.music)
+		}
+		if r.Tint != "" {
+			c, err := parseHexColor(r.Tint)
+			if err != nil {
+				slog.Warn("invalid room tint", "room", r.Name, "err", err)
+			} else {
+				rm.tint = c
+			}
+		}
+		rooms[[2]int{r.Row, r.Col}] = rm
+	}
+	return rooms
+}
+
+// updateRoom tracks which room the player is in and switches the music
+// when they cross into another one.
+func (g *Game) updateRoom() {
+	if g.meta == nil || g.meta.Rooms == nil || g.bg == nil {
+		return
+	}
+	bw, bh := g.bg.size()
+	row, col, ok := roomIndex(g.px+float64(g.playerW)/2, g.py+float64(g.playerH)/2,
+		float64(bw), float64(bh), g.meta.Rooms.Rows, g.meta.Rooms.Cols)
+	cell := [2]int{row, col}
+	if !ok {
+		cell = [2]int{-1, -1}
+	}
+	if g.roomCell == cell && g.roomKnown {
+		return
+	}
+	g.roomCell, g.roomKnown = cell, true
+	g.room = g.rooms[cell]
+	music := g.room.music
+	if music == "" {
+		music = defaultMusicPath
+	}
+	g.playMusic(music)
+}
+
+// drawRoomTint washes the screen with the current room's ambient color.
+func (g *Game) drawRoomTint(screen *ebiten.Image) {
+	if g.room.tint.A == 0 || !g.effectOn("room tint") {
+		return
+	}
+	b := screen.Bounds()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(b.Dx()), float64(b.Dy()))
+	op.GeoM.Translate(float64(b.Min.X), float64(b.Min.Y))
+	op.ColorScale.ScaleWithColor(g.room.tint)
+	screen.DrawImage(whitePixel(), op)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestRoomIndex(t *testing.T) {
+	const w, h, rows, cols = 1000, 600, 3, 4
+	tests := []struct {
+		name     string
+		x, y     float64
+		row, col int
+		ok       bool
+	}{
+		{"origin", 0, 0, 0, 0, true},
+		{"inside first room", 100, 100, 0, 0, true},
+		{"on a column boundary", 250, 100, 0, 1, true},
+		{"just before a column boundary", 249.999, 100, 0, 0, true},
+		{"on a row boundary", 100, 200, 1, 0, true},
+		{"middle", 600, 350, 1, 2, true},
+		{"far corner", 1000, 600, 2, 3, true},
+		{"far right edge", 1000, 10, 0, 3, true},
+		{"left of the world", -0.5, 100, 0, 0, false},
+		{"above the world", 100, -1, 0, 0, false},
+		{"right of the world", 1000.5, 100, 0, 0, false},
+		{"below the world", 100, 601, 0, 0, false},
+	}
+	for _, tt := range tests {
+		row, col, ok := roomIndex(tt.x, tt.y, w, h, rows, cols)
+		if row != tt.row || col != tt.col || ok != tt.ok {
+			t.Errorf("%s: roomIndex(%v, %v) = %d, %d, %v, want %d, %d, %v",
+				tt.name, tt.x, tt.y, row, col, ok, tt.row, tt.col, tt.ok)
+		}
+	}
+}
+
+func TestRoomIndexEmptyGrid(t *testing.T) {
+	for _, g := range [][4]float64{{0, 3, 100, 100}, {3, 0, 100, 100}, {3, 3, 0, 100}, {3, 3, 100, -1}} {
+		if _, _, ok := roomIndex(10, 10, g[2], g[3], int(g[0]), int(g[1])); ok {
+			t.Errorf("roomIndex on a %vx%v grid over %vx%v found a room", g[0], g[1], g[2], g[3])
+		}
+	}
+}
+
+func TestLoadRooms(t *testing.T) {
+	dir := filepath.Join("maps", "dungeon")
+	abs, _ := filepath.Abs(filepath.Join("music", "boss.ogg"))
+	grid := &RoomGrid{Rows: 2, Cols: 2, Rooms: []MetaRoom{
+		{Row: 0, Col: 1, Name: "Hall", Music: "hall.ogg", Tint: "#ff000080"},
+		{Row: 1, Col: 1, Name: "Boss", Music: abs, Tint: "red"},
+	}}
+	rooms := loadRooms(grid, dir)
+	if len(rooms) != 2 {
+		t.Fatalf("loadRooms gave %d rooms, want 2", len(rooms))
+	}
+	hall := rooms[[2]int{0, 1}]
+	if hall.name != "Hall" || hall.music != filepath.Join(dir, "hall.ogg") {
+		t.Errorf("hall = %q, %q", hall.name, hall.music)
+	}
+	if want := (color.RGBA{0x80, 0, 0, 0x80}); hall.tint != want {
+		t.Errorf("hall tint = %v, want %v", hall.tint, want)
+	}
+	boss := rooms[[2]int{1, 1}]
+	if boss.music != abs {
+		t.Errorf("absolute music path rewritten to %q", boss.music)
+	}
+	if boss.tint != (color.RGBA{}) {
+		t.Errorf("invalid tint kept as %v", boss.tint)
+	}
+}
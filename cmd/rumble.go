@@ -0,0 +1,33 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// rumble vibrates every connected gamepad for an event named in
+// rumble.events ("shake", "trigger" or "chest"), at strength times the
+// configured intensity. Gamepads without the standard layout are skipped;
+// those with it but no motors just ignore the request.
+func (g *Game) rumble(event string, strength float64) {
+	rc := g.cfg.Rumble
+	if !rc.Enabled || rc.Duration <= 0 || !slices.Contains(rc.Events, event) {
+		return
+	}
+	mag := max(min(rc.Intensity*strength, 1), 0)
+	if mag == 0 {
+		return
+	}
+	op := &ebiten.VibrateGamepadOptions{
+		Duration:        time.Duration(rc.Duration * float64(time.Second)),
+		StrongMagnitude: mag,
+		WeakMagnitude:   mag,
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			ebiten.VibrateGamepad(id, op)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+)
+
+// saveState is everything kept between sessions.
+type saveState struct {
+	Position *savedPosition `json:"position,omitempty"`
+	Fog      *fogData       `json:"fog,omitempty"`
+	Markers  []Label        `json:"markers,omitempty"`
+	Trail    []Label        `json:"trail,omitempty"`
+	Heatmap  *heatmapData   `json:"heatmap,omitempty"`
+	// positions of the chests already collected
+	Chests [][2]float64 `json:"chests,omitempty"`
+	Notes  []Note       `json:"notes,omitempty"`
+	// best speedrun times in seconds, by goal zone
+	Bests map[string]float64 `json:"bests,omitempty"`
+}
+
+type savedPosition struct {
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Zoom float64 `json:"zoom"`
+}
+
+// dataDir is where per-user state is stored.
+func dataDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "hyrule-map-explorer"), nil
+}
+
+func savePath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "save.json"), nil
+}
+
+// loadSave reads the save file. A missing file yields an empty state.
+func loadSave() (saveState, error) {
+	var st saveState
+	path, err := savePath()
+	if err != nil {
+		return st, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	err = json.Unmarshal(data, &st)
+	return st, err
+}
+
+// writeSave writes the state through a temporary file so a crash can't
+// leave a truncated save behind.
+func writeSave(st saveState) error {
+	path, err := savePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// saveState captures the persistent parts of the game.
+func (g *Game) saveState() saveState {
+	st := saveState{
+		Position: &savedPosition{X: g.px, Y: g.py, Zoom: g.zoom},
+		// copied so a background write never sees later edits
+		Markers: append([]Label(nil), g.markers...),
+		Trail:   append([]Label(nil), g.trail...),
+		Chests:  g.collectedChests(),
+		Notes:   append([]Note(nil), g.notes...),
+		Bests:   maps.Clone(g.runBests),
+	}
+	fog, heat := g.fog, g.heatmap
+	// a debug part view is never saved over the stitched map
+	if s := g.stitched; s != nil {
+		st.Position.X, st.Position.Y = s.px, s.py
+		fog, heat = s.fog, s.heatmap
+	}
+	if fog != nil {
+		st.Fog = fog.data()
+	}
+	if heat != nil {
+		st.Heatmap = heat.data()
+	}
+	return st
+}
+
+// applySave restores a previously saved state. The player position is
+// only restored when restorePosition is set.
+func (g *Game) applySave(st saveState, restorePosition bool) {
+	if st.Position != nil && restorePosition {
+		g.px, g.py = g.clampToMap(st.Position.X, st.Position.Y)
+		if st.Position.Zoom > 0 {
+			g.zoom = st.Position.Zoom
+		}
+	}
+	g.markers = st.Markers
+	g.nextMarker = lastMarkerNumber(st.Markers)
+	g.trail = st.Trail
+	g.savedChests = st.Chests
+	g.notes = st.Notes
+	g.runBests = st.Bests
+	if st.Fog != nil && g.fog != nil {
+		g.fog.restore(st.Fog)
+	}
+	if st.Heatmap != nil && g.heatmap != nil {
+		g.heatmap.restore(st.Heatmap)
+	}
+}
+
+// updateAutosave writes the save every save.autosaveInterval seconds. The
+// state is captured here on the game goroutine but written on another
+// one, and a new autosave is skipped while the previous is still running.
+func (g *Game) updateAutosave() {
+	select {
+	case err := <-g.autosaveDone:
+		if err != nil {
+			slog.Warn("autosave failed", "err", err)
+		} else {
+			g.toast("Autosaved")
+		}
+	default:
+	}
+	interval := g.cfg.Save.AutosaveInterval
+	if interval <= 0 {
+		return
+	}
+	g.sinceAutosave += deltaTime()
+	if g.sinceAutosave < interval {
+		return
+	}
+	if !g.autosaving.CompareAndSwap(false, true) {
+		return
+	}
+	g.sinceAutosave = 0
+	st := g.saveState()
+	go func() {
+		err := writeSave(st)
+		g.autosaving.Store(false)
+		g.autosaveDone <- err
+	}()
+}
+
+// waitAutosave blocks until an autosave still being written has finished,
+// so a later write can't interleave with it or be renamed over by it.
+func (g *Game) waitAutosave() {
+	if !g.autosaving.Load() {
+		return
+	}
+	if err := <-g.autosaveDone; err != nil {
+		slog.Warn("autosave failed", "err", err)
+	}
+}
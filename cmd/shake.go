@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// shake jolts the camera by up to intensity world pixels, decaying to
+// nothing over duration seconds, and rumbles the gamepads with it. A
+// weaker shake doesn't cut short a stronger one that is still running.
+func (g *Game) shake(intensity, duration float64) {
+	if intensity <= 0 || duration <= 0 {
+		return
+	}
+	// a 16 pixel shake is a full-strength rumble
+	g.rumble("shake", intensity/16)
+	if g.shakeLeft > 0 && g.shakeAmp*g.shakeLeft/g.shakeDuration > intensity {
+		return
+	}
+	g.shakeAmp = intensity
+	g.shakeDuration = duration
+	g.shakeLeft = duration
+}
+
+// updateShake picks this frame's shake offset, clamped so the shaken view
+// still stays inside the map.
+func (g *Game) updateShake() {
+	g.shakeX, g.shakeY = 0, 0
+	if g.shakeLeft <= 0 {
+		return
+	}
+	g.shakeLeft = max(g.shakeLeft-deltaTime(), 0)
+	amp := g.shakeAmp * g.shakeLeft / g.shakeDuration
+	angle := rand.Float64() * 2 * math.Pi
+	ox, oy := amp*math.Cos(angle), amp*math.Sin(angle)
+
+	// clamp the shaken origin like a regular camera position
+	vx, vy := g.vx, g.vy
+	g.vx += ox
+	g.vy += oy
+	g.clampView()
+	g.shakeX, g.shakeY = g.vx-vx, g.vy-vy
+	g.vx, g.vy = vx, vy
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var snapColor = color.RGBA{0x40, 0xff, 0x90, 0xc0}
+
+// snapping reports whether new markers are snapped, which is while Alt is
+// held.
+func (g *Game) snapping() bool {
+	return ebiten.IsKeyPressed(ebiten.KeyAlt) && (g.cfg.Snap.Radius > 0 || g.cfg.Snap.Grid > 0)
+}
+
+// rebuildMarkerGrid indexes the markers for snapping lookups.
+func (g *Game) rebuildMarkerGrid() {
+	g.markerGrid = newSpatialGrid(spatialCellSize)
+	for i, m := range g.markers {
+		g.markerGrid.insert(i, pointBounds(m.X, m.Y))
+	}
+}
+
+// snapPoint moves a world point into line with the markers within
+// snap.radius of it: into the same column as the closest one more above or
+// below it, and the same row as the closest one more beside it. Axes left
+// unaligned are rounded to snap.grid, if set. alignX and alignY are the
+// markers aligned with, or -1.
+func (g *Game) snapPoint(x, y float64) (sx, sy float64, alignX, alignY int) {
+	sx, sy, alignX, alignY = x, y, -1, -1
+	r := g.cfg.Snap.Radius
+	if r > 0 && g.markerGrid != nil {
+		bestX, bestY := math.Inf(1), math.Inf(1)
+		for _, i := range g.markerGrid.queryRegion(bounds{x - r, y - r, x + r, y + r}) {
+			m := g.markers[i]
+			dx, dy := math.Abs(m.X-x), math.Abs(m.Y-y)
+			if math.Hypot(dx, dy) > r {
+				continue
+			}
+			if dx <= dy && dx < bestX {
+				bestX, sx, alignX = dx, m.X, i
+			} else if dy < dx && dy < bestY {
+				bestY, sy, alignY = dy, m.Y, i
+			}
+		}
+	}
+	if step := g.cfg.Snap.Grid; step > 0 {
+		if alignX < 0 {
+			sx = math.Round(x/step) * step
+		}
+		if alignY < 0 {
+			sy = math.Round(y/step) * step
+		}
+	}
+	return sx, sy, alignX, alignY
+}
+
+// drawSnap shows where a marker placed now would land while snapping, with
+// guides to the markers it would line up with.
+func (g *Game) drawSnap(screen *ebiten.Image) {
+	if !g.snapping() || g.menu != nil {
+		return
+	}
+	wx, wy := g.cursorWorld()
+	x, y, alignX, alignY := g.snapPoint(wx, wy)
+	px, py := g.worldToScreen(x, y)
+	for _, i := range []int{alignX, alignY} {
+		if i < 0 {
+			continue
+		}
+		mx, my := g.worldToScreen(g.markers[i].X, g.markers[i].Y)
+		vector.StrokeLine(screen, float32(mx), float32(my), float32(px), float32(py), 1, snapColor, true)
+	}
+	vector.StrokeCircle(screen, float32(px), float32(py), 5, 1.5, snapColor, true)
+	vector.StrokeLine(screen, float32(px-8), float32(py), float32(px+8), float32(py), 1, snapColor, true)
+	vector.StrokeLine(screen, float32(px), float32(py-8), float32(px), float32(py+8), 1, snapColor, true)
+}
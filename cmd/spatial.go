@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"slices"
+)
+
+// spatialCellSize is the side of a spatial grid cell in world pixels.
+const spatialCellSize = 256.0
+
+// bounds is an axis-aligned world rectangle; a point has min == max.
+type bounds struct {
+	minX, minY, maxX, maxY float64
+}
+
+func pointBounds(x, y float64) bounds {
+	return bounds{x, y, x, y}
+}
+
+// spatialGrid is a uniform grid of cells, each listing the ids of the
+// entities whose bounds overlap it, so proximity queries only look at
+// nearby cells.
+type spatialGrid struct {
+	cell  float64
+	cells map[[2]int][]int
+	items map[int]bounds
+}
+
+func newSpatialGrid(cell float64) *spatialGrid {
+	return &spatialGrid{cell: cell, cells: map[[2]int][]int{}, items: map[int]bounds{}}
+}
+
+// cellRange is the inclusive range of cells b covers.
+func (s *spatialGrid) cellRange(b bounds) (c0, r0, c1, r1 int) {
+	return int(math.Floor(b.minX / s.cell)), int(math.Floor(b.minY / s.cell)),
+		int(math.Floor(b.maxX / s.cell)), int(math.Floor(b.maxY / s.cell))
+}
+
+// insert adds id with bounds b, replacing any previous entry for id.
+func (s *spatialGrid) insert(id int, b bounds) {
+	s.remove(id)
+	s.items[id] = b
+	c0, r0, c1, r1 := s.cellRange(b)
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			k := [2]int{c, r}
+			s.cells[k] = append(s.cells[k], id)
+		}
+	}
+}
+
+// remove drops id if it is present.
+func (s *spatialGrid) remove(id int) {
+	b, ok := s.items[id]
+	if !ok {
+		return
+	}
+	delete(s.items, id)
+	c0, r0, c1, r1 := s.cellRange(b)
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			k := [2]int{c, r}
+			ids := slices.DeleteFunc(s.cells[k], func(v int) bool { return v == id })
+			if len(ids) == 0 {
+				delete(s.cells, k)
+			} else {
+				s.cells[k] = ids
+			}
+		}
+	}
+}
+
+// queryRegion returns the ids whose bounds overlap b, in ascending order.
+func (s *spatialGrid) queryRegion(b bounds) []int {
+	var out []int
+	c0, r0, c1, r1 := s.cellRange(b)
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			for _, id := range s.cells[[2]int{c, r}] {
+				ib := s.items[id]
+				if ib.minX <= b.maxX && ib.maxX >= b.minX && ib.minY <= b.maxY && ib.maxY >= b.minY {
+					out = append(out, id)
+				}
+			}
+		}
+	}
+	// entities spanning several cells show up once per cell
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// gridIndex is a placeIndex backed by a spatial grid.
+type gridIndex struct {
+	places []Label
+	grid   *spatialGrid
+	// largest coordinate magnitude of any place, bounding how far a
+	// search has to widen
+	extent float64
+}
+
+func newGridIndex(places []Label) *gridIndex {
+	gi := &gridIndex{places: places, grid: newSpatialGrid(spatialCellSize)}
+	for i, p := range places {
+		gi.grid.insert(i, pointBounds(p.X, p.Y))
+		gi.extent = max(gi.extent, math.Abs(p.X), math.Abs(p.Y))
+	}
+	return gi
+}
+
+// nearest searches squares of growing size around (x, y) until the best
+// hit is closer than the square's half-size, so no place outside could
+// beat it.
+func (gi *gridIndex) nearest(x, y float64) (Label, float64, bool) {
+	if len(gi.places) == 0 {
+		return Label{}, 0, false
+	}
+	limit := 2 * (gi.extent + math.Abs(x) + math.Abs(y))
+	for r := gi.grid.cell; ; r *= 2 {
+		best, bestDist := -1, math.Inf(1)
+		for _, id := range gi.grid.queryRegion(bounds{x - r, y - r, x + r, y + r}) {
+			p := gi.places[id]
+			if d := math.Hypot(p.X-x, p.Y-y); d < bestDist {
+				best, bestDist = id, d
+			}
+		}
+		if best >= 0 && (bestDist <= r || r >= limit) {
+			return gi.places[best], bestDist, true
+		}
+	}
+}
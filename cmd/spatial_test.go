@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestSpatialGridQueryRegion(t *testing.T) {
+	s := newSpatialGrid(100)
+	s.insert(1, pointBounds(50, 50))
+	s.insert(2, pointBounds(150, 50))
+	// spans four cells
+	s.insert(3, bounds{80, 80, 120, 120})
+	s.insert(4, pointBounds(-50, -50))
+	tests := []struct {
+		name string
+		b    bounds
+		want []int
+	}{
+		{"one cell", bounds{0, 0, 99, 99}, []int{1, 3}},
+		{"spanning entity listed once", bounds{0, 0, 199, 199}, []int{1, 2, 3}},
+		{"negative coordinates", bounds{-100, -100, -1, -1}, []int{4}},
+		{"same cell, no overlap", bounds{0, 0, 40, 40}, nil},
+		{"edge touches", bounds{120, 120, 130, 130}, []int{3}},
+		{"empty area", bounds{500, 500, 600, 600}, nil},
+	}
+	for _, tt := range tests {
+		if got := s.queryRegion(tt.b); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: queryRegion(%v) = %v, want %v", tt.name, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSpatialGridRemoveAndReinsert(t *testing.T) {
+	s := newSpatialGrid(100)
+	s.insert(1, bounds{0, 0, 250, 250})
+	s.insert(2, pointBounds(10, 10))
+	s.remove(1)
+	if got := s.queryRegion(bounds{0, 0, 300, 300}); !slices.Equal(got, []int{2}) {
+		t.Errorf("after remove, queryRegion = %v, want [2]", got)
+	}
+	// no cell is left holding only removed ids
+	if len(s.cells) != 1 {
+		t.Errorf("after remove, %d cells are kept, want 1", len(s.cells))
+	}
+	// moving an id drops it from its old cells
+	s.insert(2, pointBounds(510, 510))
+	if got := s.queryRegion(bounds{0, 0, 100, 100}); got != nil {
+		t.Errorf("after moving, old area still has %v", got)
+	}
+	if got := s.queryRegion(bounds{500, 500, 600, 600}); !slices.Equal(got, []int{2}) {
+		t.Errorf("after moving, new area has %v, want [2]", got)
+	}
+	// removing an unknown id is a no-op
+	s.remove(99)
+}
+
+// randomPlaces scatters n places over a w x h world, the same ones for a
+// given n.
+func randomPlaces(n int, w, h float64) []Label {
+	r := rand.New(rand.NewPCG(uint64(n), 1))
+	places := make([]Label, n)
+	for i := range places {
+		places[i] = Label{X: r.Float64() * w, Y: r.Float64() * h}
+	}
+	return places
+}
+
+// The grid finds the same nearest place, at the same distance, as scanning
+// every place.
+func TestGridIndexMatchesLinear(t *testing.T) {
+	places := randomPlaces(2000, 8192, 4096)
+	grid, linear := newGridIndex(places), linearIndex(places)
+	r := rand.New(rand.NewPCG(7, 7))
+	for range 500 {
+		// include points off the map, where the search has to widen
+		x, y := r.Float64()*12000-2000, r.Float64()*8000-2000
+		_, gd, gok := grid.nearest(x, y)
+		_, ld, lok := linear.nearest(x, y)
+		if gok != lok || gd != ld {
+			t.Fatalf("nearest(%v, %v): grid %v (%v), linear %v (%v)", x, y, gd, gok, ld, lok)
+		}
+	}
+	if _, _, ok := newGridIndex(nil).nearest(0, 0); ok {
+		t.Error("an empty grid found a place")
+	}
+}
+
+func benchmarkNearest(b *testing.B, idx placeIndex) {
+	r := rand.New(rand.NewPCG(3, 3))
+	for b.Loop() {
+		idx.nearest(r.Float64()*8192, r.Float64()*4096)
+	}
+}
+
+func BenchmarkNearestLinear1k(b *testing.B) {
+	benchmarkNearest(b, linearIndex(randomPlaces(1000, 8192, 4096)))
+}
+
+func BenchmarkNearestGrid1k(b *testing.B) {
+	benchmarkNearest(b, newGridIndex(randomPlaces(1000, 8192, 4096)))
+}
+
+func BenchmarkNearestLinear10k(b *testing.B) {
+	benchmarkNearest(b, linearIndex(randomPlaces(10000, 8192, 4096)))
+}
+
+func BenchmarkNearestGrid10k(b *testing.B) {
+	benchmarkNearest(b, newGridIndex(randomPlaces(10000, 8192, 4096)))
+}
+
+// benchTriggers returns n 64x64 zones scattered over the world.
+func benchTriggers(n int) []TriggerZone {
+	places := randomPlaces(n, 8192, 4096)
+	zones := make([]TriggerZone, n)
+	for i, p := range places {
+		zones[i] = TriggerZone{X: p.X, Y: p.Y, W: 64, H: 64}
+	}
+	return zones
+}
+
+// the per-update check updateTriggers does, scanning every zone
+func BenchmarkTriggersLinear5k(b *testing.B) {
+	zones := benchTriggers(5000)
+	r := rand.New(rand.NewPCG(3, 3))
+	for b.Loop() {
+		x, y := r.Float64()*8192, r.Float64()*4096
+		for _, z := range zones {
+			_ = z.contains(x, y)
+		}
+	}
+}
+
+// and through the grid, as updateTriggers does it
+func BenchmarkTriggersGrid5k(b *testing.B) {
+	zones := benchTriggers(5000)
+	grid := newSpatialGrid(spatialCellSize)
+	for i, z := range zones {
+		grid.insert(i, z.bounds())
+	}
+	r := rand.New(rand.NewPCG(3, 3))
+	for b.Loop() {
+		x, y := r.Float64()*8192, r.Float64()*4096
+		for _, i := range grid.queryRegion(pointBounds(x, y)) {
+			_ = zones[i].contains(x, y)
+		}
+	}
+}
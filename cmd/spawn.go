@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Label is a named world location.
+type Label struct {
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+// findLabel looks up a label by name, ignoring case.
+func findLabel(labels []Label, name string) (Label, bool) {
+	for _, l := range labels {
+		if strings.EqualFold(l.Name, name) {
+			return l, true
+		}
+	}
+	return Label{}, false
+}
+
+// parseFinite parses a number, rejecting the NaN and infinities
+// strconv.ParseFloat accepts, which would slip through clamping into the
+// player and camera positions.
+func parseFinite(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, errors.New("not a finite number")
+	}
+	return v, nil
+}
+
+// parseCoord parses "x,y" into world coordinates.
+func parseCoord(s string) (x, y float64, err error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("want x,y, got %q", s)
+	}
+	x, err = parseFinite(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid x in %q", s)
+	}
+	y, err = parseFinite(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid y in %q", s)
+	}
+	return x, y, nil
+}
+
+// parseAt parses the -at flag, "x,y" or "x,y,zoom". zoom is 0 when not
+// given.
+func parseAt(s string) (x, y, zoom float64, err error) {
+	fields := strings.Split(s, ",")
+	switch len(fields) {
+	case 2:
+		x, y, err = parseCoord(s)
+		return x, y, 0, err
+	case 3:
+		x, y, err = parseCoord(fields[0] + "," + fields[1])
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		zoom, err = parseFinite(fields[2])
+		if err != nil || zoom <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid zoom in %q", s)
+		}
+		return x, y, zoom, nil
+	}
+	return 0, 0, 0, fmt.Errorf("want x,y[,zoom], got %q", s)
+}
+
+// resolveSpawn turns a spawn spec, either "x,y" or the name of a label,
+// into the world position the player should be centered on.
+func resolveSpawn(spec string, labels []Label) (x, y float64, err error) {
+	if l, ok := findLabel(labels, spec); ok {
+		return l.X, l.Y, nil
+	}
+	x, y, err = parseCoord(spec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unknown spawn %q: not a label or x,y", spec)
+	}
+	return x, y, nil
+}
+
+// clampPlayer keeps the player box inside the map.
+func (g *Game) clampPlayer() {
+	g.px, g.py = g.clampToMap(g.px, g.py)
+}
@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestParseCoord(t *testing.T) {
+	tests := []struct {
+		in   string
+		x, y float64
+		ok   bool
+	}{
+		{"100,200", 100, 200, true},
+		{" 12.5 , -3 ", 12.5, -3, true},
+		{"100", 0, 0, false},
+		{"1,2,3", 0, 0, false},
+		{"a,2", 0, 0, false},
+		{"1,b", 0, 0, false},
+		{"NaN,5", 0, 0, false},
+		{"5,nan", 0, 0, false},
+		{"Inf,5", 0, 0, false},
+		{"5,-Inf", 0, 0, false},
+		{"+inf,0", 0, 0, false},
+		{"1e400,0", 0, 0, false},
+	}
+	for _, tt := range tests {
+		x, y, err := parseCoord(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseCoord(%q) error = %v, want ok %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && (x != tt.x || y != tt.y) {
+			t.Errorf("parseCoord(%q) = %v, %v, want %v, %v", tt.in, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestParseAt(t *testing.T) {
+	tests := []struct {
+		in         string
+		x, y, zoom float64
+		ok         bool
+	}{
+		{"10,20", 10, 20, 0, true},
+		{"10,20,1.5", 10, 20, 1.5, true},
+		{"10,20,0", 0, 0, 0, false},
+		{"10,20,-2", 0, 0, 0, false},
+		{"10,20,NaN", 0, 0, 0, false},
+		{"10,20,Inf", 0, 0, 0, false},
+		{"NaN,20,1", 0, 0, 0, false},
+		{"10", 0, 0, 0, false},
+		{"1,2,3,4", 0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		x, y, zoom, err := parseAt(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseAt(%q) error = %v, want ok %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && (x != tt.x || y != tt.y || zoom != tt.zoom) {
+			t.Errorf("parseAt(%q) = %v, %v, %v, want %v, %v, %v", tt.in, x, y, zoom, tt.x, tt.y, tt.zoom)
+		}
+	}
+}
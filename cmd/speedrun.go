@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// speedrun is the timer racing to the speedrun.goal trigger zone.
+type speedrun struct {
+	// seconds since the first movement of this run
+	elapsed float64
+	// counting, and stopped at the goal; neither is set before the first
+	// movement
+	running, done bool
+}
+
+// formatRunTime formats seconds as MM:SS.mmm, with minutes going past 99
+// on very long runs.
+func formatRunTime(sec float64) string {
+	ms := int64(math.Round(max(sec, 0) * 1000))
+	return fmt.Sprintf("%02d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+}
+
+// recordBest stores t as the best time for goal if there is none yet or
+// it beats the old one, and reports whether it did.
+func recordBest(bests map[string]float64, goal string, t float64) bool {
+	if old, ok := bests[goal]; ok && old <= t {
+		return false
+	}
+	bests[goal] = t
+	return true
+}
+
+// hasTrigger reports whether one of zones is called name.
+func hasTrigger(zones []TriggerZone, name string) bool {
+	return slices.ContainsFunc(zones, func(z TriggerZone) bool { return z.Name == name })
+}
+
+// updateSpeedrun starts the timer on the player's first movement and
+// counts while the run is on. Backspace resets the run and puts the player
+// back at the spawn.
+func (g *Game) updateSpeedrun(moved bool) {
+	if g.cfg.Speedrun.Goal == "" {
+		return
+	}
+	if g.justPressed(ebiten.KeyBackspace) {
+		g.run = speedrun{}
+		g.teleport(g.spawnX, g.spawnY)
+		g.toast("Run reset")
+		return
+	}
+	if moved && !g.run.running && !g.run.done {
+		g.run.running = true
+	}
+	if g.run.running {
+		g.run.elapsed += deltaTime()
+	}
+}
+
+// finishRun stops the timer when the zone entered is the goal.
+func (g *Game) finishRun(z TriggerZone) {
+	if !g.run.running || z.Name != g.cfg.Speedrun.Goal {
+		return
+	}
+	g.run.running, g.run.done = false, true
+	if g.runBests == nil {
+		g.runBests = make(map[string]float64)
+	}
+	msg := "Reached " + z.Name + " in " + formatRunTime(g.run.elapsed)
+	if recordBest(g.runBests, z.Name, g.run.elapsed) {
+		msg += " - new best!"
+	}
+	g.toast(msg)
+}
+
+// speedrunText is the HUD line for the timer and the goal's best time.
+func (g *Game) speedrunText() string {
+	text := "Run " + formatRunTime(g.run.elapsed)
+	if best, ok := g.runBests[g.cfg.Speedrun.Goal]; ok {
+		text += " (best " + formatRunTime(best) + ")"
+	}
+	return text
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestFormatRunTime(t *testing.T) {
+	tests := []struct {
+		sec  float64
+		want string
+	}{
+		{0, "00:00.000"},
+		{0.0004, "00:00.000"},
+		{0.0005, "00:00.001"},
+		{1.5, "00:01.500"},
+		{59.9996, "01:00.000"},
+		{61.25, "01:01.250"},
+		{3599.999, "59:59.999"},
+		{6000, "100:00.000"},
+		{-3, "00:00.000"},
+	}
+	for _, tt := range tests {
+		if got := formatRunTime(tt.sec); got != tt.want {
+			t.Errorf("formatRunTime(%v) = %q, want %q", tt.sec, got, tt.want)
+		}
+	}
+}
+
+func TestRecordBest(t *testing.T) {
+	bests := map[string]float64{}
+	steps := []struct {
+		name string
+		goal string
+		t    float64
+		want bool
+		best float64
+	}{
+		{"first run", "Tower", 30, true, 30},
+		{"slower", "Tower", 31, false, 30},
+		{"a tie", "Tower", 30, false, 30},
+		{"faster", "Tower", 29.5, true, 29.5},
+		{"another goal", "Lake", 40, true, 40},
+	}
+	for _, s := range steps {
+		if got := recordBest(bests, s.goal, s.t); got != s.want {
+			t.Errorf("%s: recordBest(%s, %v) = %v, want %v", s.name, s.goal, s.t, got, s.want)
+		}
+		if bests[s.goal] != s.best {
+			t.Errorf("%s: best for %s = %v, want %v", s.name, s.goal, bests[s.goal], s.best)
+		}
+	}
+	if bests["Tower"] != 29.5 {
+		t.Errorf("best for Tower = %v after another goal's run, want 29.5", bests["Tower"])
+	}
+}
+
+// A run starts on the first movement, stops at the goal and only keeps
+// the best time.
+func TestSpeedrun(t *testing.T) {
+	g := newTestGame(1000, 1000)
+	g.cfg.Speedrun.Goal = "Tower"
+	goal := TriggerZone{Name: "Tower"}
+
+	g.updateSpeedrun(false)
+	if g.run.running || g.run.elapsed != 0 {
+		t.Fatalf("run started before moving: %+v", g.run)
+	}
+	for range 60 {
+		g.updateSpeedrun(true)
+	}
+	g.finishRun(TriggerZone{Name: "Lake"})
+	if !g.run.running {
+		t.Fatal("another zone stopped the run")
+	}
+	g.finishRun(goal)
+	if g.run.running || !g.run.done {
+		t.Fatalf("run not done at the goal: %+v", g.run)
+	}
+	if got, want := g.toasts[len(g.toasts)-1].msg, "Reached Tower in 00:01.000 - new best!"; got != want {
+		t.Errorf("toast %q, want %q", got, want)
+	}
+	elapsed := g.run.elapsed
+	g.updateSpeedrun(true)
+	if g.run.elapsed != elapsed {
+		t.Error("timer kept counting after the goal")
+	}
+
+	keys := &fakeKeys{}
+	g.keys = keys
+	keys.hold(ebiten.KeyBackspace)
+	g.updateSpeedrun(false)
+	if g.run != (speedrun{}) {
+		t.Errorf("Backspace left the run at %+v", g.run)
+	}
+	keys.hold()
+	for range 90 {
+		g.updateSpeedrun(true)
+	}
+	g.finishRun(goal)
+	if got, want := g.toasts[len(g.toasts)-1].msg, "Reached Tower in 00:01.500"; got != want {
+		t.Errorf("slower run toast %q, want %q", got, want)
+	}
+	if got, want := g.speedrunText(), "Run 00:01.500 (best 00:01.000)"; got != want {
+		t.Errorf("speedrunText = %q, want %q", got, want)
+	}
+}
+
+func TestSpeedrunOff(t *testing.T) {
+	g := newTestGame(1000, 1000)
+	g.updateSpeedrun(true)
+	if g.run.running {
+		t.Error("run started with no goal")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"image/color"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// scene is the top-level state the game is in.
+type scene int
+
+const (
+	sceneSplash scene = iota
+	sceneMap
+	// fading through a fast travel; input waits until it is over
+	sceneTravel
+)
+
+// splash is the optional logo screen shown at launch.
+type splash struct {
+	logo    *ebiten.Image
+	bg      color.RGBA
+	elapsed float64
+}
+
+// newSplash loads the configured logo. It returns nil when no splash should
+// be shown.
+func newSplash(cfg SplashConfig) *splash {
+	if cfg.Logo == "" || cfg.Duration <= 0 {
+		return nil
+	}
+	logo, err := loadImage(cfg.Logo)
+	if err != nil {
+		slog.Warn("failed to load splash logo", "path", cfg.Logo, "err", err)
+		return nil
+	}
+	bg, err := parseHexColor(cfg.Background)
+	if err != nil {
+		slog.Warn("invalid splash background", "err", err)
+		bg = color.RGBA{A: 0xff}
+	}
+	return &splash{logo: logo, bg: bg}
+}
+
+func (g *Game) updateSplash() {
+	if g.cfg.Splash.MusicDuringSplash {
+		g.loopMusic()
+	}
+	g.splash.elapsed += deltaTime()
+	skipped := len(inpututil.AppendJustPressedKeys(nil)) > 0
+	if skipped || g.splash.elapsed >= g.cfg.Splash.Duration {
+		g.scene = sceneMap
+		g.splash = nil
+	}
+}
+
+// splashAlpha is 1 while the splash is fully shown and falls to 0 over the
+// fade at its end.
+func (g *Game) splashAlpha() float64 {
+	fade := min(g.cfg.Splash.Fade, g.cfg.Splash.Duration)
+	remaining := g.cfg.Splash.Duration - g.splash.elapsed
+	if fade <= 0 || remaining >= fade {
+		return 1
+	}
+	return max(remaining/fade, 0)
+}
+
+func (g *Game) drawSplash(screen *ebiten.Image) {
+	alpha := g.splashAlpha()
+	if alpha < 1 {
+		// let the map show through while fading out
+		g.drawWorld(screen)
+	}
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+
+	bgOp := &ebiten.DrawImageOptions{}
+	bgOp.GeoM.Scale(float64(sw), float64(sh))
+	bgOp.ColorScale.ScaleWithColor(g.splash.bg)
+	bgOp.ColorScale.ScaleAlpha(float32(alpha))
+	screen.DrawImage(whitePixel(), bgOp)
+
+	// center the logo, shrinking it if it doesn't fit in most of the screen
+	lw, lh := g.splash.logo.Bounds().Dx(), g.splash.logo.Bounds().Dy()
+	scale := min(1, 0.6*float64(sw)/float64(lw), 0.6*float64(sh)/float64(lh))
+	logoOp := &ebiten.DrawImageOptions{}
+	logoOp.GeoM.Scale(scale, scale)
+	logoOp.GeoM.Translate((float64(sw)-float64(lw)*scale)/2, (float64(sh)-float64(lh)*scale)/2)
+	logoOp.ColorScale.ScaleAlpha(float32(alpha))
+	logoOp.Filter = ebiten.FilterLinear
+	screen.DrawImage(g.splash.logo, logoOp)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fitSize scales srcW x srcH to the largest size that fits in boxW x boxH
+// while keeping its aspect ratio. Neither side drops below one pixel.
+func fitSize(srcW, srcH, boxW, boxH int) (w, h int) {
+	if srcW <= 0 || srcH <= 0 {
+		return boxW, boxH
+	}
+	scale := min(float64(boxW)/float64(srcW), float64(boxH)/float64(srcH))
+	w = max(int(math.Round(float64(srcW)*scale)), 1)
+	h = max(int(math.Round(float64(srcH)*scale)), 1)
+	return w, h
+}
+
+// fitSprite returns src scaled uniformly to fit in boxW x boxH.
+func fitSprite(src *ebiten.Image, boxW, boxH int) *ebiten.Image {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	w, h := fitSize(srcW, srcH, boxW, boxH)
+	dst := ebiten.NewImage(w, h)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(w)/float64(srcW), float64(h)/float64(srcH))
+	dst.DrawImage(src, op)
+	return dst
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		name                   string
+		srcW, srcH, boxW, boxH int
+		w, h                   int
+	}{
+		{"2:1 into square box", 64, 32, 32, 32, 32, 16},
+		{"1:2 into square box", 32, 64, 32, 32, 16, 32},
+		{"2:1 upscaled", 20, 10, 100, 100, 100, 50},
+		{"2:1 into 2:1 box", 64, 32, 128, 64, 128, 64},
+		{"2:1 into wide box", 64, 32, 200, 32, 64, 32},
+		{"square into tall box", 16, 16, 16, 48, 16, 16},
+		{"rounds to nearest", 30, 20, 10, 10, 10, 7},
+		{"thin side kept at one pixel", 1000, 1, 10, 10, 10, 1},
+		{"zero-width source fills the box", 0, 32, 24, 24, 24, 24},
+		{"zero-height source fills the box", 32, 0, 24, 24, 24, 24},
+		{"zero-size box", 64, 32, 0, 0, 1, 1},
+	}
+	for _, tt := range tests {
+		w, h := fitSize(tt.srcW, tt.srcH, tt.boxW, tt.boxH)
+		if w != tt.w || h != tt.h {
+			t.Errorf("%s: fitSize(%d, %d, %d, %d) = %d x %d, want %d x %d", tt.name, tt.srcW, tt.srcH, tt.boxW, tt.boxH, w, h, tt.w, tt.h)
+		}
+	}
+}
+
+// A 2:1 sprite keeps its aspect ratio, to within rounding, whatever box it
+// is fitted into, and never spills out of the box.
+func TestFitSizeKeepsAspect(t *testing.T) {
+	for boxW := 8; boxW <= 256; boxW += 13 {
+		for boxH := 8; boxH <= 256; boxH += 11 {
+			w, h := fitSize(200, 100, boxW, boxH)
+			if w > boxW || h > boxH {
+				t.Fatalf("fitSize(200, 100, %d, %d) = %d x %d, larger than the box", boxW, boxH, w, h)
+			}
+			if d := w - 2*h; d < -1 || d > 1 {
+				t.Fatalf("fitSize(200, 100, %d, %d) = %d x %d, not 2:1", boxW, boxH, w, h)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import "log/slog"
+
+// bgSwap crossfades from the previous background to g.bg.
+type bgSwap struct {
+	old     *background
+	elapsed float64
+	// requested while this swap was fading, started once it completes
+	pending *background
+}
+
+// swapBackground replaces the background, fading the old one out over
+// map.swapFade seconds. A swap requested mid-fade waits for the current
+// one to finish; only the latest such request is kept.
+func (g *Game) swapBackground(bg *background) {
+	if g.swap != nil {
+		if g.swap.pending != nil {
+			g.swap.pending.deallocate()
+		}
+		g.swap.pending = bg
+		return
+	}
+	if g.cfg.Map.SwapFade <= 0 || g.bg == nil {
+		old := g.bg
+		g.bg = bg
+		g.finishSwap(old)
+		return
+	}
+	g.swap = &bgSwap{old: g.bg}
+	g.bg = bg
+}
+
+func (g *Game) updateSwap() {
+	if g.swap == nil {
+		return
+	}
+	g.swap.elapsed += deltaTime()
+	if g.swap.elapsed < g.cfg.Map.SwapFade {
+		return
+	}
+	swap := g.swap
+	g.swap = nil
+	g.finishSwap(swap.old)
+	if swap.pending != nil {
+		g.swapBackground(swap.pending)
+	}
+}
+
+// finishSwap frees the old background and recomputes everything derived
+// from the map size. A debug part view gets throwaway layers and the
+// player in its middle; the stitched map gets its own back afterwards.
+func (g *Game) finishSwap(old *background) {
+	if old != nil && old != g.bg {
+		old.deallocate()
+	}
+	bw, bh := g.bg.size()
+	g.tileW, g.tileH = deriveTileSize(bw, bh, targetTile)
+	restored := g.swapPartView()
+	if !restored && (g.fog == nil || old == nil || old.w != bw || old.h != bh || g.soloPart != 0) {
+		g.fog = newFogLayer(bw, bh)
+		g.exploreReached = 0
+		g.heatmap = newHeatmap(bw, bh, g.tileW, g.tileH)
+	}
+	g.updateMinZoom()
+	g.updateMinimapRect()
+	g.clampPlayer()
+	switch {
+	case g.soloPart != 0:
+		g.centerOnMap()
+	case restored:
+		g.follow(false)
+	}
+}
+
+// swapAlpha is the opacity of the outgoing background.
+func (g *Game) swapAlpha() float32 {
+	if g.swap == nil || g.cfg.Map.SwapFade <= 0 {
+		return 0
+	}
+	return float32(max(1-g.swap.elapsed/g.cfg.Map.SwapFade, 0))
+}
+
+// reloadBackground reads the map parts from disk again.
+func (g *Game) reloadBackground() {
+	bg, err := loadMapBackground(g.cfg.Map, g.meta, nil)
+	if err != nil {
+		slog.Warn("failed to reload background", "path", g.cfg.Map.Parts, "err", err)
+		return
+	}
+	slog.Info("reloaded background", "path", g.cfg.Map.Parts)
+	g.swapBackground(bg)
+}
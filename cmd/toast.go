@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.design/x/clipboard"
+)
+
+// toastDuration is how long a toast stays on screen, in seconds.
+const toastDuration = 2.0
+
+type toast struct {
+	msg       string
+	remaining float64
+}
+
+// toast queues a short message at the bottom of the screen.
+func (g *Game) toast(msg string) {
+	g.toasts = append(g.toasts, toast{msg: msg, remaining: toastDuration})
+}
+
+func (g *Game) updateToasts() {
+	dt := deltaTime()
+	kept := g.toasts[:0]
+	for _, t := range g.toasts {
+		t.remaining -= dt
+		if t.remaining > 0 {
+			kept = append(kept, t)
+		}
+	}
+	g.toasts = kept
+}
+
+func (g *Game) drawToasts(screen *ebiten.Image) {
+	lineHeight := textLineHeight()
+	y := screen.Bounds().Dy() - lineHeight*(len(g.toasts)+1)
+	for _, t := range g.toasts {
+		drawText(screen, t.msg, 8, y)
+		y += lineHeight
+	}
+}
+
+// coordinateText is what copying the world point (x, y) puts on the
+// clipboard, always whole map pixels as "x,y" so it can be pasted back
+// into -spawn or -at, and what the toast shows, which adds the position
+// in world units when readouts use them.
+func (g *Game) coordinateText(x, y float64) (clip, shown string) {
+	clip = fmt.Sprintf("%.0f,%.0f", x, y)
+	shown = clip
+	if g.usingUnits() {
+		shown += " (" + g.formatUnits(x) + "," + g.formatUnits(y) + " " + g.unitName() + ")"
+	}
+	return clip, shown
+}
+
+// copyCoordinates puts the player's world position (or the cursor's, with
+// atCursor) on the system clipboard as "x,y".
+func (g *Game) copyCoordinates(atCursor bool) {
+	x := g.px + float64(g.playerW)/2
+	y := g.py + float64(g.playerH)/2
+	if atCursor {
+		x, y = g.cursorWorld()
+	}
+	clip, shown := g.coordinateText(x, y)
+	if !g.clipboardOK {
+		slog.Info("clipboard unavailable", "coordinates", clip)
+		g.toast("Clipboard unavailable: " + shown)
+		return
+	}
+	clipboard.Write(clipboard.FmtText, []byte(clip))
+	g.toast("Copied " + shown)
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestCoordinateText(t *testing.T) {
+	tests := []struct {
+		name        string
+		ppu         float64
+		unit        string
+		raw         bool
+		clip, shown string
+	}{
+		{"no units", 0, "", false, "1235,-40", "1235,-40"},
+		{"units", 16, "m", false, "1235,-40", "1235,-40 (77.2,-2.5 m)"},
+		{"unnamed units", 16, "", false, "1235,-40", "1235,-40 (77.2,-2.5 units)"},
+		{"units switched off", 16, "m", true, "1235,-40", "1235,-40"},
+	}
+	for _, tt := range tests {
+		g := newTestGame(2000, 2000)
+		g.cfg.Map.PixelsPerUnit = tt.ppu
+		g.cfg.Map.Unit = tt.unit
+		g.rawPixels = tt.raw
+		clip, shown := g.coordinateText(1234.6, -40.2)
+		if clip != tt.clip || shown != tt.shown {
+			t.Errorf("%s: coordinateText = %q, %q, want %q, %q", tt.name, clip, shown, tt.clip, tt.shown)
+		}
+	}
+}
+
+// What is copied parses back as a spawn point at the same pixel.
+func TestCoordinateTextRoundTrips(t *testing.T) {
+	g := newTestGame(2000, 2000)
+	g.cfg.Map.PixelsPerUnit = 16
+	clip, _ := g.coordinateText(812, 96)
+	x, y, err := parseCoord(clip)
+	if err != nil || x != 812 || y != 96 {
+		t.Errorf("parseCoord(%q) = %v, %v, %v, want 812, 96", clip, x, y, err)
+	}
+}
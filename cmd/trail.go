@@ -0,0 +1,57 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// trailMinStep is how far, in world pixels, the player has to have moved
+// since the last auto-marker for a new one to be dropped.
+const trailMinStep = 8.0
+
+var trailColor = color.RGBA{0xff, 0xd0, 0x20, 0x60}
+
+// updateTrail drops a faint auto-marker at the player every
+// trail.interval seconds while they keep moving, and clears the trail on
+// Delete.
+func (g *Game) updateTrail() {
+	if g.justPressed(ebiten.KeyDelete) && len(g.trail) > 0 {
+		g.trail = nil
+		g.toast("Cleared trail")
+	}
+	interval := g.cfg.Trail.Interval
+	if interval <= 0 {
+		return
+	}
+	g.sinceTrail += deltaTime()
+	if g.sinceTrail < interval {
+		return
+	}
+	g.sinceTrail = 0
+	x := math.Round(g.px + float64(g.playerW)/2)
+	y := math.Round(g.py + float64(g.playerH)/2)
+	if n := len(g.trail); n > 0 && math.Hypot(x-g.trail[n-1].X, y-g.trail[n-1].Y) < trailMinStep {
+		return
+	}
+	g.trail = append(g.trail, Label{X: x, Y: y})
+	// drop the oldest once over the limit
+	if limit := g.cfg.Trail.Max; limit > 0 && len(g.trail) > limit {
+		g.trail = append(g.trail[:0], g.trail[len(g.trail)-limit:]...)
+	}
+}
+
+// drawTrail draws the auto-markers as small translucent dots under the
+// real markers.
+func (g *Game) drawTrail(screen *ebiten.Image) {
+	if len(g.trail) == 0 || !g.effectOn("trail") {
+		return
+	}
+	scale, tx, ty := g.worldTransform(g.screenW, g.screenH)
+	for _, m := range g.trail {
+		x, y := m.X*scale+tx, m.Y*scale+ty
+		vector.FillCircle(screen, float32(x), float32(y), 2.5, trailColor, true)
+	}
+}
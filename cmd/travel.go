@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// travel is a fast travel in progress: the screen fades to black, the
+// player is moved at the midpoint, and the destination fades in.
+type travel struct {
+	x, y    float64
+	elapsed float64
+	moved   bool
+}
+
+// fastTravel takes the player to a place, through a fade when
+// travel.fade is set, unless the cooldown from the last trip is still
+// running.
+func (g *Game) fastTravel(p Label) {
+	g.menu = nil
+	if g.travelCooldown > 0 {
+		g.toast(fmt.Sprintf("Fast travel ready in %.0fs", math.Ceil(g.travelCooldown)))
+		return
+	}
+	g.travelCooldown = g.cfg.Travel.Cooldown
+	if g.cfg.Travel.Fade <= 0 {
+		g.focusOn(p.X, p.Y)
+		g.ping(p.X, p.Y)
+		return
+	}
+	g.travel = &travel{x: p.X, y: p.Y}
+	g.scene = sceneTravel
+}
+
+// updateTravel counts the cooldown down and advances a fade in progress,
+// moving the player once the screen is fully black.
+func (g *Game) updateTravel() {
+	dt := deltaTime()
+	g.travelCooldown = max(g.travelCooldown-dt, 0)
+	t := g.travel
+	if t == nil {
+		return
+	}
+	t.elapsed += dt
+	if !t.moved && t.elapsed >= g.cfg.Travel.Fade/2 {
+		t.moved = true
+		g.teleport(t.x, t.y)
+		g.ping(t.x, t.y)
+	}
+	if t.elapsed >= g.cfg.Travel.Fade {
+		g.travel = nil
+		g.scene = sceneMap
+	}
+}
+
+// teleport puts the player, or the free camera, on a world point at once.
+// The camera follows through moveCameraTo, so it snaps there on a long
+// jump and eases on a short one.
+func (g *Game) teleport(x, y float64) {
+	g.route = nil
+	if g.freeCam {
+		g.moveCameraTo(x, y, false)
+		return
+	}
+	g.px = x - float64(g.playerW)/2
+	g.py = y - float64(g.playerH)/2
+	g.clampPlayer()
+	g.unstick()
+	g.follow(false)
+}
+
+// travelAlpha is the opacity of the black overlay, rising to 1 at the
+// midpoint of the fade and falling back after it.
+func (g *Game) travelAlpha() float64 {
+	half := g.cfg.Travel.Fade / 2
+	if g.travel == nil || half <= 0 {
+		return 0
+	}
+	return max(1-math.Abs(g.travel.elapsed-half)/half, 0)
+}
+
+func (g *Game) drawTravel(screen *ebiten.Image) {
+	g.drawWorld(screen)
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(sw), float64(sh))
+	op.ColorScale.Scale(0, 0, 0, float32(g.travelAlpha()))
+	screen.DrawImage(whitePixel(), op)
+}
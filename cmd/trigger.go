@@ -0,0 +1,61 @@
+package main
+
+import "slices"
+
+// TriggerZone is a world rectangle that fires when the player enters it.
+type TriggerZone struct {
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	W    float64 `json:"w"`
+	H    float64 `json:"h"`
+	// toast shown on entry, if any
+	Message string `json:"message"`
+	// camera shake on entry in world pixels, and how long it lasts
+	Shake         float64 `json:"shake"`
+	ShakeDuration float64 `json:"shakeDuration"`
+}
+
+func (t TriggerZone) contains(x, y float64) bool {
+	return x >= t.X && y >= t.Y && x < t.X+t.W && y < t.Y+t.H
+}
+
+func (t TriggerZone) bounds() bounds {
+	return bounds{t.X, t.Y, t.X + t.W, t.Y + t.H}
+}
+
+// updateTriggers fires the zones the player's center just entered. Only
+// zones near the player are looked at, through a spatial grid built on
+// first use.
+func (g *Game) updateTriggers() {
+	zones := g.cfg.Triggers
+	if g.triggerGrid == nil {
+		g.triggerGrid = newSpatialGrid(spatialCellSize)
+		for i, z := range zones {
+			g.triggerGrid.insert(i, z.bounds())
+		}
+	}
+	cx := g.px + float64(g.playerW)/2
+	cy := g.py + float64(g.playerH)/2
+	var inside []int
+	for _, i := range g.triggerGrid.queryRegion(pointBounds(cx, cy)) {
+		if zones[i].contains(cx, cy) {
+			inside = append(inside, i)
+			if !slices.Contains(g.inTrigger, i) {
+				g.enterTrigger(zones[i])
+			}
+		}
+	}
+	g.inTrigger = inside
+}
+
+func (g *Game) enterTrigger(z TriggerZone) {
+	if z.Message != "" {
+		g.toast(z.Message)
+	}
+	g.shake(z.Shake, z.ShakeDuration)
+	g.rumble("trigger", 1)
+	g.duckMusic(g.cfg.Audio.Duck.Amount, g.cfg.Audio.Duck.Duration)
+	g.ping(z.X+z.W/2, z.Y+z.H/2)
+	g.finishRun(z)
+}
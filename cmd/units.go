@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// usingUnits reports whether readouts are shown in world units rather than
+// map pixels.
+func (g *Game) usingUnits() bool {
+	return g.cfg.Map.PixelsPerUnit > 0 && !g.rawPixels
+}
+
+// worldToUnits converts a distance or coordinate in map pixels into the
+// unit readouts are shown in.
+func (g *Game) worldToUnits(px float64) float64 {
+	if !g.usingUnits() {
+		return px
+	}
+	return px / g.cfg.Map.PixelsPerUnit
+}
+
+// unitName is the suffix put after readouts.
+func (g *Game) unitName() string {
+	if !g.usingUnits() {
+		return "px"
+	}
+	if g.cfg.Map.Unit == "" {
+		return "units"
+	}
+	return g.cfg.Map.Unit
+}
+
+// formatUnits formats a map pixel value for display, keeping a decimal
+// place in world units where one unit usually covers many pixels.
+func (g *Game) formatUnits(px float64) string {
+	if !g.usingUnits() {
+		return fmt.Sprintf("%.0f", px)
+	}
+	return fmt.Sprintf("%.1f", g.worldToUnits(px))
+}
+
+// updateUnits switches readouts between world units and raw pixels on F8.
+func (g *Game) updateUnits() {
+	if g.cfg.Map.PixelsPerUnit <= 0 || !g.justPressed(ebiten.KeyF8) {
+		return
+	}
+	g.rawPixels = !g.rawPixels
+	g.toast("Readouts in " + g.unitName())
+}
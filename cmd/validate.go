@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// asset is a file the config refers to. Required assets stop the game
+// from starting when they are broken; the others are skipped with a
+// warning.
+type asset struct {
+	kind     string
+	path     string
+	sound    bool
+	font     bool
+	required bool
+}
+
+// configAssets lists every asset the config and map metadata refer to.
+func configAssets(cfg Config, meta *MapMeta) ([]asset, error) {
+	var assets []asset
+	if meta != nil && len(meta.Parts) > 0 {
+		for _, p := range meta.Parts {
+			assets = append(assets, asset{kind: "map part", path: p.File, required: true})
+		}
+	} else {
+		paths, err := filepath.Glob(cfg.Map.Parts)
+		if err != nil {
+			return nil, fmt.Errorf("map parts %s: %w", cfg.Map.Parts, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no files match map parts %s", cfg.Map.Parts)
+		}
+		for _, p := range paths {
+			assets = append(assets, asset{kind: "map part", path: p, required: true})
+		}
+	}
+	assets = append(assets, asset{kind: "player sprite", path: playerSpritePath, required: true})
+	assets = append(assets, asset{kind: "music", path: defaultMusicPath, sound: true})
+	optional := []asset{
+		{kind: "collision mask", path: cfg.Collision.Mask},
+		{kind: "splash logo", path: cfg.Splash.Logo},
+		{kind: "frame image", path: cfg.Frame.Image},
+		{kind: "map overlay", path: cfg.Map.Overlay.Image},
+		{kind: "ping sound", path: cfg.Ping.Sound, sound: true},
+		{kind: "chest sound", path: cfg.Chests.Sound, sound: true},
+		{kind: "font", path: cfg.Font.Path, font: true},
+	}
+	if cfg.Map.Backdrop.Mode == "texture" {
+		optional = append(optional, asset{kind: "backdrop texture", path: cfg.Map.Backdrop.Texture})
+	}
+	if cfg.TwoPlayer.Enabled {
+		optional = append(optional, asset{kind: "player two sprite", path: cfg.TwoPlayer.Sprite})
+	}
+	for _, s := range cfg.Ambient.Sources {
+		optional = append(optional, asset{kind: "ambient sound " + s.Name, path: s.Sound, sound: true})
+	}
+	if meta != nil && meta.Rooms != nil {
+		for name, r := range loadRooms(meta.Rooms, meta.dir) {
+			optional = append(optional, asset{kind: fmt.Sprintf("room %d,%d music", name[0], name[1]), path: r.music, sound: true})
+		}
+	}
+	for _, a := range optional {
+		if a.path != "" {
+			assets = append(assets, a)
+		}
+	}
+	return assets, nil
+}
+
+// checkAsset decodes an asset fully, without keeping it.
+func checkAsset(a asset) error {
+	if a.font {
+		f, err := os.Open(a.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = text.NewGoTextFaceSource(f)
+		return err
+	}
+	if !a.sound {
+		_, err := decodeImage(a.path)
+		return err
+	}
+	f, err := os.Open(a.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	stream, err := mp3.DecodeWithoutResampling(f)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(io.Discard, stream)
+	return err
+}
+
+// validateAssets checks every configured asset and reports all problems
+// rather than stopping at the first.
+func validateAssets(cfg Config, meta *MapMeta) []error {
+	assets, err := configAssets(cfg, meta)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, a := range assets {
+		if err := checkAsset(a); err != nil {
+			level := "optional"
+			if a.required {
+				level = "required"
+			}
+			errs = append(errs, fmt.Errorf("%s %s %s: %w", level, a.kind, a.path, err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,93 @@
+package main
+
+import "math"
+
+// viewTransform returns the scale and offset that map world coordinates
+// onto a screen of sw x sh pixels: screen = (world - v) * scale + d. The
+// map is laid out in mapArea, which is the whole screen unless a frame
+// insets it.
+func (g *Game) viewTransform(sw, sh int) (scale, dx, dy float64) {
+	// desired viewport in background image coordinates (tile size at the
+	// current zoom)
+	vw, vh := g.viewSize()
+	area := g.mapArea(sw, sh)
+	sw, sh = area.Dx(), area.Dy()
+
+	// compute scale to cover the screen while preserving aspect ratio
+	sx := float64(sw) / vw
+	sy := float64(sh) / vh
+	// use the larger scale so the viewport covers the whole screen (no empty bars)
+	scale = capScale(math.Max(sx, sy), g.cfg.Map.MaxUpscale)
+
+	// Scale first, then translate so that the viewport's (vx,vy) maps to
+	// the screen origin, and then center the scaled viewport on the screen.
+	// After scaling, add an offset to center if the scaled viewport is larger
+	// than the screen in one dimension.
+	dx = float64(area.Min.X) + (float64(sw)-vw*scale)/2
+	dy = float64(area.Min.Y) + (float64(sh)-vh*scale)/2
+	return scale, dx, dy
+}
+
+// capScale limits a cover scale to maxUpscale screen pixels per world
+// pixel so small maps aren't blown up into a blur; maxUpscale <= 0 leaves
+// it alone. A capped viewport no longer covers the screen and the
+// centering in viewTransform letterboxes it.
+func capScale(scale, maxUpscale float64) float64 {
+	if maxUpscale <= 0 {
+		return scale
+	}
+	return math.Min(scale, maxUpscale)
+}
+
+// viewSize is the nominal viewport size in world pixels, one tile at the
+// current zoom. The visible area is this size cropped to the screen's
+// aspect ratio.
+func (g *Game) viewSize() (vw, vh float64) {
+	return float64(g.tileW) / g.zoom, float64(g.tileH) / g.zoom
+}
+
+// viewOrigin is the viewport origin used for drawing: the camera position
+// plus any active shake.
+func (g *Game) viewOrigin() (float64, float64) {
+	return g.vx + g.shakeX, g.vy + g.shakeY
+}
+
+// worldTransform returns the mapping screen = world*scale + (tx, ty) for a
+// screen of sw x sh pixels.
+func (g *Game) worldTransform(sw, sh int) (scale, tx, ty float64) {
+	scale, dx, dy := g.viewTransform(sw, sh)
+	ox, oy := g.viewOrigin()
+	tx, ty = dx-ox*scale, dy-oy*scale
+	if g.cfg.Camera.PixelSnap {
+		tx, ty = snapTranslate(scale, tx, ty)
+	}
+	return scale, tx, ty
+}
+
+// integerScaleEpsilon is how close a scale has to be to a whole number to
+// count as one.
+const integerScaleEpsilon = 1e-3
+
+// snapTranslate rounds the translation to whole screen pixels when every
+// world pixel covers a whole number of screen pixels, so pixel art
+// doesn't shimmer as the camera moves. Other scales keep sub-pixel
+// positioning.
+func snapTranslate(scale, tx, ty float64) (float64, float64) {
+	if scale < 1-integerScaleEpsilon || math.Abs(scale-math.Round(scale)) > integerScaleEpsilon {
+		return tx, ty
+	}
+	return math.Round(tx), math.Round(ty)
+}
+
+// worldToScreen converts a world position to screen pixels using the size
+// from the last Layout.
+func (g *Game) worldToScreen(x, y float64) (float64, float64) {
+	scale, tx, ty := g.worldTransform(g.screenW, g.screenH)
+	return x*scale + tx, y*scale + ty
+}
+
+// screenToWorld is the inverse of worldToScreen.
+func (g *Game) screenToWorld(x, y float64) (float64, float64) {
+	scale, tx, ty := g.worldTransform(g.screenW, g.screenH)
+	return (x - tx) / scale, (y - ty) / scale
+}
@@ -0,0 +1,95 @@
+package main
+
+import "testing"
+
+func TestSnapTranslate(t *testing.T) {
+	tests := []struct {
+		name           string
+		scale, tx, ty  float64
+		wantTX, wantTY float64
+	}{
+		{"scale 1", 1, 10.4, 20.6, 10, 21},
+		{"scale 2", 2, 10.4, 20.6, 10, 21},
+		{"scale 3", 3, 0.49, 0.51, 0, 1},
+		{"just under an integer", 1.9995, 10.4, 20.6, 10, 21},
+		{"just over an integer", 2.0005, 10.4, 20.6, 10, 21},
+		{"negative offsets", 2, -12.4, -12.6, -12, -13},
+		{"negative offsets at scale 1", 1, -0.4, -1000.7, 0, -1001},
+		{"fractional zoom", 1.5, 10.4, 20.6, 10.4, 20.6},
+		{"fractional zoom, negative offsets", 2.25, -12.4, -12.6, -12.4, -12.6},
+		{"near but outside the epsilon", 2.01, 10.4, 20.6, 10.4, 20.6},
+		// downscaled maps sample several world pixels per screen pixel,
+		// so there is nothing to line up
+		{"below 1", 0.5, 10.4, 20.6, 10.4, 20.6},
+		{"just below 1", 0.9995, 10.4, 20.6, 10, 21},
+		{"zero", 0, 10.4, 20.6, 10.4, 20.6},
+	}
+	for _, tt := range tests {
+		tx, ty := snapTranslate(tt.scale, tt.tx, tt.ty)
+		if tx != tt.wantTX || ty != tt.wantTY {
+			t.Errorf("%s: snapTranslate(%v, %v, %v) = %v, %v, want %v, %v", tt.name, tt.scale, tt.tx, tt.ty, tx, ty, tt.wantTX, tt.wantTY)
+		}
+	}
+}
+
+func TestCapScale(t *testing.T) {
+	tests := []struct {
+		scale, maxUpscale, want float64
+	}{
+		{1.5, 2, 1.5},
+		{2, 2, 2},
+		{3, 2, 2},
+		{0.5, 2, 0.5},
+		{8, 0, 8},
+		{8, -1, 8},
+	}
+	for _, tt := range tests {
+		if got := capScale(tt.scale, tt.maxUpscale); got != tt.want {
+			t.Errorf("capScale(%v, %v) = %v, want %v", tt.scale, tt.maxUpscale, got, tt.want)
+		}
+	}
+}
+
+// A small map on a big screen is drawn at most maxUpscale times its size,
+// letterboxed in the middle.
+func TestViewTransformCapsUpscale(t *testing.T) {
+	g := newTestGame(100, 100)
+	g.cfg.Map.MaxUpscale = 2
+	g.screenW, g.screenH = 1280, 960
+	g.vx, g.vy = 0, 0
+	if scale, _, _ := g.viewTransform(g.screenW, g.screenH); scale != 2 {
+		t.Fatalf("scale = %v, want 2", scale)
+	}
+	// the 640x480 viewport at scale 2 exactly fills the screen here; a
+	// bigger zoom shrinks the viewport, which must then be centered
+	g.zoom = 4
+	scale, dx, dy := g.viewTransform(g.screenW, g.screenH)
+	if scale != 2 || dx != 480 || dy != 360 {
+		t.Errorf("at zoom 4 got scale %v offset %v, %v, want 2 and 480, 360", scale, dx, dy)
+	}
+}
+
+// fitZoom never asks for more magnification than the cap allows, so the
+// overview and the zoom-out limit agree with what is drawn.
+func TestFitZoomHonorsMaxUpscale(t *testing.T) {
+	g := newTestGame(100, 50)
+	if got := g.fitZoom(640, 480); got != 6.4 {
+		t.Errorf("uncapped fitZoom = %v, want 6.4", got)
+	}
+	g.cfg.Map.MaxUpscale = 2
+	z := g.fitZoom(640, 480)
+	if z != 2 {
+		t.Errorf("capped fitZoom = %v, want 2", z)
+	}
+	g.zoom = z
+	if scale, _, _ := g.viewTransform(640, 480); scale != 2 {
+		t.Errorf("scale at the fit zoom = %v, want 2", scale)
+	}
+
+	// big maps are unaffected by the cap
+	g = newTestGame(6400, 4800)
+	g.cfg.Map.MaxUpscale = 2
+	if got := g.fitZoom(640, 480); got != 0.1 {
+		t.Errorf("fitZoom for a big map = %v, want 0.1", got)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import "math"
+
+// wrapCoord maps v into [0, size).
+func wrapCoord(v, size float64) float64 {
+	if size <= 0 {
+		return v
+	}
+	return v - math.Floor(v/size)*size
+}
+
+// wrapToMap wraps a player box at (x, y) around the map edges by its
+// center, so walking off one side comes back in on the other.
+func (g *Game) wrapToMap(x, y float64) (float64, float64) {
+	if g.bg == nil {
+		return x, y
+	}
+	bw, bh := g.bg.size()
+	hw, hh := float64(g.playerW)/2, float64(g.playerH)/2
+	return wrapCoord(x+hw, float64(bw)) - hw, wrapCoord(y+hh, float64(bh)) - hh
+}
+
+// followWrap moves the camera along by a whole map size when the player
+// at (x, y) wrapped to (nx, ny), so following them doesn't sweep across
+// the map.
+func (g *Game) followWrap(x, y, nx, ny float64) {
+	bw, bh := g.bg.size()
+	dx := math.Round((nx-x)/float64(bw)) * float64(bw)
+	dy := math.Round((ny-y)/float64(bh)) * float64(bh)
+	g.vx += dx
+	g.vy += dy
+	g.camTargetX += dx
+	g.camTargetY += dy
+}
+
+// wrapSpans splits [lo, hi) on a ring of the given size into at most
+// two spans inside [0, size).
+func wrapSpans(lo, hi, size float64) [][2]float64 {
+	if hi-lo >= size {
+		return [][2]float64{{0, size}}
+	}
+	shift := math.Floor(lo/size) * size
+	lo, hi = lo-shift, hi-shift
+	if hi <= size {
+		return [][2]float64{{lo, hi}}
+	}
+	return [][2]float64{{lo, size}, {0, hi - size}}
+}
+
+// wrappedRects splits a world rectangle on a toroidal map of w x h into
+// the pieces that lie inside the map, up to four when it crosses a
+// corner.
+func wrappedRects(b bounds, w, h float64) []bounds {
+	var out []bounds
+	for _, ys := range wrapSpans(b.minY, b.maxY, h) {
+		for _, xs := range wrapSpans(b.minX, b.maxX, w) {
+			out = append(out, bounds{xs[0], ys[0], xs[1], ys[1]})
+		}
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrapCoord(t *testing.T) {
+	tests := []struct {
+		v, size, want float64
+	}{
+		{10, 100, 10},
+		{0, 100, 0},
+		{100, 100, 0},
+		{130, 100, 30},
+		{-10, 100, 90},
+		{-100, 100, 0},
+		{-250, 100, 50},
+		{42, 0, 42},
+	}
+	for _, tt := range tests {
+		if got := wrapCoord(tt.v, tt.size); got != tt.want {
+			t.Errorf("wrapCoord(%v, %v) = %v, want %v", tt.v, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestWrapSpans(t *testing.T) {
+	tests := []struct {
+		name   string
+		lo, hi float64
+		want   [][2]float64
+	}{
+		{"inside", 10, 40, [][2]float64{{10, 40}}},
+		{"up to the edge", 60, 100, [][2]float64{{60, 100}}},
+		{"across the far edge", 80, 120, [][2]float64{{80, 100}, {0, 20}}},
+		{"across the near edge", -20, 30, [][2]float64{{80, 100}, {0, 30}}},
+		{"a lap further on", 210, 240, [][2]float64{{10, 40}}},
+		{"wider than the ring", -50, 60, [][2]float64{{0, 100}}},
+	}
+	for _, tt := range tests {
+		if got := wrapSpans(tt.lo, tt.hi, 100); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: wrapSpans(%v, %v, 100) = %v, want %v", tt.name, tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
+
+func TestWrappedRects(t *testing.T) {
+	const w, h = 1000, 500
+	tests := []struct {
+		name string
+		b    bounds
+		want []bounds
+	}{
+		{"inside", bounds{100, 100, 200, 200}, []bounds{{100, 100, 200, 200}}},
+		{"across the right edge", bounds{950, 100, 1050, 200}, []bounds{
+			{950, 100, 1000, 200},
+			{0, 100, 50, 200},
+		}},
+		{"across the top edge", bounds{100, -50, 200, 50}, []bounds{
+			{100, 450, 200, 500},
+			{100, 0, 200, 50},
+		}},
+		{"across the bottom right corner", bounds{950, 450, 1050, 550}, []bounds{
+			{950, 450, 1000, 500},
+			{0, 450, 50, 500},
+			{950, 0, 1000, 50},
+			{0, 0, 50, 50},
+		}},
+	}
+	for _, tt := range tests {
+		got := wrappedRects(tt.b, w, h)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: wrappedRects(%v) = %v, want %v", tt.name, tt.b, got, tt.want)
+			continue
+		}
+		// the pieces tile the original rectangle
+		area := 0.0
+		for _, r := range got {
+			area += (r.maxX - r.minX) * (r.maxY - r.minY)
+		}
+		if want := (tt.b.maxX - tt.b.minX) * (tt.b.maxY - tt.b.minY); area != want {
+			t.Errorf("%s: pieces cover %v, want %v", tt.name, area, want)
+		}
+	}
+}
+
+// Walking off one side of the map puts the player back in on the other,
+// and the camera jumps with them.
+func TestWrapToMapAndFollow(t *testing.T) {
+	g := newTestGame(1000, 500)
+	hw := float64(g.playerW) / 2
+	x, y := 1000-hw+5, 100.0
+	nx, ny := g.wrapToMap(x, y)
+	if nx != 5-hw || ny != y {
+		t.Fatalf("wrapToMap(%v, %v) = %v, %v, want %v, %v", x, y, nx, ny, 5-hw, y)
+	}
+	g.vx, g.vy = 700, 50
+	g.camTargetX, g.camTargetY = 700, 50
+	g.followWrap(x, y, nx, ny)
+	if g.vx != -300 || g.camTargetX != -300 || g.vy != 50 || g.camTargetY != 50 {
+		t.Errorf("followWrap moved the view to %v, %v (target %v, %v), want -300, 50",
+			g.vx, g.vy, g.camTargetX, g.camTargetY)
+	}
+}
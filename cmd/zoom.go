@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fitZoom is the zoom at which the whole map just fits on a screen of
+// sw x sh pixels. A map that would fit only above map.maxUpscale gets the
+// zoom at the cap instead, since viewTransform never draws it larger.
+func (g *Game) fitZoom(sw, sh int) float64 {
+	if g.bg == nil || sw <= 0 || sh <= 0 {
+		return 1
+	}
+	area := g.mapArea(sw, sh)
+	sw, sh = area.Dx(), area.Dy()
+	bw, bh := g.bg.size()
+	// cover scale at zoom 1
+	base := max(float64(sw)/float64(g.tileW), float64(sh)/float64(g.tileH))
+	fit := min(float64(sw)/float64(bw), float64(sh)/float64(bh))
+	return capScale(fit, g.cfg.Map.MaxUpscale) / base
+}
+
+// updateMinZoom recomputes the zoom-out limit for the current screen size.
+func (g *Game) updateMinZoom() {
+	g.minZoom = min(g.fitZoom(g.screenW, g.screenH), 1)
+	g.zoom = min(max(g.zoom, g.minZoom), max(g.cfg.Zoom.Max, g.minZoom))
+}
+
+// zoomAt sets the zoom while keeping the world point under the screen
+// position (sx, sy) in place.
+func (g *Game) zoomAt(z, sx, sy float64) {
+	z = min(max(z, g.minZoom), max(g.cfg.Zoom.Max, g.minZoom))
+	if z == g.zoom {
+		return
+	}
+	wx, wy := g.screenToWorld(sx, sy)
+	g.stopCamera()
+	g.zoom = z
+	scale, dx, dy := g.viewTransform(g.screenW, g.screenH)
+	g.vx = wx - (sx-dx)/scale
+	g.vy = wy - (sy-dy)/scale
+	g.clampView()
+}
+
+// updateZoom applies mouse wheel zooming around the cursor.
+func (g *Game) updateZoom() {
+	_, wy := ebiten.Wheel()
+	if wy == 0 {
+		return
+	}
+	cx, cy := ebiten.CursorPosition()
+	g.zoomAt(wheelZoom(g.zoom, wy, g.cfg.Zoom), float64(cx), float64(cy))
+}
+
+// wheelZoom is the zoom after wy wheel ticks: each tick multiplies or
+// divides by cfg.Factor, or adds cfg.Step when cfg.Linear is set.
+func wheelZoom(z, wy float64, cfg ZoomConfig) float64 {
+	if cfg.Linear || cfg.Factor <= 1 {
+		return z + wy*cfg.Step
+	}
+	return z * math.Pow(cfg.Factor, wy)
+}
+
+// clampViewAxis clamps the viewport origin v on one axis so the visible
+// span stays inside the map, give or take peek of it past either edge,
+// centering it when the map is smaller. The visible span is centered
+// inside the nominal viewport.
+func clampViewAxis(v, nominal, visible, world, peek float64) float64 {
+	// offset of the visible span inside the nominal viewport
+	inset := (nominal - visible) / 2
+	left := v + inset
+	if visible >= world {
+		left = (world - visible) / 2
+	} else {
+		slack := peek * visible
+		left = min(max(left, -slack), world-visible+slack)
+	}
+	return left - inset
+}
+
+// clampView keeps the viewport inside the map.
+func (g *Game) clampView() {
+	g.vx, g.vy = g.clampedView(g.vx, g.vy)
+}
+
+// clampedView is the viewport origin (vx, vy) kept inside the map.
+func (g *Game) clampedView(vx, vy float64) (float64, float64) {
+	// a wrapping map has no edges to stop at
+	if g.bg == nil || g.screenW <= 0 || g.screenH <= 0 || g.cfg.Map.Wrap {
+		return vx, vy
+	}
+	wb := g.worldBounds()
+	vw, vh := g.viewSize()
+	scale, _, _ := g.viewTransform(g.screenW, g.screenH)
+	area := g.mapArea(g.screenW, g.screenH)
+	peek := min(max(g.cfg.Camera.EdgePeek, 0), 0.5)
+	return wb.minX + clampViewAxis(vx-wb.minX, vw, float64(area.Dx())/scale, wb.maxX-wb.minX, peek),
+		wb.minY + clampViewAxis(vy-wb.minY, vh, float64(area.Dy())/scale, wb.maxY-wb.minY, peek)
+}
@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func TestClampViewAxis(t *testing.T) {
+	tests := []struct {
+		name                             string
+		v, nominal, visible, world, peek float64
+		want                             float64
+	}{
+		{"inside", 300, 100, 100, 1000, 0, 300},
+		{"past the low edge", -50, 100, 100, 1000, 0, 0},
+		{"past the high edge", 950, 100, 100, 1000, 0, 900},
+		{"peek past the low edge", -50, 100, 100, 1000, 0.2, -20},
+		{"peek past the high edge", 950, 100, 100, 1000, 0.2, 920},
+		{"within the peek", -10, 100, 100, 1000, 0.2, -10},
+		{"map smaller than the view", 300, 100, 100, 60, 0, -20},
+		{"map smaller ignores peek", -300, 100, 100, 60, 0.5, -20},
+		// only the middle 80 of a nominal 100 is on screen, so the origin
+		// may sit 10 before the edge
+		{"cropped view at the low edge", -50, 100, 80, 1000, 0, -10},
+		{"cropped view at the high edge", 950, 100, 80, 1000, 0, 910},
+	}
+	for _, tt := range tests {
+		got := clampViewAxis(tt.v, tt.nominal, tt.visible, tt.world, tt.peek)
+		if got != tt.want {
+			t.Errorf("%s: clampViewAxis(%v, %v, %v, %v, %v) = %v, want %v",
+				tt.name, tt.v, tt.nominal, tt.visible, tt.world, tt.peek, got, tt.want)
+		}
+	}
+}
+
+// Pushed into each corner the view stops at camera.edgePeek of a screen
+// past both edges at once.
+func TestClampViewCorners(t *testing.T) {
+	tests := []struct {
+		name         string
+		peek         float64
+		vx, vy       float64
+		wantX, wantY float64
+	}{
+		{"top left", 0, -500, -500, 0, 0},
+		{"bottom right", 0, 5000, 5000, 2000 - 640, 1000 - 480},
+		{"top right with peek", 0.25, 5000, -500, 2000 - 640 + 160, -120},
+		{"bottom left with peek", 0.25, -500, 5000, -160, 1000 - 480 + 120},
+		// peek is capped at half a screen
+		{"top left, peek too big", 3, -5000, -5000, -320, -240},
+		{"negative peek acts as none", -1, -500, -500, 0, 0},
+	}
+	for _, tt := range tests {
+		g := newTestGame(2000, 1000)
+		g.cfg.Camera.EdgePeek = tt.peek
+		x, y := g.clampedView(tt.vx, tt.vy)
+		if x != tt.wantX || y != tt.wantY {
+			t.Errorf("%s: clampedView(%v, %v) = %v, %v, want %v, %v", tt.name, tt.vx, tt.vy, x, y, tt.wantX, tt.wantY)
+		}
+	}
+}
+
+func TestClampViewWrapping(t *testing.T) {
+	g := newTestGame(2000, 1000)
+	g.cfg.Map.Wrap = true
+	if x, y := g.clampedView(-500, 5000); x != -500 || y != 5000 {
+		t.Errorf("a wrapping map clamped the view to %v, %v", x, y)
+	}
+}